@@ -0,0 +1,126 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	runner "github.com/fire-ant/kro-actions-runner/internal"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// expiredCleaner is implemented by runners that can reap instances past their expiry
+type expiredCleaner interface {
+	CleanupExpired(ctx context.Context, now time.Time) ([]string, error)
+}
+
+func newCleanupCommand(ctx context.Context, r interface{}, opts *Opts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Delete the scale set's instances that are past their expiry",
+		Long: "Deletes instances of the scale set's RGD whose actions.github.com/expires-at annotation, " +
+			"set from --max-runtime at create time, has passed. Instances without the annotation are kept.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cleanup(ctx, cmd.OutOrStdout(), r, *opts, time.Now())
+		},
+	}
+	cmd.Flags().IntVar(&opts.CleanupConcurrency, "concurrency", runner.DefaultCleanupConcurrency,
+		"Number of expired instances to delete in parallel.")
+	cmd.Flags().StringSliceVar(&opts.CleanupAllowedNamespaces, "allowed-namespaces", nil,
+		"Namespaces cleanup may delete instances in. Without it, the namespace must be given with --runner-namespace.")
+	cmd.Flags().BoolVar(&opts.CleanupForce, "force", false,
+		"Clean up any namespace, including one taken from the kubeconfig, ignoring --allowed-namespaces.")
+
+	return cmd
+}
+
+func cleanup(ctx context.Context, out io.Writer, r interface{}, opts Opts, now time.Time) error {
+	c, ok := r.(expiredCleaner)
+	if !ok {
+		return errors.New("runner does not support cleanup")
+	}
+
+	if opts.CleanupConcurrency < 0 {
+		return errors.Errorf("invalid --concurrency %d, must not be negative", opts.CleanupConcurrency)
+	}
+
+	if err := checkCleanupNamespace(r, opts); err != nil {
+		return err
+	}
+
+	// Discovery and delete flags such as --rgd-extra-selector apply to cleanup as well
+	if configurable, ok := r.(interface{ Configure(opts ...runner.Option) }); ok {
+		runnerOpts, err := opts.runnerOptions()
+		if err != nil {
+			return err
+		}
+		if opts.CleanupConcurrency > 0 {
+			runnerOpts = append(runnerOpts, runner.WithCleanupConcurrency(opts.CleanupConcurrency))
+		}
+		configurable.Configure(runnerOpts...)
+	}
+
+	deleted, err := c.CleanupExpired(ctx, now)
+	for _, name := range deleted {
+		_, _ = fmt.Fprintf(out, "deleted %s\n", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "%d expired instance(s) deleted\n", len(deleted))
+	return nil
+}
+
+// checkCleanupNamespace refuses bulk deletion in a namespace that was not explicitly targeted: it must be
+// listed in --allowed-namespaces or, without a list, given with --runner-namespace, unless --force is set
+func checkCleanupNamespace(r interface{}, opts Opts) error {
+	if opts.CleanupForce {
+		return nil
+	}
+
+	// Guard the namespace the runner deletes in, which the entrypoint resolved separately from these options
+	namespace := opts.RunnerNamespace
+	if n, ok := r.(interface{ Namespace() string }); ok {
+		namespace = n.Namespace()
+		if opts.RunnerNamespace != "" && opts.RunnerNamespace != namespace {
+			return errors.Errorf("refusing to clean up namespace %q, which differs from --runner-namespace %q; "+
+				"pass --runner-namespace on the command line", namespace, opts.RunnerNamespace)
+		}
+	}
+
+	if len(opts.CleanupAllowedNamespaces) > 0 {
+		if slices.Contains(opts.CleanupAllowedNamespaces, namespace) {
+			return nil
+		}
+		return errors.Errorf("refusing to clean up namespace %q, not in --allowed-namespaces %s; pass --force to override",
+			namespace, strings.Join(opts.CleanupAllowedNamespaces, ","))
+	}
+
+	if opts.RunnerNamespace == "" {
+		return errors.Errorf("refusing to clean up namespace %q implied by the kubeconfig; target it with "+
+			"--runner-namespace or --allowed-namespaces, or pass --force", namespace)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	runner "github.com/fire-ant/kro-actions-runner/internal"
+)
+
+// mockCleaner returns fixed cleanup results
+type mockCleaner struct {
+	namespace string
+	deleted   []string
+	err       error
+	calls     int
+}
+
+func (m *mockCleaner) CleanupExpired(_ context.Context, _ time.Time) ([]string, error) {
+	m.calls++
+	return m.deleted, m.err
+}
+
+func (m *mockCleaner) Namespace() string {
+	return m.namespace
+}
+
+// TestCleanup tests the cleanup report and its failure result
+func TestCleanup(t *testing.T) {
+	tests := []struct {
+		name      string
+		cleaner   *mockCleaner
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:     "Expired instances deleted",
+			cleaner:  &mockCleaner{namespace: "default", deleted: []string{"runner-a", "runner-b"}},
+			expected: []string{"deleted runner-a", "deleted runner-b", "2 expired instance(s) deleted"},
+		},
+		{
+			name:      "Partial failure reports deleted instances",
+			cleaner:   &mockCleaner{namespace: "default", deleted: []string{"runner-a"}, err: errors.New("forbidden")},
+			expected:  []string{"deleted runner-a"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := cleanup(context.Background(), &out, tt.cleaner, Opts{RunnerNamespace: "default"}, time.Now())
+			if (err != nil) != tt.expectErr {
+				t.Errorf("cleanup() error = %v, expectErr %v", err, tt.expectErr)
+			}
+
+			for _, line := range tt.expected {
+				if !strings.Contains(out.String(), line) {
+					t.Errorf("output is missing %q:\n%s", line, out.String())
+				}
+			}
+		})
+	}
+}
+
+// TestCleanupUnsupportedRunner tests cleanup with a runner lacking cleanup support
+func TestCleanupUnsupportedRunner(t *testing.T) {
+	if err := cleanup(context.Background(), &bytes.Buffer{}, &mockRunner{}, Opts{}, time.Now()); err == nil {
+		t.Error("cleanup() error = nil, want error for unsupported runner")
+	}
+}
+
+// TestCleanupConcurrencyFlag tests the cleanup command's concurrency default and validation
+func TestCleanupConcurrencyFlag(t *testing.T) {
+	opts := &Opts{}
+	cmd := newCleanupCommand(context.Background(), &mockCleaner{}, opts)
+	if err := cmd.ParseFlags(nil); err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	if opts.CleanupConcurrency != runner.DefaultCleanupConcurrency {
+		t.Errorf("CleanupConcurrency = %d, want default %d", opts.CleanupConcurrency, runner.DefaultCleanupConcurrency)
+	}
+
+	err := cleanup(context.Background(), &bytes.Buffer{}, &mockCleaner{}, Opts{CleanupConcurrency: -1}, time.Now())
+	if err == nil {
+		t.Error("cleanup() error = nil, want error for negative concurrency")
+	}
+}
+
+// TestCleanupNamespaceGuard tests that cleanup only deletes in explicitly targeted namespaces unless forced
+func TestCleanupNamespaceGuard(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      Opts
+		expectErr bool
+	}{
+		{name: "Namespace from kubeconfig", opts: Opts{}, expectErr: true},
+		{name: "Explicit runner namespace", opts: Opts{RunnerNamespace: "arc-runners"}},
+		{name: "Allowed namespace", opts: Opts{CleanupAllowedNamespaces: []string{"ci", "arc-runners"}}},
+		{name: "Unlisted namespace", opts: Opts{CleanupAllowedNamespaces: []string{"ci"}}, expectErr: true},
+		{
+			name:      "Unlisted explicit runner namespace",
+			opts:      Opts{RunnerNamespace: "prod", CleanupAllowedNamespaces: []string{"arc-runners"}},
+			expectErr: true,
+		},
+		{
+			name:      "Allowed runner namespace that the runner does not use",
+			opts:      Opts{RunnerNamespace: "prod", CleanupAllowedNamespaces: []string{"prod"}},
+			expectErr: true,
+		},
+		{name: "Unlisted namespace forced", opts: Opts{CleanupAllowedNamespaces: []string{"ci"}, CleanupForce: true}},
+		{name: "Namespace from kubeconfig forced", opts: Opts{CleanupForce: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleaner := &mockCleaner{namespace: "arc-runners"}
+			err := cleanup(context.Background(), &bytes.Buffer{}, cleaner, tt.opts, time.Now())
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("cleanup() error = %v, expectErr %v", err, tt.expectErr)
+			}
+
+			// A refused cleanup must not delete anything
+			if deleted := cleaner.calls > 0; deleted == tt.expectErr {
+				t.Errorf("CleanupExpired called = %v, want %v", deleted, !tt.expectErr)
+			}
+		})
+	}
+}
+
+// TestCleanupForceIgnoresEnv tests that a FORCE variable in the environment does not skip the namespace guard
+func TestCleanupForceIgnoresEnv(t *testing.T) {
+	t.Setenv("FORCE", "true")
+
+	cleaner := &mockCleaner{namespace: "arc-runners"}
+	cmd := NewRootCommand(context.Background(), cleaner, Opts{})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"cleanup", "--scale-set-name", "linux-builders"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Error("Execute() error = nil, want the namespace guard to refuse")
+	}
+	if cleaner.calls > 0 {
+		t.Error("CleanupExpired was called despite the namespace guard")
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// redactedValue replaces secret values in printed configuration
+const redactedValue = "REDACTED"
+
+// secretFlags hold values that must never be printed
+var secretFlags = map[string]bool{
+	"actions-runner-input-jitconfig": true,
+}
+
+func newConfigCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "config",
+		Short: "Print the effective configuration as YAML",
+		Long: "Prints every option after flags, environment variables and defaults have been merged, " +
+			"keyed by flag name, with secrets redacted. Nothing is created.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return showConfig(cmd.OutOrStdout(), cmd.Flags())
+		},
+	}
+}
+
+// showConfig writes the resolved flag values as YAML
+func showConfig(out io.Writer, flags *pflag.FlagSet) error {
+	config := map[string]string{}
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "help" || flag.Name == "version" {
+			return
+		}
+		config[flag.Name] = redactFlagValue(flag.Name, flag.Value.String())
+	})
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(out, string(data))
+	return err
+}
+
+// redactFlagValue hides secrets and credentials embedded in URLs
+func redactFlagValue(name, value string) string {
+	if value == "" {
+		return value
+	}
+	if secretFlags[name] {
+		return redactedValue
+	}
+
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return value
+}
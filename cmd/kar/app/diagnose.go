@@ -0,0 +1,100 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	runner "github.com/fire-ant/kro-actions-runner/internal"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// diagnoser is implemented by runners that can run read-only preflight checks
+type diagnoser interface {
+	Preflight(ctx context.Context) runner.PreflightReport
+}
+
+func newDiagnoseCommand(ctx context.Context, r interface{}, opts *Opts) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "diagnose",
+		Short: "Run read-only checks explaining why a runner may not start",
+		Long: "Checks kubeconfig resolution, the namespace, KRO API registration, RGD discovery for the " +
+			"scale set, the instance CRD, RBAC for the instance resource and namespace quota. " +
+			"No resources are created or modified. Exits non-zero if any check fails.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return diagnose(ctx, cmd.OutOrStdout(), r, *opts, output)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table or json.")
+
+	return cmd
+}
+
+func diagnose(ctx context.Context, out io.Writer, r interface{}, opts Opts, output string) error {
+	d, ok := r.(diagnoser)
+	if !ok {
+		return errors.New("runner does not support diagnostics")
+	}
+
+	if output != "table" && output != "json" {
+		return errors.Errorf("invalid --output %q, expected table or json", output)
+	}
+
+	// Discovery flags such as --rgd-extra-selector affect which RGDs are checked
+	if configurable, ok := r.(interface{ Configure(opts ...runner.Option) }); ok {
+		runnerOpts, err := opts.runnerOptions()
+		if err != nil {
+			return err
+		}
+		configurable.Configure(runnerOpts...)
+	}
+
+	report := d.Preflight(ctx)
+
+	if output == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(out, string(data)); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "CHECK\tSTATUS\tDETAILS")
+		for _, check := range report.Checks {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name, check.Status, check.Message)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("%d of %d diagnostic checks failed", failed, len(report.Checks))
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	runner "github.com/fire-ant/kro-actions-runner/internal"
+)
+
+// mockDiagnoser returns fixed diagnostic checks
+type mockDiagnoser struct {
+	checks []runner.DiagnosticCheck
+}
+
+func (m *mockDiagnoser) Preflight(_ context.Context) runner.PreflightReport {
+	return runner.NewPreflightReport(m.checks)
+}
+
+// TestDiagnose tests the diagnose report and its failure result
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		name      string
+		checks    []runner.DiagnosticCheck
+		expectErr bool
+	}{
+		{
+			name:   "All checks pass",
+			checks: []runner.DiagnosticCheck{{Name: "namespace", Status: runner.CheckOK, Message: "namespace default exists"}},
+		},
+		{
+			name: "Failed check",
+			checks: []runner.DiagnosticCheck{
+				{Name: "namespace", Status: runner.CheckOK, Message: "namespace default exists"},
+				{Name: "rgd-discovery", Status: runner.CheckFail, Message: "no RGD matches"},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := diagnose(context.Background(), &out, &mockDiagnoser{checks: tt.checks}, Opts{}, "table")
+			if (err != nil) != tt.expectErr {
+				t.Errorf("diagnose() error = %v, expectErr %v", err, tt.expectErr)
+			}
+
+			for _, check := range tt.checks {
+				if !strings.Contains(out.String(), check.Name) || !strings.Contains(out.String(), check.Message) {
+					t.Errorf("report is missing check %s:\n%s", check.Name, out.String())
+				}
+			}
+		})
+	}
+}
+
+// TestDiagnoseJSON tests the JSON preflight report for mixed passing and failing checks
+func TestDiagnoseJSON(t *testing.T) {
+	checks := []runner.DiagnosticCheck{
+		{Name: "namespace", Status: runner.CheckOK, Message: "namespace default exists"},
+		{Name: "quota", Status: runner.CheckWarn, Message: "resource quotas cannot be read"},
+		{Name: "instance-api", Status: runner.CheckFail, Message: "kro.run/v1alpha1 does not serve podrunners"},
+	}
+
+	var out bytes.Buffer
+	err := diagnose(context.Background(), &out, &mockDiagnoser{checks: checks}, Opts{}, "json")
+	if err == nil {
+		t.Error("diagnose() error = nil, want error for the failed check")
+	}
+
+	var report runner.PreflightReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if report.Status != runner.CheckFail || len(report.Checks) != 3 || report.Checks[2].Name != "instance-api" {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+// TestDiagnoseInvalidOutput tests rejecting an unknown output format
+func TestDiagnoseInvalidOutput(t *testing.T) {
+	if err := diagnose(context.Background(), &bytes.Buffer{}, &mockDiagnoser{}, Opts{}, "yaml"); err == nil {
+		t.Error("diagnose() error = nil, want error for unsupported output")
+	}
+}
+
+// TestDiagnoseUnsupportedRunner tests diagnose with a runner lacking diagnostics
+func TestDiagnoseUnsupportedRunner(t *testing.T) {
+	if err := diagnose(context.Background(), &bytes.Buffer{}, &mockRunner{}, Opts{}, "table"); err == nil {
+		t.Error("diagnose() error = nil, want error for unsupported runner")
+	}
+}
+
+// TestNewRootCommandDiagnoseSubcommand tests the diagnose subcommand is registered
+func TestNewRootCommandDiagnoseSubcommand(t *testing.T) {
+	cmd := NewRootCommand(context.Background(), &mockRunner{}, Opts{})
+
+	sub, _, err := cmd.Find([]string{"diagnose"})
+	if err != nil || sub.Name() != "diagnose" {
+		t.Errorf("diagnose subcommand not found: %v", err)
+	}
+}
@@ -20,7 +20,9 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
+	runner "github.com/fire-ant/kro-actions-runner/internal"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -34,27 +36,229 @@ func installFlags(flags *pflag.FlagSet, cmdOptions *Opts) {
 	// KRO RGD discovery
 	flags.StringVarP(&cmdOptions.ScaleSetName, "scale-set-name", "s", "",
 		"The scale set name for RGD discovery. Uses label matching.")
+	flags.StringVar(&cmdOptions.ScaleSetNameFile, "scale-set-name-file", "",
+		"File to read the scale set name from when it is not set by flag or environment.")
+	flags.StringVar(&cmdOptions.RGDExtraSelector, "rgd-extra-selector", "",
+		"Additional comma-separated key=value label requirements for RGD discovery (e.g. env=prod).")
+	flags.StringVar(&cmdOptions.RGDResourceName, "rgd-resource-name", "",
+		"Plural resource name of the RGD's CRD (e.g. podrunners), used verbatim instead of deriving it from the kind. Must match the CRD.")
+	flags.StringVar(&cmdOptions.RGDSelection, "rgd-selection", runner.RGDSelectionStrict,
+		"How to pick an RGD when several match: strict (fail), newest or oldest by creation time.")
+	flags.StringVar(&cmdOptions.KROAPIVersion, "kro-api-version", "",
+		"KRO API version (e.g. v1alpha1). Defaults to the server's preferred version, or "+runner.DefaultKROAPIVersion+" if undiscoverable.")
 
 	// Runner configuration
 	flags.StringVarP(&cmdOptions.RunnerName, "runner-name", "r", "runner",
 		"The name of the runner.")
 	flags.StringVarP(&cmdOptions.JitConfig, "actions-runner-input-jitconfig", "c", "",
 		"The opaque JIT runner config.")
+	flags.BoolVar(&cmdOptions.RunnerNameFromJITConfig, "runner-name-from-jitconfig", false,
+		"Use the runner name embedded in the JIT config when --runner-name is not set, and warn when they differ.")
+
+	// Cluster configuration
+	flags.StringVar(&cmdOptions.KubeContext, "context", "",
+		"Kubeconfig context to use instead of the current context.")
+	flags.StringSliceVar(&cmdOptions.KubeconfigFiles, "kubeconfig-file", nil,
+		"Kubeconfig file merged ahead of KUBECONFIG and ~/.kube/config; earlier files win on conflicts. Repeatable.")
+	flags.BoolVar(&cmdOptions.CreateNamespace, "create-namespace", false,
+		"Create the target namespace if it does not exist.")
+	flags.StringVar(&cmdOptions.ScaleSetNamespace, "scale-set-namespace", "",
+		"Namespace to discover RGDs in, for forks with namespaced RGDs. Defaults to listing cluster-wide.")
+	flags.StringVar(&cmdOptions.RunnerNamespace, "runner-namespace", "",
+		"Namespace of the runner pod, instance and JIT secret. Defaults to the kubeconfig namespace.")
+	flags.StringVar(&cmdOptions.ProxyURL, "proxy-url", "",
+		"Proxy URL for reaching the API server, overriding HTTPS_PROXY/NO_PROXY.")
+
+	// Instance spec and metadata
+	flags.StringVar(&cmdOptions.OrchestratorPodName, "orchestrator-pod-name", "",
+		"Pod to own the instance. Overrides KAR_ORCHESTRATOR_POD_NAME and defaults to the runner name.")
+	flags.StringVar(&cmdOptions.SpecOverrides, "spec-overrides", "",
+		"JSON object merged into the instance spec. Keys are validated against the RGD schema.")
+	flags.StringVar(&cmdOptions.SpecTemplate, "spec-template", "",
+		"Go template file rendering the base instance spec as YAML or JSON, with .RunnerName, .ScaleSetName, .JITSecret, .Namespace and .Env, instead of only runnerName.")
+	flags.StringVar(&cmdOptions.JITReferenceStyle, "jit-reference-style", runner.JITReferenceNone,
+		"How the spec references the JIT secret: none (RGD derives it from runnerName), name or secretKeyRef.")
+	flags.StringVar(&cmdOptions.JITSecretName, "jit-secret-name", "",
+		"Name of ARC's JIT secret when it differs from the runner name. Referenced in the spec; ARC owns and deletes it.")
+	flags.StringVar(&cmdOptions.RunnerLabels, "runner-labels", "",
+		"Comma-separated GitHub runner labels (e.g. linux,x64,self-hosted,gpu) passed to the RGD under spec.runnerLabels.")
+	flags.StringVar(&cmdOptions.RunnerGroup, "runner-group", "",
+		"GitHub runner group passed to the RGD under spec.runnerGroup. Read from ACTIONS_RUNNER_GROUP; omitted when unset.")
+	flags.StringSliceVar(&cmdOptions.ImagePullSecrets, "image-pull-secret", nil,
+		"Image pull secret name passed to the RGD under spec.imagePullSecrets. Repeatable.")
+	flags.StringSliceVar(&cmdOptions.NodeSelectors, "node-selector", nil,
+		"Node selector key=value passed to the RGD under spec.scheduling.nodeSelector. Repeatable.")
+	flags.StringSliceVar(&cmdOptions.Tolerations, "toleration", nil,
+		"Toleration key[=value][:effect] passed to the RGD under spec.scheduling.tolerations; without a value it uses the Exists operator. Repeatable.")
+	flags.StringVar(&cmdOptions.RegisteredStatusPath, "registered-status-path", "",
+		"Dot-separated instance field (e.g. status.registered) the RGD sets once the runner registers with GitHub; logged when it becomes true.")
+	flags.BoolVar(&cmdOptions.StampCreator, "stamp-creator", false,
+		"Annotate the instance with the orchestrator pod name, node and service account for auditing.")
+	flags.BoolVar(&cmdOptions.StampCleanupTimeout, "stamp-cleanup-timeout", false,
+		"Annotate the instance with the cleanup timeout (KAR_CLEANUP_TIMEOUT) in seconds, so external reapers know how long kar waits for teardown.")
+	flags.BoolVar(&cmdOptions.Validate, "validate", false,
+		"Validate the instance with a server-side dry-run create before creating it.")
+
+	// Lifecycle
+	flags.BoolVar(&cmdOptions.Resume, "resume", false,
+		"Reattach to an instance previously created for this runner instead of creating a new one.")
+	flags.BoolVar(&cmdOptions.DeterministicName, "deterministic-name", false,
+		"Name the instance after a hash of the scale set, runner name and GITHUB_RUN_ID so retries of one runner map to the same instance (adopt it with --on-conflict adopt). The runner name is kept in an annotation.")
+	flags.BoolVar(&cmdOptions.GetOrCreate, "get-or-create", false,
+		"Get the instance by name first and reuse it when it belongs to this scale set; create it otherwise.")
+	flags.BoolVar(&cmdOptions.UseLease, "use-lease", false,
+		"Hold a coordination.k8s.io Lease named after the runner while the orchestrator is active; a stale Lease means it crashed.")
+	flags.StringVar(&cmdOptions.OnConflict, "on-conflict", runner.ConflictFail,
+		"When the instance already exists: fail, adopt (watch it if the spec matches) or replace (delete and recreate).")
+	flags.DurationVar(&cmdOptions.CreateTimeout, "create-timeout", runner.DefaultCreateTimeout,
+		"Timeout for discovering the RGD and creating the instance. Zero disables the timeout.")
+	flags.DurationVar(&cmdOptions.StartupRetryBudget, "startup-retry-budget", 0,
+		"Total backoff time transient API errors may be retried for across discovery and create before giving up. Zero leaves retries unbounded.")
+	flags.BoolVar(&cmdOptions.CheckQuota, "check-quota", false,
+		"Fail before creating the instance if the namespace's ResourceQuotas cannot fit the RGD's "+
+			"actions.github.com/expected-resources annotation (default pods=1).")
+	flags.BoolVar(&cmdOptions.CheckKRO, "check-kro", false,
+		"Fail before creating the instance if the KRO controller Deployment has no available replicas, "+
+			"or, when it cannot be read, if KRO has never reconciled the RGD.")
+	flags.StringVar(&cmdOptions.KROController, "kro-controller",
+		runner.DefaultKROControllerNamespace+"/"+runner.DefaultKROControllerName,
+		"Namespace/name of the KRO controller Deployment checked by --check-kro.")
+	flags.BoolVar(&cmdOptions.WaitActiveOnCreate, "wait-active-on-create", false,
+		"Block create until the instance is ACTIVE or fails validation, within --create-timeout.")
+	flags.DurationVar(&cmdOptions.FailedGrace, "failed-grace", 0,
+		"How long a FAILED instance may take to recover to ACTIVE before the runner is considered failed. Not supported with --use-informer.")
+	flags.DurationVar(&cmdOptions.MaxRuntime, "max-runtime", 0,
+		"Maximum time to wait for the runner. Also stamps the instance with an expiry for kar cleanup. Zero disables both.")
+	flags.DurationVar(&cmdOptions.InstanceTTL, "instance-ttl", 0,
+		"Stamp the instance with actions.github.com/ttl-seconds for an external TTL controller to delete it. kar does not enforce it. Zero disables.")
+	flags.StringSliceVar(&cmdOptions.FatalWaitingReasons, "fatal-waiting-reasons", runner.DefaultFatalWaitingReasons,
+		"Runner pod container waiting reasons that fail the runner immediately. Empty disables detection.")
+	flags.StringVar(&cmdOptions.OnMissingPodStatus, "on-missing-pod-status", runner.MissingPodStatusAssumeSuccess,
+		"Outcome when the instance is ready but status.resources.runnerPod.status.phase is absent: assume-success, assume-failure or error.")
+	flags.BoolVar(&cmdOptions.WatchPodFallback, "watch-pod-fallback", false,
+		"When the status lacks the runner pod phase, watch the pod named by status.runnerPodName and use its phase for the outcome.")
+	flags.IntVar(&cmdOptions.FailureEvents, "failure-events", runner.DefaultFailureEvents,
+		"Number of the instance's most recent Kubernetes events to log when the runner fails or times out. Zero disables.")
+	flags.BoolVar(&cmdOptions.FailureEventsIncludeRelated, "failure-events-include-related", false,
+		"Also log events of objects named after the runner with a suffix, such as a <runner>-job runner pod.")
+	flags.StringVar(&cmdOptions.CompletionExpr, "completion-expr", "",
+		"CEL expression over the instance status (e.g. status.state == 'ACTIVE' && status.phase == 'Done') that marks the runner done, replacing the runner pod phase check.")
+	flags.StringVar(&cmdOptions.FailureExpr, "failure-expr", "",
+		"CEL expression over the instance status that marks the runner failed. Checked before --completion-expr.")
+	flags.IntVar(&cmdOptions.MaxWatchReconnects, "max-watch-reconnects", runner.DefaultMaxWatchReconnects,
+		"Consecutive attempts to re-establish a closed instance watch before giving up.")
+	flags.DurationVar(&cmdOptions.WatchReconnectInterval, "watch-reconnect-interval", runner.DefaultWatchReconnectInterval,
+		"Delay before each attempt to re-establish a closed instance watch.")
+	flags.DurationVar(&cmdOptions.WatchResyncInterval, "watch-resync-interval", runner.DefaultWatchResyncInterval,
+		"How often to re-read the instance alongside the watch, in case a terminal transition was missed. Zero disables it.")
+	flags.BoolVar(&cmdOptions.UseInformer, "use-informer", false,
+		"Wait through a shared informer over the scale set's instances instead of a single watch.")
+	flags.BoolVar(&cmdOptions.WatchByLabel, "watch-by-label", false,
+		"Watch the instance by its kro.run/runner-name label instead of a metadata.name field selector, for API servers that mishandle field selectors on custom resources.")
+	flags.BoolVar(&cmdOptions.CleanupOnSuccess, "cleanup-on-success", true,
+		"Delete the instance after a successful run. Disable to rely on owner reference garbage collection.")
+	flags.BoolVar(&cmdOptions.CleanupOnFailure, "cleanup-on-failure", true,
+		"Delete the instance after a failed wait. Disable to keep the instance for debugging.")
+	flags.BoolVar(&cmdOptions.DetachOnSignal, "detach-on-signal", false,
+		"On SIGTERM or interrupt, leave the instance running if its job is still healthy, for owner reference or external garbage collection.")
+	flags.Int64Var(&cmdOptions.DeleteGracePeriod, "delete-grace-period", -1,
+		"Grace period in seconds for deleting the instance. Negative uses the server default.")
+
+	// Reporting
+	flags.BoolVar(&cmdOptions.PropagateExitCode, "propagate-exit-code", false,
+		"Exit with the runner container's exit code when the instance status reports a non-zero one.")
+	flags.StringVar(&cmdOptions.ResultFile, "result-file", "",
+		"Write the runner outcome to this path as JUnit XML (.xml) or JSON (any other extension).")
+	flags.StringVar(&cmdOptions.StatusJSON, "status-json", "",
+		"Rewrite this file atomically with the live runner state, conditions and elapsed time as JSON on each instance update.")
+	flags.StringVar(&cmdOptions.ResultWebhookURL, "result-webhook-url", "",
+		"POST the runner outcome as JSON to this URL on completion. Delivery failures do not change the exit code.")
+	flags.DurationVar(&cmdOptions.ResultWebhookTimeout, "result-webhook-timeout", 10*time.Second,
+		"Timeout for delivering the runner outcome to the result webhook.")
+	flags.StringVar(&cmdOptions.ResultWebhookTokenFile, "result-webhook-token-file", "",
+		"File containing a bearer token for the result webhook.")
+
+	// Safety controls
+	flags.StringSliceVar(&cmdOptions.AllowedKinds, "allowed-kinds", nil,
+		"Comma-separated list of RGD kinds that may be instantiated. Empty allows all kinds.")
+
+	// Hooks
+	flags.StringVar(&cmdOptions.PreCreateHook, "pre-create-hook", "",
+		"Command run with sh -c before creating the instance; a non-zero exit aborts the run. "+
+			"Receives KAR_RUNNER_NAME and KAR_SCALE_SET_NAME.")
+	flags.StringVar(&cmdOptions.PostRunHook, "post-run-hook", "",
+		"Command run with sh -c once the runner reaches a terminal state, before cleanup. "+
+			"Receives KAR_RUNNER_NAME, KAR_SCALE_SET_NAME and KAR_OUTCOME; a non-zero exit is only logged.")
+	flags.DurationVar(&cmdOptions.HookTimeout, "hook-timeout", DefaultHookTimeout,
+		"Timeout for hook commands. Zero disables the timeout.")
+
+	// Logging
+	flags.BoolVar(&cmdOptions.Quiet, "quiet", false,
+		"Suppress routine progress logs, keeping warnings, errors and the final outcome.")
+	flags.StringVar(&cmdOptions.LogFile, "log-file", "",
+		"Also write logs as NDJSON to this file, e.g. for a sidecar to upload after the job.")
+	flags.StringVar(&cmdOptions.LogTimestampFormat, "log-timestamp-format", runner.LogTimestampDefault,
+		"Timestamp on console log lines: default, rfc3339, epoch or none (when the log collector adds its own).")
+	flags.BoolVar(&cmdOptions.NoBanner, "no-banner", false,
+		"Suppress the startup banner with the build info, which --version still prints.")
+	flags.IntVar(&cmdOptions.LogSampleRate, "log-sample-rate", 1,
+		"Log one in every N identical per-event status lines for chatty RGDs. State changes and terminal events are always logged.")
+
+	// Debugging
+	flags.BoolVar(&cmdOptions.PrintSpec, "print-spec", false,
+		"Log the constructed instance spec (credentials redacted, Secret names kept) before creating it.")
+	flags.BoolVar(&cmdOptions.TerminateOnFirstEvent, "terminate-on-first-event", false,
+		"DEBUG ONLY: stop waiting after the first instance status event instead of a terminal state. Not for production runners.")
+	_ = flags.MarkHidden("terminate-on-first-event")
+	flags.BoolVar(&cmdOptions.Simulate, "simulate", false,
+		"DEMO ONLY: run against an in-memory fake cluster whose instances go ACTIVE and succeed immediately. Never starts a runner.")
+}
+
+// flagEnv names the variable a flag is read from when it is not the flag's upper-cased name: ARC's
+// variables, and the KAR_ variables the entrypoint reads before the root command runs, so both agree
+var flagEnv = map[string]string{
+	"scale-set-name":       "ACTIONS_RUNNER_SCALE_SET_NAME",
+	"scale-set-name-file":  "ACTIONS_RUNNER_SCALE_SET_NAME_FILE",
+	"runner-group":         "ACTIONS_RUNNER_GROUP",
+	"create-namespace":     "KAR_CREATE_NAMESPACE",
+	"detach-on-signal":     "KAR_DETACH_ON_SIGNAL",
+	"quiet":                "KAR_QUIET",
+	"no-banner":            "KAR_NO_BANNER",
+	"log-file":             "KAR_LOG_FILE",
+	"log-timestamp-format": "KAR_LOG_TIMESTAMP_FORMAT",
+	"simulate":             "KAR_SIMULATE",
 }
 
 func initializeConfig(cmd *cobra.Command) error {
 	v := viper.New()
-	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
-	v.AutomaticEnv()
+	// Each flag is read from one variable, its upper-cased name (e.g. RUNNER_NAME) unless flagEnv names another
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		env, ok := flagEnv[flag.Name]
+		if !ok {
+			env = strings.ToUpper(strings.ReplaceAll(flag.Name, "-", "_"))
+		}
+		_ = v.BindEnv(flag.Name, env)
+	})
 
 	bindFlags(cmd, v)
 
 	return nil
 }
 
+// envIgnoredFlags are never read from the environment; unprefixed variables such as VERSION or FORCE are
+// common and would otherwise fail to parse or, for cleanup's --force, silently disable a safety check
+var envIgnoredFlags = map[string]bool{
+	"help":    true,
+	"version": true,
+	"force":   true,
+}
+
 func bindFlags(cmd *cobra.Command, viperInstance *viper.Viper) {
 	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
 		configName := flag.Name
+		if envIgnoredFlags[configName] {
+			return
+		}
 
 		// Apply the viper config value to the flag when the flag is not set and viper has a value
 		if !flag.Changed && viperInstance.IsSet(configName) {
@@ -33,7 +33,7 @@ func TestInstallFlags(t *testing.T) {
 	installFlags(flags, opts)
 
 	// Check that flags were registered
-	expectedFlags := []string{"scale-set-name", "runner-name", "actions-runner-input-jitconfig"}
+	expectedFlags := []string{"scale-set-name", "scale-set-name-file", "rgd-extra-selector", "rgd-resource-name", "rgd-selection", "kro-api-version", "runner-name", "actions-runner-input-jitconfig", "runner-name-from-jitconfig", "context", "kubeconfig-file", "create-namespace", "scale-set-namespace", "runner-namespace", "proxy-url", "orchestrator-pod-name", "spec-overrides", "spec-template", "jit-reference-style", "jit-secret-name", "runner-labels", "runner-group", "image-pull-secret", "node-selector", "toleration", "registered-status-path", "stamp-creator", "stamp-cleanup-timeout", "validate", "resume", "deterministic-name", "get-or-create", "on-conflict", "use-lease", "create-timeout", "startup-retry-budget", "wait-active-on-create", "check-kro", "kro-controller", "check-quota", "failed-grace", "max-runtime", "instance-ttl", "fatal-waiting-reasons", "on-missing-pod-status", "watch-pod-fallback", "failure-events", "failure-events-include-related", "completion-expr", "failure-expr", "max-watch-reconnects", "watch-reconnect-interval", "watch-resync-interval", "use-informer", "watch-by-label", "cleanup-on-success", "cleanup-on-failure", "detach-on-signal", "delete-grace-period", "propagate-exit-code", "result-file", "status-json", "result-webhook-url", "result-webhook-timeout", "result-webhook-token-file", "pre-create-hook", "post-run-hook", "hook-timeout", "allowed-kinds", "quiet", "log-file", "log-timestamp-format", "no-banner", "log-sample-rate", "print-spec", "simulate", "terminate-on-first-event"}
 	for _, flagName := range expectedFlags {
 		flag := flags.Lookup(flagName)
 		if flag == nil {
@@ -60,6 +60,11 @@ func TestInstallFlags(t *testing.T) {
 	if runnerNameFlag.DefValue != "runner" {
 		t.Errorf("runner-name default value = %q, want %q", runnerNameFlag.DefValue, "runner")
 	}
+
+	// Debug-only flags stay out of the help output
+	if !flags.Lookup("terminate-on-first-event").Hidden {
+		t.Error("terminate-on-first-event should be hidden")
+	}
 }
 
 // TestInitializeConfig tests the initializeConfig function
@@ -77,6 +82,46 @@ func TestInitializeConfig(t *testing.T) {
 	}
 }
 
+// TestInitializeConfigRunnerGroup tests that the runner group is read from ACTIONS_RUNNER_GROUP
+func TestInitializeConfigRunnerGroup(t *testing.T) {
+	t.Setenv("ACTIONS_RUNNER_GROUP", "gpu-runners")
+
+	cmd := &cobra.Command{Use: "test"}
+	opts := &Opts{}
+	installFlags(cmd.Flags(), opts)
+
+	if err := initializeConfig(cmd); err != nil {
+		t.Fatalf("initializeConfig() error = %v", err)
+	}
+	if opts.RunnerGroup != "gpu-runners" {
+		t.Errorf("RunnerGroup = %q, want gpu-runners", opts.RunnerGroup)
+	}
+}
+
+// TestInitializeConfigEntrypointEnv tests that flags shared with the entrypoint read only the variables it reads
+func TestInitializeConfigEntrypointEnv(t *testing.T) {
+	t.Setenv("KAR_NO_BANNER", "true")
+	t.Setenv("KAR_LOG_FILE", "/tmp/kar.ndjson")
+	t.Setenv("ACTIONS_RUNNER_SCALE_SET_NAME", "linux-builders")
+	t.Setenv("QUIET", "true")
+	t.Setenv("SIMULATE", "true")
+
+	cmd := &cobra.Command{Use: "test"}
+	opts := &Opts{}
+	installFlags(cmd.Flags(), opts)
+
+	if err := initializeConfig(cmd); err != nil {
+		t.Fatalf("initializeConfig() error = %v", err)
+	}
+	if !opts.NoBanner || opts.LogFile != "/tmp/kar.ndjson" || opts.ScaleSetName != "linux-builders" {
+		t.Errorf("opts = {NoBanner: %v, LogFile: %q, ScaleSetName: %q}, want the entrypoint's variables applied",
+			opts.NoBanner, opts.LogFile, opts.ScaleSetName)
+	}
+	if opts.Quiet || opts.Simulate {
+		t.Errorf("opts = {Quiet: %v, Simulate: %v}, want unprefixed QUIET and SIMULATE ignored", opts.Quiet, opts.Simulate)
+	}
+}
+
 // TestBindFlags tests the bindFlags function
 func TestBindFlags(t *testing.T) {
 	tests := []struct {
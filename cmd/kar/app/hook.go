@@ -0,0 +1,85 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+
+	runner "github.com/fire-ant/kro-actions-runner/internal"
+	"github.com/pkg/errors"
+)
+
+// DefaultHookTimeout bounds hook commands unless --hook-timeout is set
+const DefaultHookTimeout = time.Minute
+
+// hookWaitDelay is how long a timed-out hook's output may linger before it is abandoned
+const hookWaitDelay = 5 * time.Second
+
+// runHook runs command with sh -c, passing env on top of the process environment and
+// logging its output. A timeout of zero or less disables the time box.
+func runHook(ctx context.Context, name, command string, timeout time.Duration, env map[string]string) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = os.Environ()
+	for key, val := range env {
+		cmd.Env = append(cmd.Env, key+"="+val)
+	}
+	cmd.Stdout = log.Writer()
+	cmd.Stderr = log.Writer()
+	cmd.WaitDelay = hookWaitDelay
+
+	log.Printf("Running %s hook", name)
+	start := time.Now()
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("%s hook timed out after %s", name, timeout)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s hook failed", name)
+	}
+
+	log.Printf("%s hook finished in %.1fs", name, time.Since(start).Seconds())
+	return nil
+}
+
+// runPostRunHook runs the post-run hook with the runner outcome; failures are only logged.
+// It is not cancelled with ctx, so it also runs when the run was interrupted.
+func runPostRunHook(ctx context.Context, opts Opts, runErr error) {
+	env := hookEnv(opts)
+	env["KAR_OUTCOME"] = runner.OutcomeFor(runErr)
+
+	if err := runHook(context.WithoutCancel(ctx), "post-run", opts.PostRunHook, opts.HookTimeout, env); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// hookEnv describes the runner to hook commands
+func hookEnv(opts Opts) map[string]string {
+	return map[string]string{
+		"KAR_RUNNER_NAME":    opts.RunnerName,
+		"KAR_SCALE_SET_NAME": opts.ScaleSetName,
+	}
+}
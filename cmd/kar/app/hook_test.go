@@ -0,0 +1,167 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	runner "github.com/fire-ant/kro-actions-runner/internal"
+)
+
+// TestRunPreCreateHook tests that the pre-create hook runs with the runner env and gates the create
+func TestRunPreCreateHook(t *testing.T) {
+	tests := []struct {
+		name       string
+		hook       string
+		timeout    time.Duration
+		wantCreate bool
+		wantErr    string
+	}{
+		{
+			name:       "Hook receives the runner env",
+			hook:       `test "$KAR_RUNNER_NAME" = test-runner && test "$KAR_SCALE_SET_NAME" = test-scale-set`,
+			timeout:    DefaultHookTimeout,
+			wantCreate: true,
+		},
+		{
+			name:    "Non-zero exit aborts the run",
+			hook:    "exit 3",
+			timeout: DefaultHookTimeout,
+			wantErr: "pre-create hook failed",
+		},
+		{
+			name:    "Slow hook times out",
+			hook:    "exec sleep 5",
+			timeout: 50 * time.Millisecond,
+			wantErr: "pre-create hook timed out",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &mockRunner{}
+			opts := Opts{
+				RunnerName:    "test-runner",
+				ScaleSetName:  "test-scale-set",
+				JitConfig:     "test-jit-config",
+				PreCreateHook: tt.hook,
+				HookTimeout:   tt.timeout,
+			}
+
+			err := run(context.Background(), io.Discard, r, opts)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("run() error = %v, want %q", err, tt.wantErr)
+			}
+			if r.called.create != tt.wantCreate {
+				t.Errorf("CreateResources called = %v, want %v", r.called.create, tt.wantCreate)
+			}
+		})
+	}
+}
+
+// TestRunHookWritesFile tests a trivial hook with side effects
+func TestRunHookWritesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hook.out")
+
+	err := runHook(context.Background(), "test", `echo "$KAR_RUNNER_NAME" > "$HOOK_OUT"`, time.Second,
+		map[string]string{"KAR_RUNNER_NAME": "test-runner", "HOOK_OUT": path})
+	if err != nil {
+		t.Fatalf("runHook() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("hook output missing: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "test-runner" {
+		t.Errorf("hook wrote %q, want test-runner", data)
+	}
+}
+
+// TestRunPostRunHook tests that the post-run hook receives the outcome and never changes it
+func TestRunPostRunHook(t *testing.T) {
+	tests := []struct {
+		name        string
+		waitErr     error
+		hook        string
+		wantOutcome string
+		wantErr     bool
+	}{
+		{name: "Success", wantOutcome: runner.OutcomeSucceeded},
+		{name: "Failure", waitErr: runner.ErrRunnerFailed, wantOutcome: runner.OutcomeFailed, wantErr: true},
+		{name: "Timeout", waitErr: context.DeadlineExceeded, wantOutcome: runner.OutcomeTimeout, wantErr: true},
+		{name: "Failing hook keeps the outcome", hook: "; exit 1", wantOutcome: runner.OutcomeSucceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "outcome")
+			t.Setenv("HOOK_OUT", path)
+
+			r := &mockRunner{waitErr: tt.waitErr}
+			opts := Opts{
+				RunnerName:  "test-runner",
+				JitConfig:   "test-jit-config",
+				PostRunHook: `echo "$KAR_RUNNER_NAME $KAR_OUTCOME" > "$HOOK_OUT"` + tt.hook,
+				HookTimeout: DefaultHookTimeout,
+			}
+
+			err := run(context.Background(), io.Discard, r, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("post-run hook did not run: %v", err)
+			}
+			if got, want := strings.TrimSpace(string(data)), "test-runner "+tt.wantOutcome; got != want {
+				t.Errorf("hook saw %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// TestRunPostRunHookAfterCancel tests that the post-run hook still runs once the run context is cancelled
+func TestRunPostRunHookAfterCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outcome")
+	t.Setenv("HOOK_OUT", path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := &mockRunner{waitErr: context.Canceled}
+	opts := Opts{RunnerName: "test-runner", PostRunHook: `echo "$KAR_OUTCOME" > "$HOOK_OUT"`, HookTimeout: DefaultHookTimeout}
+	runPostRunHook(ctx, opts, r.waitErr)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("post-run hook did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != runner.OutcomeCancelled {
+		t.Errorf("hook saw outcome %q, want %q", got, runner.OutcomeCancelled)
+	}
+}
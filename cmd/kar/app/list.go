@@ -0,0 +1,115 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	runner "github.com/fire-ant/kro-actions-runner/internal"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/yaml"
+)
+
+// instanceLister is implemented by runners that can list the scale set's instances
+type instanceLister interface {
+	ListInstances(ctx context.Context) ([]runner.InstanceSummary, error)
+}
+
+func newListCommand(ctx context.Context, r interface{}, opts *Opts) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the runner instances of the scale set across all matching RGD kinds",
+		Long: "Lists instances labelled kro.run/runner-name for every RGD matching the scale set, " +
+			"with their KRO state and the outcome parsed from their status.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return list(ctx, cmd.OutOrStdout(), r, *opts, output, time.Now())
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table, json or yaml.")
+
+	return cmd
+}
+
+func list(ctx context.Context, out io.Writer, r interface{}, opts Opts, output string, now time.Time) error {
+	l, ok := r.(instanceLister)
+	if !ok {
+		return errors.New("runner does not support listing instances")
+	}
+
+	if output != "table" && output != "json" && output != "yaml" {
+		return errors.Errorf("invalid --output %q, expected table, json or yaml", output)
+	}
+
+	// Discovery flags such as --rgd-extra-selector affect which RGDs are listed
+	if configurable, ok := r.(interface{ Configure(opts ...runner.Option) }); ok {
+		runnerOpts, err := opts.runnerOptions()
+		if err != nil {
+			return err
+		}
+		configurable.Configure(runnerOpts...)
+	}
+
+	instances, err := l.ListInstances(ctx)
+	if err != nil {
+		return err
+	}
+	if instances == nil {
+		instances = []runner.InstanceSummary{}
+	}
+
+	switch output {
+	case "json":
+		data, err := json.MarshalIndent(instances, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(instances)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tKIND\tSTATE\tAGE\tOUTCOME")
+	for _, instance := range instances {
+		age := "<unknown>"
+		if !instance.CreatedAt.IsZero() {
+			age = duration.HumanDuration(now.Sub(instance.CreatedAt))
+		}
+		state := instance.State
+		if state == "" {
+			state = "<none>"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", instance.Name, instance.Kind, state, age, instance.Outcome)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	runner "github.com/fire-ant/kro-actions-runner/internal"
+	"sigs.k8s.io/yaml"
+)
+
+// mockLister returns fixed instance summaries
+type mockLister struct {
+	instances []runner.InstanceSummary
+}
+
+func (m *mockLister) ListInstances(_ context.Context) ([]runner.InstanceSummary, error) {
+	return m.instances, nil
+}
+
+// TestList tests the list output formats
+func TestList(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	lister := &mockLister{instances: []runner.InstanceSummary{
+		{Name: "runner-a", Namespace: "default", Kind: "PodRunner", State: "ACTIVE", Outcome: runner.OutcomeRunning, CreatedAt: now.Add(-5 * time.Minute)},
+		{Name: "runner-b", Namespace: "default", Kind: "VMRunner", Outcome: runner.OutcomePending},
+	}}
+
+	tests := []struct {
+		name      string
+		output    string
+		check     func(t *testing.T, out string)
+		expectErr bool
+	}{
+		{
+			name:   "Table",
+			output: "table",
+			check: func(t *testing.T, out string) {
+				for _, line := range []string{"NAME", "runner-a  PodRunner  ACTIVE  5m         running", "runner-b  VMRunner   <none>  <unknown>  pending"} {
+					if !strings.Contains(out, line) {
+						t.Errorf("output is missing %q:\n%s", line, out)
+					}
+				}
+			},
+		},
+		{
+			name:   "JSON",
+			output: "json",
+			check: func(t *testing.T, out string) {
+				var got []runner.InstanceSummary
+				if err := json.Unmarshal([]byte(out), &got); err != nil {
+					t.Fatalf("output is not JSON: %v\n%s", err, out)
+				}
+				if len(got) != 2 || got[0].Name != "runner-a" || got[0].Outcome != runner.OutcomeRunning {
+					t.Errorf("decoded instances = %v", got)
+				}
+			},
+		},
+		{
+			name:   "YAML",
+			output: "yaml",
+			check: func(t *testing.T, out string) {
+				var got []runner.InstanceSummary
+				if err := yaml.Unmarshal([]byte(out), &got); err != nil {
+					t.Fatalf("output is not YAML: %v\n%s", err, out)
+				}
+				if len(got) != 2 || got[1].Kind != "VMRunner" || got[1].Outcome != runner.OutcomePending {
+					t.Errorf("decoded instances = %v", got)
+				}
+			},
+		},
+		{
+			name:      "Unknown format",
+			output:    "xml",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := list(context.Background(), &out, lister, Opts{}, tt.output, now)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("list() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.check != nil {
+				tt.check(t, out.String())
+			}
+		})
+	}
+}
+
+// TestListUnsupportedRunner tests list with a runner lacking list support
+func TestListUnsupportedRunner(t *testing.T) {
+	if err := list(context.Background(), &bytes.Buffer{}, &mockRunner{}, Opts{}, "table", time.Now()); err == nil {
+		t.Error("list() error = nil, want error for unsupported runner")
+	}
+}
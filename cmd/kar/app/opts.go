@@ -16,12 +16,364 @@ limitations under the License.
 
 package app
 
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	runner "github.com/fire-ant/kro-actions-runner/internal"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+)
+
 // Opts stores all the options for configuring the root kar command.
 type Opts struct {
+	// Build information printed by --version, set by the entrypoint
+	Version string
+
 	// Scale set name for RGD discovery
 	ScaleSetName string
 
+	// File to read the scale set name from when not set by flag or env
+	ScaleSetNameFile string
+
+	// Additional comma-separated key=value requirements for RGD discovery
+	RGDExtraSelector string
+
+	// Plural resource name of the RGD's CRD, overriding the name derived from the kind
+	RGDResourceName string
+
+	// How discovery picks an RGD when several match: strict, newest or oldest
+	RGDSelection string
+
+	// KRO API version, discovered from the server when empty
+	KROAPIVersion string
+
 	// Runner configuration
-	RunnerName string
-	JitConfig  string
+	RunnerName              string
+	JitConfig               string
+	RunnerNameFromJITConfig bool
+
+	// runnerNameSet records whether --runner-name was given, by flag or environment
+	runnerNameSet bool
+
+	// Cluster configuration
+	KubeContext     string
+	KubeconfigFiles []string
+	CreateNamespace bool
+	ProxyURL        string
+
+	// Namespaces for RGD discovery and for the runner pod, instance and secret
+	ScaleSetNamespace string
+	RunnerNamespace   string
+
+	// Instance spec and metadata
+	SpecOverrides     string
+	SpecTemplate      string
+	JITReferenceStyle string
+	JITSecretName     string
+	ImagePullSecrets  []string
+	RunnerLabels      string
+	RunnerGroup       string
+	NodeSelectors     []string
+	Tolerations       []string
+
+	RegisteredStatusPath  string
+	TerminateOnFirstEvent bool
+
+	OrchestratorPodName string
+	WaitActiveOnCreate  bool
+	CheckQuota          bool
+	CheckKRO            bool
+	KROController       string
+
+	// CleanupConcurrency is set by the cleanup subcommand's --concurrency flag
+	CleanupConcurrency int
+	StampCreator       bool
+	Validate           bool
+
+	// Annotate the instance with KAR_CLEANUP_TIMEOUT, which the entrypoint passes to the runner
+	StampCleanupTimeout bool
+
+	// Namespaces the cleanup subcommand may delete in, and whether to skip that guard
+	CleanupAllowedNamespaces []string
+	CleanupForce             bool
+
+	// Lifecycle
+	Resume             bool
+	DeterministicName  bool
+	GetOrCreate        bool
+	OnConflict         string
+	CreateTimeout      time.Duration
+	StartupRetryBudget time.Duration
+	FailedGrace        time.Duration
+	MaxRuntime         time.Duration
+	InstanceTTL        time.Duration
+	UseInformer        bool
+	WatchByLabel       bool
+
+	// Watch reconnect budget
+	MaxWatchReconnects     int
+	WatchReconnectInterval time.Duration
+
+	// Interval for re-reading the instance alongside the watch, zero disables it
+	WatchResyncInterval time.Duration
+
+	// Container waiting reasons that fail the runner immediately
+	FatalWaitingReasons []string
+
+	// Outcome of a ready instance without a runner pod phase, unless the pod named in the status is watched
+	OnMissingPodStatus string
+	WatchPodFallback   bool
+
+	// Recent instance events logged on failure, optionally including objects named <runner>-*
+	FailureEvents               int
+	FailureEventsIncludeRelated bool
+
+	// CEL expressions over the instance status replacing the built-in completion and failure checks
+	CompletionExpr string
+	FailureExpr    string
+
+	// Cleanup behaviour
+	CleanupOnSuccess bool
+	CleanupOnFailure bool
+
+	// Leave a healthy instance running when the orchestrator is stopped by a signal; handled by the entrypoint
+	DetachOnSignal bool
+
+	// Hold a Lease named after the runner while the orchestrator is active
+	UseLease bool
+
+	// Exit with the runner container's exit code when the instance status reports a non-zero one
+	PropagateExitCode bool
+
+	// Grace period in seconds for deleting the instance, negative uses the server default
+	DeleteGracePeriod int64
+
+	// Reporting
+	ResultFile string
+	StatusJSON string
+
+	// Result webhook; the URL is not logged since it may embed credentials
+	ResultWebhookURL       string
+	ResultWebhookTimeout   time.Duration
+	ResultWebhookTokenFile string
+
+	// Hooks
+	PreCreateHook string
+	PostRunHook   string
+	HookTimeout   time.Duration
+
+	// Safety controls
+	AllowedKinds []string
+
+	// Logging
+	Quiet              bool
+	LogFile            string
+	LogTimestampFormat string
+	LogSampleRate      int
+	// NoBanner suppresses the startup banner; read by the entrypoint
+	NoBanner bool
+
+	// Debugging
+	PrintSpec bool
+
+	// Simulate runs against in-memory fakes instead of a cluster; read by the entrypoint
+	Simulate bool
+}
+
+// runnerOptions translates the command options into KRO runner options
+func (o Opts) runnerOptions() ([]runner.Option, error) {
+	var specOverrides map[string]interface{}
+	if o.SpecOverrides != "" {
+		if err := json.Unmarshal([]byte(o.SpecOverrides), &specOverrides); err != nil {
+			return nil, errors.Wrap(err, "invalid --spec-overrides, expected a JSON object")
+		}
+	}
+
+	var specTemplate *runner.SpecTemplate
+	if o.SpecTemplate != "" {
+		var err error
+		specTemplate, err = runner.LoadSpecTemplate(o.SpecTemplate)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid --spec-template")
+		}
+	}
+
+	var rgdExtraSelector map[string]string
+	if o.RGDExtraSelector != "" {
+		selector, err := labels.ConvertSelectorToLabelsMap(o.RGDExtraSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid --rgd-extra-selector, expected comma-separated key=value pairs")
+		}
+		rgdExtraSelector = selector
+	}
+
+	switch o.JITReferenceStyle {
+	case "", runner.JITReferenceNone, runner.JITReferenceName, runner.JITReferenceSecretKeyRef:
+	default:
+		return nil, errors.Errorf("invalid --jit-reference-style %q, expected none, name or secretKeyRef", o.JITReferenceStyle)
+	}
+
+	switch o.OnConflict {
+	case "", runner.ConflictFail, runner.ConflictAdopt, runner.ConflictReplace:
+	default:
+		return nil, errors.Errorf("invalid --on-conflict %q, expected fail, adopt or replace", o.OnConflict)
+	}
+
+	switch o.OnMissingPodStatus {
+	case "", runner.MissingPodStatusAssumeSuccess, runner.MissingPodStatusAssumeFailure, runner.MissingPodStatusError:
+	default:
+		return nil, errors.Errorf("invalid --on-missing-pod-status %q, expected assume-success, assume-failure or error", o.OnMissingPodStatus)
+	}
+
+	switch o.RGDSelection {
+	case "", runner.RGDSelectionStrict, runner.RGDSelectionNewest, runner.RGDSelectionOldest:
+	default:
+		return nil, errors.Errorf("invalid --rgd-selection %q, expected strict, newest or oldest", o.RGDSelection)
+	}
+
+	kroNamespace, kroName := runner.DefaultKROControllerNamespace, runner.DefaultKROControllerName
+	if o.KROController != "" {
+		var ok bool
+		kroNamespace, kroName, ok = strings.Cut(o.KROController, "/")
+		if !ok || kroNamespace == "" || kroName == "" {
+			return nil, errors.Errorf("invalid --kro-controller %q, expected namespace/name", o.KROController)
+		}
+	}
+
+	if o.LogSampleRate < 0 {
+		return nil, errors.Errorf("invalid --log-sample-rate %d, expected a positive rate", o.LogSampleRate)
+	}
+
+	runnerLabels, err := runner.ParseRunnerLabels(o.RunnerLabels)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid --runner-labels")
+	}
+
+	scheduling, err := runner.ParseScheduling(o.NodeSelectors, o.Tolerations)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid --node-selector or --toleration")
+	}
+
+	completionExprs, err := runner.NewCompletionExprs(o.CompletionExpr, o.FailureExpr)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleteGracePeriod *int64
+	if o.DeleteGracePeriod >= 0 {
+		deleteGracePeriod = ptr.To(o.DeleteGracePeriod)
+	}
+
+	return []runner.Option{
+		runner.WithPrintSpec(o.PrintSpec),
+		runner.WithAllowedKinds(o.AllowedKinds),
+		runner.WithResume(o.Resume),
+		runner.WithGetOrCreate(o.GetOrCreate),
+		runner.WithDeterministicName(o.DeterministicName),
+		runner.WithOnConflict(o.OnConflict),
+		runner.WithOnMissingPodStatus(o.OnMissingPodStatus),
+		runner.WithWatchPodFallback(o.WatchPodFallback),
+		runner.WithCreateTimeout(o.CreateTimeout),
+		runner.WithStartupRetryBudget(o.StartupRetryBudget),
+		runner.WithFailedGrace(o.FailedGrace),
+		runner.WithCompletionExprs(completionExprs),
+		runner.WithFailureEvents(o.FailureEvents, o.FailureEventsIncludeRelated),
+		runner.WithMaxRuntime(o.MaxRuntime),
+		runner.WithInstanceTTL(o.InstanceTTL),
+		runner.WithFatalWaitingReasons(o.FatalWaitingReasons),
+		runner.WithWatchReconnect(o.MaxWatchReconnects, o.WatchReconnectInterval),
+		runner.WithWatchResyncInterval(o.WatchResyncInterval),
+		runner.WithLogSampleRate(o.LogSampleRate),
+		runner.WithStatusFile(o.StatusJSON),
+		runner.WithInformer(o.UseInformer),
+		runner.WithWatchByLabel(o.WatchByLabel),
+		runner.WithDeleteGracePeriod(deleteGracePeriod),
+		runner.WithSpecOverrides(specOverrides),
+		runner.WithSpecTemplate(specTemplate),
+		runner.WithStampCreator(o.StampCreator),
+		runner.WithStampCleanupTimeout(o.StampCleanupTimeout),
+		runner.WithValidate(o.Validate),
+		runner.WithJITReferenceStyle(o.JITReferenceStyle),
+		runner.WithJITSecretName(o.JITSecretName),
+		runner.WithImagePullSecrets(o.ImagePullSecrets),
+		runner.WithRunnerLabels(runnerLabels),
+		runner.WithRunnerGroup(strings.TrimSpace(o.RunnerGroup)),
+		runner.WithScheduling(scheduling),
+		runner.WithRegisteredStatusPath(o.RegisteredStatusPath),
+		runner.WithTerminateOnFirstEvent(o.TerminateOnFirstEvent),
+		runner.WithOrchestratorPodName(o.OrchestratorPodName),
+		runner.WithWaitActiveOnCreate(o.WaitActiveOnCreate),
+		runner.WithCheckQuota(o.CheckQuota),
+		runner.WithCheckKRO(o.CheckKRO, kroNamespace, kroName),
+		runner.WithRGDExtraSelector(rgdExtraSelector),
+		runner.WithRGDResourceName(o.RGDResourceName),
+		runner.WithRGDSelection(o.RGDSelection),
+		runner.WithScaleSetNamespace(o.ScaleSetNamespace),
+		runner.WithKROAPIVersion(o.KROAPIVersion),
+	}, nil
+}
+
+// validateOpts rejects contradictory or incomplete flag combinations, listing every conflict
+// ResolveScaleSetName falls back to reading the scale set name from a file (e.g. a downward API volume)
+// when neither the flag nor the environment provided one
+func ResolveScaleSetName(name, file string) (string, error) {
+	if name != "" || file == "" {
+		return name, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read scale set name file")
+	}
+
+	name = strings.TrimSpace(string(data))
+	if name == "" {
+		return "", errors.Errorf("scale set name file %s is empty", file)
+	}
+
+	return name, nil
+}
+
+func validateOpts(o Opts) error {
+	var conflicts []string
+
+	if o.UseInformer && o.TerminateOnFirstEvent {
+		conflicts = append(conflicts, "--terminate-on-first-event only applies to the watch, not --use-informer")
+	}
+	if o.UseInformer && o.RegisteredStatusPath != "" {
+		conflicts = append(conflicts, "--registered-status-path only applies to the watch, not --use-informer")
+	}
+	if o.UseInformer && (o.CompletionExpr != "" || o.FailureExpr != "") {
+		conflicts = append(conflicts, "--completion-expr and --failure-expr only apply to the watch, not --use-informer")
+	}
+	if o.UseInformer && o.FailedGrace > 0 {
+		conflicts = append(conflicts, "--failed-grace only applies to the watch, not --use-informer")
+	}
+	if o.UseInformer && o.WatchPodFallback {
+		conflicts = append(conflicts, "--watch-pod-fallback only applies to the watch, not --use-informer")
+	}
+	if o.DeterministicName && o.WatchByLabel {
+		conflicts = append(conflicts, "--watch-by-label selects on the runner name label, which does not match a --deterministic-name instance")
+	}
+	if o.JITSecretName != "" && (o.JITReferenceStyle == "" || o.JITReferenceStyle == runner.JITReferenceNone) {
+		conflicts = append(conflicts, "--jit-secret-name requires --jit-reference-style name or secretKeyRef")
+	}
+	if o.WaitActiveOnCreate && o.CreateTimeout <= 0 {
+		conflicts = append(conflicts, "--wait-active-on-create requires a positive --create-timeout")
+	}
+	if o.RunnerNameFromJITConfig && o.JitConfig == "" {
+		conflicts = append(conflicts, "--runner-name-from-jitconfig requires --actions-runner-input-jitconfig")
+	}
+	if o.ResultWebhookTokenFile != "" && o.ResultWebhookURL == "" {
+		conflicts = append(conflicts, "--result-webhook-token-file requires --result-webhook-url")
+	}
+
+	if len(conflicts) > 0 {
+		return errors.Errorf("invalid flag combination: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
 }
@@ -18,8 +18,15 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 
+	runner "github.com/fire-ant/kro-actions-runner/internal"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -29,46 +36,286 @@ func NewRootCommand(ctx context.Context, r interface{}, opts Opts) *cobra.Comman
 		Use:   "kar",
 		Short: "Tool that creates a GitHub Self-Host runner with KRO or Kubevirt",
 		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
-			return initializeConfig(cmd)
+			if err := initializeConfig(cmd); err != nil {
+				return err
+			}
+			// The entrypoint resolves the file for the runner; hooks and results need the same name
+			scaleSetName, err := ResolveScaleSetName(opts.ScaleSetName, opts.ScaleSetNameFile)
+			if err != nil {
+				return err
+			}
+			opts.ScaleSetName = scaleSetName
+			if err := validateOpts(opts); err != nil {
+				return err
+			}
+			// Only enable here; quiet mode may already be on from the entrypoint's flags
+			if opts.Quiet {
+				runner.SetQuiet(true)
+			}
+			return nil
 		},
-		RunE: func(_ *cobra.Command, _ []string) error {
-			return run(ctx, r, opts)
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts.runnerNameSet = cmd.Flags().Changed("runner-name")
+			return run(ctx, cmd.OutOrStdout(), r, opts)
 		},
 	}
 
-	installFlags(cmd.Flags(), &opts)
+	// Persistent so subcommands accept the same configuration
+	installFlags(cmd.PersistentFlags(), &opts)
+
+	// Cobra prints the version before any pre-run, so no configuration or cluster access is needed
+	cmd.Version = opts.Version
+	if cmd.Version == "" {
+		cmd.Version = "unknown"
+	}
+	cmd.SetVersionTemplate("kro-actions-runner {{.Version}}\n")
+	cmd.PersistentFlags().BoolP("version", "v", false, "Print the build info and exit.")
+
+	cmd.AddCommand(newDiagnoseCommand(ctx, r, &opts))
+	cmd.AddCommand(newCleanupCommand(ctx, r, &opts))
+	cmd.AddCommand(newListCommand(ctx, r, &opts))
+	cmd.AddCommand(newConfigCommand())
+	cmd.AddCommand(newScaffoldRGDCommand(&opts))
+
+	// Cobra only honours --version on commands with a version set
+	for _, sub := range cmd.Commands() {
+		sub.Version = cmd.Version
+	}
 
 	return cmd
 }
 
-func run(ctx context.Context, r interface{}, opts Opts) error {
+func run(ctx context.Context, out io.Writer, r interface{}, opts Opts) error {
 	// KRO mode (only mode supported)
-	kroRunner, ok := r.(interface {
-		CreateResources(ctx context.Context, runnerName string, jitConfig string) error
-		WaitForResourceGraph(ctx context.Context) error
-		DeleteResources(ctx context.Context) error
-	})
+	kroRunner, ok := r.(lifecycleRunner)
 	if !ok {
 		return errors.New("runner does not implement required KRO interface")
 	}
 
+	runnerName, err := resolveRunnerName(opts)
+	if err != nil {
+		return err
+	}
+	opts.RunnerName = runnerName
+
+	// Apply flag-driven options to runners that support them
+	if configurable, ok := r.(interface{ Configure(opts ...runner.Option) }); ok {
+		runnerOpts, err := opts.runnerOptions()
+		if err != nil {
+			return err
+		}
+		configurable.Configure(runnerOpts...)
+	}
+
+	// The Lease lets external controllers see the run in progress; it is advisory, so failing to take it is not fatal
+	if opts.UseLease {
+		if holder, ok := r.(interface {
+			StartLease(ctx context.Context, runnerName string) (func(), error)
+		}); ok {
+			release, err := holder.StartLease(ctx, opts.RunnerName)
+			if err != nil {
+				log.Printf("Warning: %v", err)
+			} else {
+				defer release()
+			}
+		}
+	}
+
+	start := time.Now()
+	err = runLifecycle(ctx, kroRunner, opts)
+
+	result := newResult(r, opts, start, err)
+
+	// The outcome summary is logged even in quiet mode
+	log.Printf("Runner %s finished: outcome=%s duration=%.1fs", result.RunnerName, result.Outcome, result.DurationSeconds)
+
+	reportResult(result, opts)
+
+	// Last, so the sentinel is the final line on stdout whatever the log settings
+	writeSentinel(out, result)
+
+	if opts.PropagateExitCode {
+		return propagateExitCode(result, err)
+	}
+	return err
+}
+
+// resolveRunnerName returns the runner name, taking it from the JIT config when enabled and
+// --runner-name was not set. An explicit name always wins, with a warning if the JIT config disagrees.
+func resolveRunnerName(opts Opts) (string, error) {
+	if !opts.RunnerNameFromJITConfig {
+		return opts.RunnerName, nil
+	}
+
+	jitName, err := runner.RunnerNameFromJITConfig(opts.JitConfig)
+	if err != nil {
+		if opts.runnerNameSet {
+			log.Printf("Warning: cannot read the runner name from the JIT config: %v", err)
+			return opts.RunnerName, nil
+		}
+		return "", errors.Wrap(err, "failed to read the runner name from the JIT config")
+	}
+
+	if !opts.runnerNameSet {
+		runner.Infof("Using runner name from the JIT config: %s", jitName)
+		return jitName, nil
+	}
+
+	if jitName != opts.RunnerName {
+		log.Printf("Warning: --runner-name %q does not match the JIT config runner name %q, using %q",
+			opts.RunnerName, jitName, opts.RunnerName)
+	}
+	return opts.RunnerName, nil
+}
+
+// lifecycleRunner is the lifecycle every KRO runner implements
+type lifecycleRunner interface {
+	CreateResources(ctx context.Context, runnerName string, jitConfig string) error
+	WaitForResourceGraph(ctx context.Context) error
+	DeleteResources(ctx context.Context) error
+}
+
+// runLifecycle creates the runner resources, waits for completion and deletes them
+func runLifecycle(ctx context.Context, kroRunner lifecycleRunner, opts Opts) error {
+	if opts.PreCreateHook != "" {
+		if err := runHook(ctx, "pre-create", opts.PreCreateHook, opts.HookTimeout, hookEnv(opts)); err != nil {
+			return err
+		}
+	}
+
 	if err := kroRunner.CreateResources(ctx, opts.RunnerName, opts.JitConfig); err != nil {
 		return errors.Wrap(err, "fail to create resources")
 	}
 
-	log.Println("ResourceGraph runner resources created successfully")
+	runner.Infof("ResourceGraph runner resources created successfully")
 
-	if err := kroRunner.WaitForResourceGraph(ctx); err != nil {
-		return errors.Wrap(err, "fail to wait for resources")
+	waitErr := kroRunner.WaitForResourceGraph(ctx)
+
+	// The hook sees every terminal outcome, including failures and timeouts
+	if opts.PostRunHook != "" {
+		runPostRunHook(ctx, opts, waitErr)
 	}
 
-	log.Println("ResourceGraph runner completed successfully")
+	if waitErr != nil {
+		if !opts.CleanupOnFailure {
+			runner.Infof("Skipping cleanup on failure, keeping the instance for debugging")
+			return errors.Wrap(waitErr, "fail to wait for resources")
+		}
+		// A cancelled context is cleaned up by the signal handler in main. Otherwise the wait error
+		// is the outcome, so a failed delete is only logged rather than masking it
+		if ctx.Err() != nil {
+			return errors.Wrap(waitErr, "fail to wait for resources")
+		}
+		if err := kroRunner.DeleteResources(ctx); err != nil {
+			log.Printf("Warning: fail to delete resources after wait failure: %v", err)
+		} else {
+			runner.Infof("ResourceGraph runner deleted after wait failure")
+		}
+		return errors.Wrap(waitErr, "fail to wait for resources")
+	}
+
+	runner.Infof("ResourceGraph runner completed successfully")
+
+	if !opts.CleanupOnSuccess {
+		runner.Infof("Skipping cleanup on success, relying on owner reference garbage collection")
+		return nil
+	}
 
 	if err := kroRunner.DeleteResources(ctx); err != nil {
 		return errors.Wrap(err, "fail to delete resources")
 	}
 
-	log.Println("ResourceGraph runner deleted successfully")
+	runner.Infof("ResourceGraph runner deleted successfully")
 
 	return nil
 }
+
+// newResult builds the runner result, including conditions from runners that report them
+func newResult(r interface{}, opts Opts, start time.Time, runErr error) runner.RunnerResult {
+	var conditions []runner.Condition
+	if reporter, ok := r.(interface{ Conditions() []runner.Condition }); ok {
+		conditions = reporter.Conditions()
+	}
+
+	result := runner.NewRunnerResult(opts.RunnerName, opts.ScaleSetName, start, runErr, conditions)
+	if reporter, ok := r.(interface{ ExitCode() (int, bool) }); ok {
+		if code, found := reporter.ExitCode(); found {
+			result.ExitCode = &code
+		}
+	}
+
+	return result
+}
+
+// ExitCodeError carries the runner container's non-zero exit code for --propagate-exit-code
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitCodeError) Unwrap() error {
+	return e.Err
+}
+
+// propagateExitCode wraps err with the runner's non-zero exit code so main can exit with it
+func propagateExitCode(result runner.RunnerResult, err error) error {
+	if result.ExitCode == nil || *result.ExitCode == 0 {
+		return err
+	}
+	if err == nil {
+		err = errors.Errorf("runner exited with code %d", *result.ExitCode)
+	}
+	return &ExitCodeError{Code: *result.ExitCode, Err: err}
+}
+
+// resultSentinel prefixes the completion line on stdout. Its format is a stable interface for
+// tooling that greps for completion; new fields may only be appended.
+const resultSentinel = "KAR_RESULT"
+
+// writeSentinel prints the completion line, e.g. "KAR_RESULT outcome=succeeded runner=r1 duration=12.3"
+func writeSentinel(out io.Writer, result runner.RunnerResult) {
+	if _, err := fmt.Fprintf(out, "%s outcome=%s runner=%s duration=%.1f\n",
+		resultSentinel, result.Outcome, result.RunnerName, result.DurationSeconds); err != nil {
+		log.Printf("Warning: failed to write the result sentinel: %v", err)
+	}
+}
+
+// reportResult writes the runner result to the result file and webhook; failures are only logged
+func reportResult(result runner.RunnerResult, opts Opts) {
+	if opts.ResultFile != "" {
+		if err := result.WriteFile(opts.ResultFile); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			runner.Infof("Runner result written to %s", opts.ResultFile)
+		}
+	}
+
+	if opts.ResultWebhookURL != "" {
+		if err := postResultWebhook(result, opts); err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			runner.Infof("Runner result posted to webhook")
+		}
+	}
+}
+
+// postResultWebhook delivers the result independently of the run context, which may already be cancelled
+func postResultWebhook(result runner.RunnerResult, opts Opts) error {
+	var token string
+	if opts.ResultWebhookTokenFile != "" {
+		data, err := os.ReadFile(opts.ResultWebhookTokenFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to read result webhook token")
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.ResultWebhookTimeout)
+	defer cancel()
+
+	return result.Post(ctx, http.DefaultClient, opts.ResultWebhookURL, token)
+}
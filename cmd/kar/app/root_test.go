@@ -17,9 +17,22 @@ limitations under the License.
 package app
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	runner "github.com/fire-ant/kro-actions-runner/internal"
 )
 
 // mockRunner implements the required interface for testing
@@ -49,6 +62,48 @@ func (m *mockRunner) DeleteResources(_ context.Context) error {
 	return m.deleteErr
 }
 
+// configurableRunner records the options applied by run
+type configurableRunner struct {
+	mockRunner
+	options []runner.Option
+}
+
+func (c *configurableRunner) Configure(opts ...runner.Option) {
+	c.options = append(c.options, opts...)
+}
+
+// exitCodeRunner reports a runner container exit code like KRORunner
+type exitCodeRunner struct {
+	mockRunner
+	exitCode int
+}
+
+func (e *exitCodeRunner) ExitCode() (int, bool) {
+	return e.exitCode, true
+}
+
+// leaseRunner records when the runner Lease is held relative to the lifecycle
+type leaseRunner struct {
+	mockRunner
+	leaseErr     error
+	held         bool
+	heldOnCreate bool
+	released     bool
+}
+
+func (l *leaseRunner) StartLease(_ context.Context, _ string) (func(), error) {
+	if l.leaseErr != nil {
+		return nil, l.leaseErr
+	}
+	l.held = true
+	return func() { l.held, l.released = false, true }, nil
+}
+
+func (l *leaseRunner) CreateResources(ctx context.Context, runnerName string, jitConfig string) error {
+	l.heldOnCreate = l.held
+	return l.mockRunner.CreateResources(ctx, runnerName, jitConfig)
+}
+
 // TestNewRootCommand tests the NewRootCommand function
 func TestNewRootCommand(t *testing.T) {
 	ctx := context.Background()
@@ -78,11 +133,12 @@ func TestRunSuccess(t *testing.T) {
 	ctx := context.Background()
 	runner := &mockRunner{}
 	opts := Opts{
-		RunnerName: "test-runner",
-		JitConfig:  "test-jit-config",
+		RunnerName:       "test-runner",
+		JitConfig:        "test-jit-config",
+		CleanupOnSuccess: true,
 	}
 
-	err := run(ctx, runner, opts)
+	err := run(ctx, io.Discard, runner, opts)
 	if err != nil {
 		t.Errorf("run() error = %v, want nil", err)
 	}
@@ -110,7 +166,7 @@ func TestRunCreateError(t *testing.T) {
 		JitConfig:  "test-jit-config",
 	}
 
-	err := run(ctx, runner, opts)
+	err := run(ctx, io.Discard, runner, opts)
 	if err == nil {
 		t.Fatal("run() error = nil, want error")
 	}
@@ -128,29 +184,47 @@ func TestRunCreateError(t *testing.T) {
 
 // TestRunWaitError tests run with WaitForResourceGraph error
 func TestRunWaitError(t *testing.T) {
-	ctx := context.Background()
-	expectedErr := errors.New("wait error")
-	runner := &mockRunner{
-		waitErr: expectedErr,
-	}
-	opts := Opts{
-		RunnerName: "test-runner",
-		JitConfig:  "test-jit-config",
+	tests := []struct {
+		name             string
+		cleanupOnFailure bool
+		deleteErr        error
+	}{
+		{name: "Cleanup enabled deletes the instance", cleanupOnFailure: true},
+		{name: "Cleanup delete error keeps the wait error", cleanupOnFailure: true, deleteErr: errors.New("delete error")},
+		{name: "Cleanup disabled keeps the instance", cleanupOnFailure: false},
 	}
 
-	err := run(ctx, runner, opts)
-	if err == nil {
-		t.Fatal("run() error = nil, want error")
-	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectedErr := errors.New("wait error")
+			runner := &mockRunner{
+				waitErr:   expectedErr,
+				deleteErr: tt.deleteErr,
+			}
+			opts := Opts{
+				RunnerName:       "test-runner",
+				JitConfig:        "test-jit-config",
+				CleanupOnFailure: tt.cleanupOnFailure,
+			}
 
-	if !runner.called.create {
-		t.Error("CreateResources was not called")
-	}
-	if !runner.called.wait {
-		t.Error("WaitForResourceGraph was not called")
-	}
-	if runner.called.delete {
-		t.Error("DeleteResources should not be called after WaitForResourceGraph error")
+			err := run(context.Background(), io.Discard, runner, opts)
+			if err == nil {
+				t.Fatal("run() error = nil, want error")
+			}
+			if !errors.Is(err, expectedErr) {
+				t.Errorf("run() error = %v, want %v", err, expectedErr)
+			}
+
+			if !runner.called.create {
+				t.Error("CreateResources was not called")
+			}
+			if !runner.called.wait {
+				t.Error("WaitForResourceGraph was not called")
+			}
+			if runner.called.delete != tt.cleanupOnFailure {
+				t.Errorf("DeleteResources called = %v, want %v", runner.called.delete, tt.cleanupOnFailure)
+			}
+		})
 	}
 }
 
@@ -162,11 +236,12 @@ func TestRunDeleteError(t *testing.T) {
 		deleteErr: expectedErr,
 	}
 	opts := Opts{
-		RunnerName: "test-runner",
-		JitConfig:  "test-jit-config",
+		RunnerName:       "test-runner",
+		JitConfig:        "test-jit-config",
+		CleanupOnSuccess: true,
 	}
 
-	err := run(ctx, runner, opts)
+	err := run(ctx, io.Discard, runner, opts)
 	if err == nil {
 		t.Fatal("run() error = nil, want error")
 	}
@@ -191,7 +266,7 @@ func TestRunInvalidRunner(t *testing.T) {
 		JitConfig:  "test-jit-config",
 	}
 
-	err := run(ctx, invalidRunner, opts)
+	err := run(ctx, io.Discard, invalidRunner, opts)
 	if err == nil {
 		t.Fatal("run() error = nil, want error for invalid runner")
 	}
@@ -201,3 +276,807 @@ func TestRunInvalidRunner(t *testing.T) {
 		t.Errorf("run() error message = %q, want %q", err.Error(), expectedMsg)
 	}
 }
+
+// TestRunConfiguresRunner tests that run applies flag-driven options before creating resources
+func TestRunConfiguresRunner(t *testing.T) {
+	r := &configurableRunner{}
+	opts := Opts{
+		RunnerName: "test-runner",
+		JitConfig:  "test-jit-config",
+		PrintSpec:  true,
+	}
+
+	if err := run(context.Background(), io.Discard, r, opts); err != nil {
+		t.Fatalf("run() error = %v, want nil", err)
+	}
+
+	if len(r.options) == 0 {
+		t.Error("Configure was not called with runner options")
+	}
+	if !r.called.create {
+		t.Error("CreateResources was not called")
+	}
+}
+
+// TestRunInvalidSpecOverrides tests that malformed spec overrides abort before creating resources
+func TestRunInvalidSpecOverrides(t *testing.T) {
+	r := &configurableRunner{}
+	opts := Opts{
+		RunnerName:    "test-runner",
+		JitConfig:     "test-jit-config",
+		SpecOverrides: "not-json",
+	}
+
+	if err := run(context.Background(), io.Discard, r, opts); err == nil {
+		t.Fatal("run() error = nil, want error for invalid spec overrides")
+	}
+	if r.called.create {
+		t.Error("CreateResources should not be called with invalid spec overrides")
+	}
+}
+
+// TestRunnerOptionsExtraSelector tests parsing of the extra RGD selector
+func TestRunnerOptionsExtraSelector(t *testing.T) {
+	tests := []struct {
+		name      string
+		selector  string
+		expectErr bool
+	}{
+		{name: "Empty selector", selector: ""},
+		{name: "Single requirement", selector: "env=prod"},
+		{name: "Multiple requirements", selector: "env=prod,tier=gold"},
+		{name: "Missing value separator", selector: "env", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Opts{RGDExtraSelector: tt.selector}.runnerOptions()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("runnerOptions() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+// TestValidateOpts tests rejection of contradictory flag combinations
+func TestValidateOpts(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      Opts
+		conflicts []string
+	}{
+		{
+			name: "Defaults",
+			opts: Opts{CreateTimeout: runner.DefaultCreateTimeout},
+		},
+		{
+			name:      "Debug stop with informer",
+			opts:      Opts{UseInformer: true, TerminateOnFirstEvent: true},
+			conflicts: []string{"--terminate-on-first-event"},
+		},
+		{
+			name:      "Registration path with informer",
+			opts:      Opts{UseInformer: true, RegisteredStatusPath: "status.registered"},
+			conflicts: []string{"--registered-status-path"},
+		},
+		{
+			name:      "Completion expression with informer",
+			opts:      Opts{UseInformer: true, FailureExpr: "status.failed"},
+			conflicts: []string{"--completion-expr and --failure-expr"},
+		},
+		{
+			name:      "Failed grace with informer",
+			opts:      Opts{UseInformer: true, FailedGrace: time.Minute, CreateTimeout: runner.DefaultCreateTimeout},
+			conflicts: []string{"--failed-grace"},
+		},
+		{
+			name:      "Pod fallback with informer",
+			opts:      Opts{UseInformer: true, WatchPodFallback: true},
+			conflicts: []string{"--watch-pod-fallback"},
+		},
+		{
+			name:      "Deterministic name watched by label",
+			opts:      Opts{DeterministicName: true, WatchByLabel: true, CreateTimeout: runner.DefaultCreateTimeout},
+			conflicts: []string{"--watch-by-label"},
+		},
+		{
+			name:      "JIT secret name without a reference",
+			opts:      Opts{JITSecretName: "arc-secret", JITReferenceStyle: runner.JITReferenceNone},
+			conflicts: []string{"--jit-secret-name"},
+		},
+		{
+			name:      "Active wait without create timeout",
+			opts:      Opts{WaitActiveOnCreate: true},
+			conflicts: []string{"--wait-active-on-create"},
+		},
+		{
+			name:      "Runner name from missing JIT config",
+			opts:      Opts{RunnerNameFromJITConfig: true},
+			conflicts: []string{"--runner-name-from-jitconfig"},
+		},
+		{
+			name:      "Every conflict is listed",
+			opts:      Opts{UseInformer: true, TerminateOnFirstEvent: true, ResultWebhookTokenFile: "/var/run/token"},
+			conflicts: []string{"--terminate-on-first-event", "--result-webhook-token-file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOpts(tt.opts)
+			if (err != nil) != (len(tt.conflicts) > 0) {
+				t.Fatalf("validateOpts() error = %v, want conflicts %v", err, tt.conflicts)
+			}
+			for _, conflict := range tt.conflicts {
+				if !strings.Contains(err.Error(), conflict) {
+					t.Errorf("validateOpts() error = %v, want it to mention %s", err, conflict)
+				}
+			}
+		})
+	}
+}
+
+// TestResolveScaleSetName tests scale set name precedence between flag/env and file
+func TestResolveScaleSetName(t *testing.T) {
+	dir := t.TempDir()
+	nameFile := filepath.Join(dir, "scale-set-name")
+	if err := os.WriteFile(nameFile, []byte("  file-scale-set\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		value     string
+		file      string
+		expected  string
+		expectErr bool
+	}{
+		{name: "Flag or env wins over file", value: "flag-scale-set", file: nameFile, expected: "flag-scale-set"},
+		{name: "File is read and trimmed", value: "", file: nameFile, expected: "file-scale-set"},
+		{name: "No file leaves name empty", value: "", file: "", expected: ""},
+		{name: "Missing file", value: "", file: filepath.Join(dir, "missing"), expectErr: true},
+		{name: "Empty file", value: "", file: emptyFile, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ResolveScaleSetName(tt.value, tt.file)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ResolveScaleSetName() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if result != tt.expected {
+				t.Errorf("ResolveScaleSetName() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRootCommandScaleSetNameFile tests that a scale set name read from a file reaches hooks and the result file
+func TestRootCommandScaleSetNameFile(t *testing.T) {
+	dir := t.TempDir()
+	nameFile := filepath.Join(dir, "scale-set-name")
+	if err := os.WriteFile(nameFile, []byte("file-scale-set\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hookOutput := filepath.Join(dir, "hook-env")
+	resultFile := filepath.Join(dir, "result.json")
+
+	cmd := NewRootCommand(context.Background(), &mockRunner{}, Opts{})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{
+		"--scale-set-name-file", nameFile,
+		"--runner-name", "test-runner",
+		"-c", "test-jit-config",
+		"--pre-create-hook", `printf %s "$KAR_SCALE_SET_NAME" > ` + hookOutput,
+		"--result-file", resultFile,
+	})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	hookEnv, err := os.ReadFile(hookOutput)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if string(hookEnv) != "file-scale-set" {
+		t.Errorf("KAR_SCALE_SET_NAME = %q, want file-scale-set", hookEnv)
+	}
+
+	data, err := os.ReadFile(resultFile)
+	if err != nil {
+		t.Fatalf("result file not written: %v", err)
+	}
+	var result runner.RunnerResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("result file is not JSON: %v", err)
+	}
+	if result.ScaleSetName != "file-scale-set" {
+		t.Errorf("result.ScaleSetName = %q, want file-scale-set", result.ScaleSetName)
+	}
+}
+
+// TestRootCommandRejectsConflictingFlags tests that conflicts abort before any runner call
+func TestRootCommandRejectsConflictingFlags(t *testing.T) {
+	r := &mockRunner{}
+	cmd := NewRootCommand(context.Background(), r, Opts{})
+	cmd.SetArgs([]string{"--use-informer", "--terminate-on-first-event", "-c", "test-jit-config"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "invalid flag combination") {
+		t.Fatalf("Execute() error = %v, want invalid flag combination", err)
+	}
+	if r.called.create {
+		t.Error("CreateResources should not be called with conflicting flags")
+	}
+}
+
+// TestRunnerOptionsRunnerLabels tests validation of the runner label list
+func TestRunnerOptionsRunnerLabels(t *testing.T) {
+	tests := []struct {
+		name      string
+		labels    string
+		expectErr bool
+	}{
+		{name: "Unset", labels: ""},
+		{name: "Valid", labels: "linux,x64,self-hosted"},
+		{name: "Empty label", labels: "linux,,x64", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Opts{RunnerLabels: tt.labels}.runnerOptions()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("runnerOptions() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+// TestRunnerOptionsOnConflict tests validation of the create conflict policy
+func TestRunnerOptionsOnConflict(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		expectErr bool
+	}{
+		{name: "Unset", policy: ""},
+		{name: "Fail", policy: runner.ConflictFail},
+		{name: "Adopt", policy: runner.ConflictAdopt},
+		{name: "Replace", policy: runner.ConflictReplace},
+		{name: "Unknown policy", policy: "overwrite", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Opts{OnConflict: tt.policy}.runnerOptions()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("runnerOptions() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+// TestRunnerOptionsOnMissingPodStatus tests validation of the missing pod status policy
+func TestRunnerOptionsOnMissingPodStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		expectErr bool
+	}{
+		{name: "Unset", policy: ""},
+		{name: "Assume success", policy: runner.MissingPodStatusAssumeSuccess},
+		{name: "Assume failure", policy: runner.MissingPodStatusAssumeFailure},
+		{name: "Error", policy: runner.MissingPodStatusError},
+		{name: "Unknown policy", policy: "ignore", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Opts{OnMissingPodStatus: tt.policy}.runnerOptions()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("runnerOptions() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+// TestRunnerOptionsRGDSelection tests validation of the RGD selection strategy
+func TestRunnerOptionsRGDSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  string
+		expectErr bool
+	}{
+		{name: "Unset", strategy: ""},
+		{name: "Strict", strategy: runner.RGDSelectionStrict},
+		{name: "Newest", strategy: runner.RGDSelectionNewest},
+		{name: "Oldest", strategy: runner.RGDSelectionOldest},
+		{name: "Unknown strategy", strategy: "random", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Opts{RGDSelection: tt.strategy}.runnerOptions()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("runnerOptions() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+// TestRunnerOptionsJITReferenceStyle tests validation of the JIT reference style
+func TestRunnerOptionsJITReferenceStyle(t *testing.T) {
+	tests := []struct {
+		name      string
+		style     string
+		expectErr bool
+	}{
+		{name: "Unset", style: ""},
+		{name: "None", style: runner.JITReferenceNone},
+		{name: "Name", style: runner.JITReferenceName},
+		{name: "SecretKeyRef", style: runner.JITReferenceSecretKeyRef},
+		{name: "Unknown style", style: "inline", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Opts{JITReferenceStyle: tt.style}.runnerOptions()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("runnerOptions() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+// TestRunWritesResultFileOnFailure tests that the result file is written even when the wait fails
+func TestRunWritesResultFileOnFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.json")
+	r := &mockRunner{waitErr: runner.ErrRunnerFailed}
+	opts := Opts{
+		RunnerName: "test-runner",
+		JitConfig:  "test-jit-config",
+		ResultFile: path,
+	}
+
+	if err := run(context.Background(), io.Discard, r, opts); err == nil {
+		t.Fatal("run() error = nil, want error")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("result file was not written: %v", err)
+	}
+
+	var result runner.RunnerResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("result file is not valid JSON: %v", err)
+	}
+	if result.Outcome != runner.OutcomeFailed {
+		t.Errorf("Outcome = %q, want %q", result.Outcome, runner.OutcomeFailed)
+	}
+	if result.RunnerName != "test-runner" {
+		t.Errorf("RunnerName = %q, want %q", result.RunnerName, "test-runner")
+	}
+}
+
+// TestRunPropagateExitCode tests that the runner exit code is recorded and optionally propagated
+func TestRunPropagateExitCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		exitCode     int
+		waitErr      error
+		propagate    bool
+		expectedCode int
+	}{
+		{name: "Non-zero exit code is propagated", exitCode: 2, waitErr: errors.New("runner failed"), propagate: true, expectedCode: 2},
+		{name: "Non-zero exit code of a succeeded run is propagated", exitCode: 1, propagate: true, expectedCode: 1},
+		{name: "Zero exit code keeps the outcome", exitCode: 0, propagate: true},
+		{name: "Propagation disabled", exitCode: 2, waitErr: errors.New("runner failed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resultFile := filepath.Join(t.TempDir(), "result.json")
+			r := &exitCodeRunner{mockRunner: mockRunner{waitErr: tt.waitErr}, exitCode: tt.exitCode}
+			opts := Opts{
+				RunnerName:        "test-runner",
+				JitConfig:         "test-jit-config",
+				ResultFile:        resultFile,
+				PropagateExitCode: tt.propagate,
+			}
+
+			err := run(context.Background(), io.Discard, r, opts)
+
+			var exitErr *ExitCodeError
+			if tt.expectedCode != 0 {
+				if !errors.As(err, &exitErr) {
+					t.Fatalf("run() error = %v, want ExitCodeError", err)
+				}
+				if exitErr.Code != tt.expectedCode {
+					t.Errorf("ExitCodeError.Code = %d, want %d", exitErr.Code, tt.expectedCode)
+				}
+			} else if errors.As(err, &exitErr) {
+				t.Errorf("run() error = %v, want no ExitCodeError", err)
+			}
+			if tt.waitErr != nil && !errors.Is(err, tt.waitErr) {
+				t.Errorf("run() error = %v, want it to wrap %v", err, tt.waitErr)
+			}
+
+			data, err := os.ReadFile(resultFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var result runner.RunnerResult
+			if err := json.Unmarshal(data, &result); err != nil {
+				t.Fatal(err)
+			}
+			if result.ExitCode == nil || *result.ExitCode != tt.exitCode {
+				t.Errorf("result.ExitCode = %v, want %d", result.ExitCode, tt.exitCode)
+			}
+		})
+	}
+}
+
+// TestRootCommandVersion tests that --version prints the build info without touching the cluster
+func TestRootCommandVersion(t *testing.T) {
+	for _, args := range [][]string{{"--version"}, {"-v"}, {"diagnose", "--version"}} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			r := &mockRunner{}
+			cmd := NewRootCommand(context.Background(), r, Opts{Version: "commit: abc123"})
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs(args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := out.String(); got != "kro-actions-runner commit: abc123\n" {
+				t.Errorf("output = %q, want the version line", got)
+			}
+			if r.called.create || r.called.wait || r.called.delete {
+				t.Errorf("runner was called: %+v", r.called)
+			}
+		})
+	}
+}
+
+// TestRootCommandIgnoresVersionEnv tests that unrelated VERSION and HELP variables do not reach the flags
+func TestRootCommandIgnoresVersionEnv(t *testing.T) {
+	t.Setenv("VERSION", "1.2.3")
+	t.Setenv("HELP", "yes")
+
+	cmd := NewRootCommand(context.Background(), nil, Opts{Version: "commit: abc123"})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"scaffold-rgd", "--kind", "PodRunner", "--scale-set-name", "linux-builders"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "kind: PodRunner") {
+		t.Errorf("output = %q, want the scaffolded RGD", out.String())
+	}
+}
+
+// TestRunUseLease tests that the Lease is held across the run and released on exit
+func TestRunUseLease(t *testing.T) {
+	tests := []struct {
+		name         string
+		useLease     bool
+		leaseErr     error
+		waitErr      error
+		expectedHeld bool
+	}{
+		{name: "Held across a successful run", useLease: true, expectedHeld: true},
+		{name: "Released after a failed run", useLease: true, waitErr: errors.New("runner failed"), expectedHeld: true},
+		{name: "Lease failure does not stop the run", useLease: true, leaseErr: errors.New("forbidden")},
+		{name: "Disabled", useLease: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &leaseRunner{mockRunner: mockRunner{waitErr: tt.waitErr}, leaseErr: tt.leaseErr}
+			opts := Opts{RunnerName: "test-runner", JitConfig: "test-jit-config", UseLease: tt.useLease}
+
+			err := run(context.Background(), io.Discard, r, opts)
+			if !errors.Is(err, tt.waitErr) {
+				t.Fatalf("run() error = %v, want %v", err, tt.waitErr)
+			}
+			if !r.called.create {
+				t.Error("CreateResources was not called")
+			}
+			if r.heldOnCreate != tt.expectedHeld {
+				t.Errorf("lease held on create = %v, want %v", r.heldOnCreate, tt.expectedHeld)
+			}
+			if r.released != tt.expectedHeld || r.held {
+				t.Errorf("lease released = %v, still held = %v, want released %v", r.released, r.held, tt.expectedHeld)
+			}
+		})
+	}
+}
+
+// TestRunWritesSentinel tests that the result sentinel is written once, as the last line on stdout
+func TestRunWritesSentinel(t *testing.T) {
+	tests := []struct {
+		name     string
+		waitErr  error
+		quiet    bool
+		expected string
+	}{
+		{name: "Succeeded", expected: "KAR_RESULT outcome=succeeded runner=test-runner duration="},
+		{name: "Failed", waitErr: errors.New("runner failed"), expected: "KAR_RESULT outcome=failed runner=test-runner duration="},
+		{name: "Quiet mode", quiet: true, expected: "KAR_RESULT outcome=succeeded runner=test-runner duration="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.quiet {
+				runner.SetQuiet(true)
+				t.Cleanup(func() { runner.SetQuiet(false) })
+			}
+			opts := Opts{RunnerName: "test-runner", JitConfig: "test-jit-config"}
+
+			var out bytes.Buffer
+			_ = run(context.Background(), &out, &mockRunner{waitErr: tt.waitErr}, opts)
+
+			if count := strings.Count(out.String(), "KAR_RESULT"); count != 1 {
+				t.Fatalf("sentinel written %d times, want once: %q", count, out.String())
+			}
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			last := lines[len(lines)-1]
+			if !strings.HasPrefix(last, tt.expected) {
+				t.Errorf("last stdout line = %q, want prefix %q", last, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRunCleanupOnSuccess tests that the explicit delete after success follows the flag
+func TestRunCleanupOnSuccess(t *testing.T) {
+	tests := []struct {
+		name             string
+		cleanupOnSuccess bool
+	}{
+		{name: "Cleanup enabled", cleanupOnSuccess: true},
+		{name: "Cleanup disabled relies on GC", cleanupOnSuccess: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &mockRunner{}
+			opts := Opts{
+				RunnerName:       "test-runner",
+				JitConfig:        "test-jit-config",
+				CleanupOnSuccess: tt.cleanupOnSuccess,
+			}
+
+			if err := run(context.Background(), io.Discard, r, opts); err != nil {
+				t.Fatalf("run() error = %v, want nil", err)
+			}
+			if r.called.delete != tt.cleanupOnSuccess {
+				t.Errorf("DeleteResources called = %v, want %v", r.called.delete, tt.cleanupOnSuccess)
+			}
+		})
+	}
+}
+
+// TestRunPostsResultWebhook tests webhook delivery and that delivery failures do not change the result
+func TestRunPostsResultWebhook(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "Delivered", status: http.StatusOK},
+		{name: "Delivery failure is ignored", status: http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenFile := filepath.Join(t.TempDir(), "token")
+			if err := os.WriteFile(tokenFile, []byte("secret-token\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			var (
+				received      runner.RunnerResult
+				authorization string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				authorization = req.Header.Get("Authorization")
+				_ = json.NewDecoder(req.Body).Decode(&received)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			opts := Opts{
+				RunnerName:             "test-runner",
+				JitConfig:              "test-jit-config",
+				ResultWebhookURL:       server.URL,
+				ResultWebhookTimeout:   5 * time.Second,
+				ResultWebhookTokenFile: tokenFile,
+			}
+			if err := run(context.Background(), io.Discard, &mockRunner{}, opts); err != nil {
+				t.Fatalf("run() error = %v, want nil", err)
+			}
+
+			if received.Outcome != runner.OutcomeSucceeded {
+				t.Errorf("webhook Outcome = %q, want %q", received.Outcome, runner.OutcomeSucceeded)
+			}
+			if authorization != "Bearer secret-token" {
+				t.Errorf("Authorization = %q, want %q", authorization, "Bearer secret-token")
+			}
+		})
+	}
+}
+
+// TestRunQuiet tests that quiet mode keeps only the outcome summary for a successful run
+func TestRunQuiet(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	runner.SetQuiet(true)
+	t.Cleanup(func() { runner.SetQuiet(false) })
+
+	opts := Opts{RunnerName: "test-runner", JitConfig: "test-jit-config", CleanupOnSuccess: true}
+	if err := run(context.Background(), io.Discard, &mockRunner{}, opts); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	if strings.Contains(logs.String(), "created successfully") {
+		t.Errorf("quiet mode logged routine progress:\n%s", logs.String())
+	}
+	if !strings.Contains(logs.String(), "Runner test-runner finished: outcome=succeeded") {
+		t.Errorf("quiet mode dropped the outcome summary:\n%s", logs.String())
+	}
+}
+
+// testJITConfig encodes a JIT config whose .runner file names the given runner
+func testJITConfig(runnerName string) string {
+	runnerFile := base64.StdEncoding.EncodeToString([]byte(`{"AgentId":"42","AgentName":"` + runnerName + `"}`))
+	return base64.StdEncoding.EncodeToString([]byte(`{".runner":"` + runnerFile + `"}`))
+}
+
+// TestResolveRunnerName tests taking the runner name from the JIT config and the mismatch warning
+func TestResolveRunnerName(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Opts
+		expected    string
+		expectErr   bool
+		wantWarning bool
+	}{
+		{
+			name:     "Mode disabled keeps the flag",
+			opts:     Opts{RunnerName: "runner", JitConfig: testJITConfig("arc-runner-abcde")},
+			expected: "runner",
+		},
+		{
+			name:     "Unset name comes from the JIT config",
+			opts:     Opts{RunnerName: "runner", JitConfig: testJITConfig("arc-runner-abcde"), RunnerNameFromJITConfig: true},
+			expected: "arc-runner-abcde",
+		},
+		{
+			name: "Matching explicit name",
+			opts: Opts{RunnerName: "arc-runner-abcde", runnerNameSet: true, JitConfig: testJITConfig("arc-runner-abcde"),
+				RunnerNameFromJITConfig: true},
+			expected: "arc-runner-abcde",
+		},
+		{
+			name: "Mismatched explicit name wins with a warning",
+			opts: Opts{RunnerName: "other-runner", runnerNameSet: true, JitConfig: testJITConfig("arc-runner-abcde"),
+				RunnerNameFromJITConfig: true},
+			expected:    "other-runner",
+			wantWarning: true,
+		},
+		{
+			name:      "Undecodable JIT config without a name",
+			opts:      Opts{RunnerName: "runner", JitConfig: "opaque", RunnerNameFromJITConfig: true},
+			expectErr: true,
+		},
+		{
+			name:        "Undecodable JIT config with a name",
+			opts:        Opts{RunnerName: "test-runner", runnerNameSet: true, JitConfig: "opaque", RunnerNameFromJITConfig: true},
+			expected:    "test-runner",
+			wantWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+			got, err := resolveRunnerName(tt.opts)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("resolveRunnerName() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if got != tt.expected {
+				t.Errorf("resolveRunnerName() = %q, want %q", got, tt.expected)
+			}
+			if warned := strings.Contains(logs.String(), "Warning:"); warned != tt.wantWarning {
+				t.Errorf("warning logged = %v, want %v:\n%s", warned, tt.wantWarning, logs.String())
+			}
+		})
+	}
+}
+
+// TestRunSimulated tests the cobra wiring end to end against the simulated runner
+func TestRunSimulated(t *testing.T) {
+	resultFile := filepath.Join(t.TempDir(), "result.json")
+
+	cmd := NewRootCommand(context.Background(), runner.NewSimulatedRunner("default", "demo"), Opts{})
+	cmd.SetArgs([]string{
+		"--scale-set-name", "demo",
+		"--runner-name", "demo-runner",
+		"--actions-runner-input-jitconfig", "simulated-config",
+		"--result-file", resultFile,
+	})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	data, err := os.ReadFile(resultFile)
+	if err != nil {
+		t.Fatalf("result file not written: %v", err)
+	}
+	var result runner.RunnerResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("result file is not JSON: %v", err)
+	}
+	if result.Outcome != runner.OutcomeSucceeded || result.RunnerName != "demo-runner" {
+		t.Errorf("result = %+v, want runner demo-runner succeeded", result)
+	}
+}
+
+// TestRunnerOptionsKROController tests validation of the KRO controller Deployment reference
+func TestRunnerOptionsKROController(t *testing.T) {
+	tests := []struct {
+		name       string
+		controller string
+		expectErr  bool
+	}{
+		{name: "Unset", controller: ""},
+		{name: "Namespace and name", controller: "kro-system/kro-controller"},
+		{name: "Name only", controller: "kro", expectErr: true},
+		{name: "Empty name", controller: "kro/", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Opts{KROController: tt.controller}.runnerOptions()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("runnerOptions() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+// TestRunnerOptionsScheduling tests validation of node selectors and tolerations
+func TestRunnerOptionsScheduling(t *testing.T) {
+	tests := []struct {
+		name          string
+		nodeSelectors []string
+		tolerations   []string
+		expectErr     bool
+	}{
+		{name: "Unset"},
+		{name: "Valid", nodeSelectors: []string{"pool=gpu"}, tolerations: []string{"gpu:NoSchedule"}},
+		{name: "Invalid node selector", nodeSelectors: []string{"pool"}, expectErr: true},
+		{name: "Invalid toleration effect", tolerations: []string{"gpu:Always"}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Opts{NodeSelectors: tt.nodeSelectors, Tolerations: tt.tolerations}.runnerOptions()
+			if (err != nil) != tt.expectErr {
+				t.Errorf("runnerOptions() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
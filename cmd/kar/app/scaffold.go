@@ -0,0 +1,64 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	runner "github.com/fire-ant/kro-actions-runner/internal"
+	"github.com/spf13/cobra"
+)
+
+// scaffoldRGDCommand is the name of the scaffold subcommand, which the entrypoint runs without cluster access
+const scaffoldRGDCommand = "scaffold-rgd"
+
+// IsOfflineCommand reports whether args invoke a subcommand that runs without cluster access.
+// The subcommand is resolved by cobra, so root flags before it are skipped with their values.
+func IsOfflineCommand(args []string) bool {
+	cmd, _, err := NewRootCommand(context.Background(), nil, Opts{}).Find(args)
+	return err == nil && cmd.Name() == scaffoldRGDCommand
+}
+
+func newScaffoldRGDCommand(opts *Opts) *cobra.Command {
+	var kind string
+
+	cmd := &cobra.Command{
+		Use:   scaffoldRGDCommand,
+		Short: "Print a minimal kar-compatible ResourceGraphDefinition skeleton",
+		Long: "Prints a ResourceGraphDefinition YAML labelled for discovery by --scale-set-name, with the " +
+			"runnerName spec field kar sets and a runnerPod resource whose readyWhen drives ResourcesReady. " +
+			"Works offline; nothing is created.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return scaffoldRGD(cmd.OutOrStdout(), kind, opts.ScaleSetName)
+		},
+	}
+	cmd.Flags().StringVar(&kind, "kind", "PodRunner", "Kind of the instances the RGD defines.")
+
+	return cmd
+}
+
+func scaffoldRGD(out io.Writer, kind, scaleSetName string) error {
+	rgd, err := runner.ScaffoldRGD(kind, scaleSetName)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(out, rgd)
+	return err
+}
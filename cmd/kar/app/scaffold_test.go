@@ -0,0 +1,77 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+// TestScaffoldRGDCommand tests that the scaffold command prints a labelled RGD without a runner
+func TestScaffoldRGDCommand(t *testing.T) {
+	cmd := NewRootCommand(context.Background(), nil, Opts{})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"scaffold-rgd", "--kind", "PodRunner", "--scale-set-name", "linux-builders"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var rgd struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal(out.Bytes(), &rgd); err != nil {
+		t.Fatalf("output is not YAML: %v\n%s", err, out.String())
+	}
+	if got := rgd.Metadata.Labels["actions.github.com/scale-set-name"]; got != "linux-builders" {
+		t.Errorf("scale set label = %q, want linux-builders", got)
+	}
+	if !strings.Contains(out.String(), "kind: PodRunner") {
+		t.Errorf("output does not define kind PodRunner:\n%s", out.String())
+	}
+}
+
+// TestIsOfflineCommand tests which subcommands the entrypoint runs without cluster access
+func TestIsOfflineCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{name: "Scaffold", args: []string{"scaffold-rgd"}, expected: true},
+		{name: "Scaffold with its own flag", args: []string{"scaffold-rgd", "--kind", "JobRunner"}, expected: true},
+		{name: "Leading bool flag", args: []string{"--validate", "scaffold-rgd"}, expected: true},
+		{name: "Leading flag with value", args: []string{"--scale-set-name", "my-scale-set", "scaffold-rgd"}, expected: true},
+		{name: "Other subcommand", args: []string{"list"}, expected: false},
+		{name: "Root command", args: []string{"-c", "test-jit-config"}, expected: false},
+		{name: "No args", args: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsOfflineCommand(tt.args); got != tt.expected {
+				t.Errorf("IsOfflineCommand(%q) = %v, want %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
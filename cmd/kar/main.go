@@ -18,10 +18,14 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"strings"
 	"syscall"
 	"time"
 
@@ -29,8 +33,13 @@ import (
 	runner "github.com/fire-ant/kro-actions-runner/internal"
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -64,9 +73,26 @@ func getBuildInfo() buildInfo {
 	return out
 }
 
+func (b buildInfo) String() string {
+	return fmt.Sprintf("commit: %v\tmodified: %v\tdate: %v\tgo: %v", b.gitCommit, b.gitTreeModified, b.buildDate, b.goVersion)
+}
+
+// logBanner logs the build info at startup unless noBanner is set; --version still prints it
+func logBanner(b buildInfo, noBanner bool) {
+	if noBanner {
+		return
+	}
+	runner.Infof("starting kro-actions-runner\n%s\n", b)
+}
+
 func getCleanupTimeout() time.Duration {
 	if val := os.Getenv("KAR_CLEANUP_TIMEOUT"); val != "" {
 		if d, err := time.ParseDuration(val); err == nil {
+			if d <= 0 {
+				log.Printf("Warning: KAR_CLEANUP_TIMEOUT=%s disables the cleanup timeout; "+
+					"cleanup can block shutdown until the pod is killed", val)
+				return 0
+			}
 			return d
 		}
 
@@ -78,81 +104,366 @@ func getCleanupTimeout() time.Duration {
 
 func ensureValidCleanupContext(parent context.Context) (context.Context, context.CancelFunc) {
 	if parent.Err() != nil {
-		return context.WithTimeout(context.TODO(), getCleanupTimeout())
+		parent = context.Background()
+	}
+
+	// A zero timeout means no timeout rather than an already expired context
+	timeout := getCleanupTimeout()
+	if timeout <= 0 {
+		return context.WithCancel(parent)
 	}
 
-	return context.WithTimeout(parent, getCleanupTimeout())
+	return context.WithTimeout(parent, timeout)
 }
 
-func main() {
-	var (
-		opts app.Opts
-		err  error
-	)
+// cleanupOnDone deletes the runner resources once ctx is done, e.g. on SIGTERM. Closing finished first
+// means the command returned on its own and applied its --cleanup-on-* policy, so nothing is deleted.
+// The returned channel is closed when cleanup has finished.
+func cleanupOnDone(ctx context.Context, r interface {
+	DeleteResources(ctx context.Context) error
+}, detachOnSignal bool, finished <-chan struct{}) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-ctx.Done():
+		case <-finished:
+			// A signal that raced the command's return still needs its cleanup
+			if ctx.Err() == nil {
+				return
+			}
+		}
 
-	buildInfo := getBuildInfo()
-	log.Printf("starting kro-actions-runner\ncommit: %v\tmodified: %v\tdate: %v\tgo: %v\n",
-		buildInfo.gitCommit, buildInfo.gitTreeModified, buildInfo.buildDate, buildInfo.goVersion)
+		// A drained orchestrator should not kill a healthy job; owner reference or external GC reaps it later
+		if detachOnSignal {
+			if h, ok := r.(interface{ InstanceHealthy() bool }); ok && h.InstanceHealthy() {
+				log.Println("Stopping with the runner still healthy, detaching without deleting the instance")
+				return
+			}
+		}
 
-	// Parse flags
-	pflag.StringVar(&opts.ScaleSetName, "scale-set-name", os.Getenv("ACTIONS_RUNNER_SCALE_SET_NAME"), "Scale set name")
-	pflag.StringVar(&opts.RunnerName, "runner-name", os.Getenv("RUNNER_NAME"), "Runner name")
-	pflag.StringVar(&opts.JitConfig, "actions-runner-input-jitconfig", os.Getenv("ACTIONS_RUNNER_INPUT_JITCONFIG"), "JIT config")
-	pflag.Parse()
+		cleanupCtx, cancel := ensureValidCleanupContext(ctx)
+		defer cancel()
 
-	// Get kubeconfig and namespace
+		// Call DeleteResources
+		if err := r.DeleteResources(cleanupCtx); err != nil {
+			log.Println("cleanup failed:", err)
+		}
+	}()
+	return done
+}
+
+// execute runs the command while ctx is bound to termination signals; a signal deletes the runner
+// resources, and execute waits for that cleanup before returning
+func execute(ctx context.Context, cmd interface{ Execute() error }, r interface {
+	DeleteResources(ctx context.Context) error
+}, detachOnSignal bool) error {
+	finished := make(chan struct{})
+	cleanupDone := cleanupOnDone(ctx, r, detachOnSignal, finished)
+
+	err := cmd.Execute()
+	close(finished)
+	<-cleanupDone
+
+	return err
+}
+
+// resolveNamespace normalizes the namespace from kubeconfig and validates it is a DNS-1123 label
+func resolveNamespace(namespace string) (string, error) {
+	namespace = strings.TrimSpace(namespace)
+	if namespace == "" {
+		return "default", nil
+	}
+
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return "", fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, "; "))
+	}
+
+	return namespace, nil
+}
+
+// ensureNamespace verifies the namespace exists, optionally creating it when missing
+func ensureNamespace(ctx context.Context, kubeClient kubernetes.Interface, namespace string, create bool) error {
+	ns, err := kubeClient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	switch {
+	case err == nil && ns.Status.Phase == corev1.NamespaceTerminating:
+		// Creating into it would fail with a forbidden error that does not say why
+		return errors.Wrapf(runner.ErrNamespaceTerminating, "namespace %s is being deleted", namespace)
+	case err == nil:
+		return nil
+	case k8serrors.IsForbidden(err):
+		// Namespaced service accounts usually cannot read namespaces; later calls will surface real problems
+		log.Printf("Warning: cannot verify namespace %s exists: %v", namespace, err)
+		return nil
+	case !k8serrors.IsNotFound(err):
+		return errors.Wrapf(err, "failed to get namespace %s", namespace)
+	case !create:
+		return fmt.Errorf("namespace %s does not exist (use --create-namespace to create it)", namespace)
+	}
+
+	runner.Infof("Creating namespace: %s", namespace)
+	_, err = kubeClient.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: namespace},
+	}, metav1.CreateOptions{})
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create namespace %s", namespace)
+	}
+
+	return nil
+}
+
+// newLoadingRules returns the default kubeconfig loading rules with files prepended to the precedence list,
+// so they are merged ahead of KUBECONFIG or ~/.kube/config
+func newLoadingRules(files []string) (*clientcmd.ClientConfigLoadingRules, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+	for _, file := range files {
+		// Missing files in the precedence list are skipped silently, so catch typos here
+		if _, err := os.Stat(file); err != nil {
+			return nil, errors.Wrap(err, "invalid --kubeconfig-file")
+		}
+	}
+	loadingRules.Precedence = append(append([]string{}, files...), loadingRules.Precedence...)
+	return loadingRules, nil
+}
+
+// effectiveKubeconfigFiles returns the files of the loading rules that exist, in merge order
+func effectiveKubeconfigFiles(loadingRules *clientcmd.ClientConfigLoadingRules) []string {
+	var files []string
+	for _, file := range loadingRules.GetLoadingPrecedence() {
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// newKubeConfig loads the kubeconfig, selecting kubeContext instead of the current context when set
+func newKubeConfig(loadingRules *clientcmd.ClientConfigLoadingRules, kubeContext string) clientcmd.ClientConfig {
+	configOverrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+}
+
+// applyProxyURL routes API server traffic through an explicit proxy, overriding the environment
+func applyProxyURL(config *rest.Config, proxyURL string) error {
+	if proxyURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid proxy URL %q", proxyURL)
+	}
+
+	config.Proxy = http.ProxyURL(u)
+	return nil
+}
+
+// effectiveProxyHost returns the host of the proxy used to reach the API server, without credentials
+func effectiveProxyHost(config *rest.Config) string {
+	proxy := config.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+
+	req, err := http.NewRequest(http.MethodGet, config.Host, nil)
+	if err != nil {
+		return ""
+	}
+
+	u, err := proxy(req)
+	if err != nil || u == nil {
+		return ""
+	}
+
+	return u.Host
+}
+
+// newClusterRunner connects to the cluster from the kubeconfig and returns a runner for it
+func newClusterRunner(opts app.Opts) (*runner.KRORunner, error) {
+	// Get kubeconfig and namespace
+	loadingRules, err := newLoadingRules(opts.KubeconfigFiles)
+	if err != nil {
+		return nil, err
+	}
+	if files := effectiveKubeconfigFiles(loadingRules); len(files) > 0 {
+		runner.Infof("Using kubeconfig files: %s", strings.Join(files, ", "))
+	}
+	kubeConfig := newKubeConfig(loadingRules, opts.KubeContext)
 
 	namespace, _, err := kubeConfig.Namespace()
 	if err != nil {
-		log.Fatalf("error in namespace : %v\n", err)
+		return nil, errors.Wrap(err, "error in namespace")
 	}
-	if namespace == "" {
-		namespace = "default"
+	if opts.RunnerNamespace != "" {
+		namespace = opts.RunnerNamespace
+	}
+	namespace, err = resolveNamespace(namespace)
+	if err != nil {
+		return nil, err
 	}
 
 	// KRO mode only (KubeVirt support removed)
-	log.Printf("Using KRO mode with scale-set-name: %s", opts.ScaleSetName)
+	runner.Infof("Using KRO mode with scale-set-name: %s", opts.ScaleSetName)
 
 	config, err := kubeConfig.ClientConfig()
 	if err != nil {
-		log.Fatalf("cannot obtain kubeconfig: %v\n", err)
+		return nil, errors.Wrap(err, "cannot obtain kubeconfig")
+	}
+
+	if err := applyProxyURL(config, opts.ProxyURL); err != nil {
+		return nil, err
+	}
+	if proxyHost := effectiveProxyHost(config); proxyHost != "" {
+		runner.Infof("Using proxy %s for API server %s", proxyHost, config.Host)
 	}
 
 	dynamicClient, err := dynamic.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("cannot create dynamic client: %v\n", err)
+		return nil, errors.Wrap(err, "cannot create dynamic client")
 	}
 
 	kubeClient, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("cannot create kubernetes client: %v\n", err)
+		return nil, errors.Wrap(err, "cannot create kubernetes client")
 	}
 
-	r := runner.NewKRORunner(namespace, dynamicClient, kubeClient, opts.ScaleSetName)
+	if err := ensureNamespace(context.TODO(), kubeClient, namespace, opts.CreateNamespace); err != nil {
+		return nil, err
+	}
 
-	log.Printf("cleanup timeout is set to: %s", getCleanupTimeout())
+	rawConfig, err := kubeConfig.RawConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read kubeconfig")
+	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	kubeContext := rawConfig.CurrentContext
+	if opts.KubeContext != "" {
+		kubeContext = opts.KubeContext
+	}
+	runner.Infof("Using kubeconfig context: %s", kubeContext)
 
-	go func() {
-		<-ctx.Done()
+	return runner.NewKRORunner(namespace, dynamicClient, kubeClient, opts.ScaleSetName,
+		runner.WithClusterInfo(kubeContext, config.Host), runner.WithCleanupTimeout(getCleanupTimeout())), nil
+}
 
-		cleanupCtx, cancel := ensureValidCleanupContext(ctx)
-		defer cancel()
+// newSimulatedRunner returns a runner backed by in-memory fakes, for demos and tests only
+func newSimulatedRunner(opts app.Opts) *runner.KRORunner {
+	log.Printf("Warning: simulate mode is for demos and tests only, no cluster is used and no runner is started")
 
-		// Call DeleteResources
-		if err := r.DeleteResources(cleanupCtx); err != nil {
-			log.Println("cleanup failed:", err)
+	namespace := opts.RunnerNamespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	runner.Infof("Simulating KRO mode with scale-set-name: %s in namespace %s", opts.ScaleSetName, namespace)
+
+	return runner.NewSimulatedRunner(namespace, opts.ScaleSetName, runner.WithCleanupTimeout(getCleanupTimeout()))
+}
+
+func main() {
+	os.Exit(run())
+}
+
+// run executes kar and returns the process exit code, so deferred cleanup such as flushing the
+// log file and stopping the signal handler happens before main exits
+func run() int {
+	var (
+		opts        app.Opts
+		err         error
+		showVersion bool
+	)
+
+	// Parse flags
+	pflag.StringVar(&opts.ScaleSetName, "scale-set-name", os.Getenv("ACTIONS_RUNNER_SCALE_SET_NAME"), "Scale set name")
+	pflag.StringVar(&opts.ScaleSetNameFile, "scale-set-name-file", os.Getenv("ACTIONS_RUNNER_SCALE_SET_NAME_FILE"), "File containing the scale set name")
+	pflag.StringVar(&opts.RunnerName, "runner-name", os.Getenv("RUNNER_NAME"), "Runner name")
+	pflag.StringVar(&opts.JitConfig, "actions-runner-input-jitconfig", os.Getenv("ACTIONS_RUNNER_INPUT_JITCONFIG"), "JIT config")
+	pflag.BoolVar(&opts.CreateNamespace, "create-namespace", os.Getenv("KAR_CREATE_NAMESPACE") == "true", "Create the namespace if missing")
+	pflag.StringVar(&opts.KubeContext, "context", "", "Kubeconfig context to use")
+	pflag.StringSliceVar(&opts.KubeconfigFiles, "kubeconfig-file", nil, "Kubeconfig file merged ahead of the default loading rules")
+	pflag.StringVar(&opts.ProxyURL, "proxy-url", "", "Proxy URL for the API server")
+	pflag.BoolVar(&opts.Quiet, "quiet", os.Getenv("KAR_QUIET") == "true", "Suppress routine progress logs")
+	pflag.StringVar(&opts.LogFile, "log-file", os.Getenv("KAR_LOG_FILE"), "Also write logs as NDJSON to this file")
+	pflag.StringVar(&opts.LogTimestampFormat, "log-timestamp-format", os.Getenv("KAR_LOG_TIMESTAMP_FORMAT"), "Timestamp format of console log lines")
+	pflag.BoolVar(&opts.NoBanner, "no-banner", os.Getenv("KAR_NO_BANNER") == "true", "Suppress the startup banner")
+	pflag.StringVar(&opts.RunnerNamespace, "runner-namespace", "", "Namespace of the runner pod, instance and secret")
+	pflag.BoolVarP(&showVersion, "version", "v", false, "Print the build info and exit")
+	pflag.BoolVar(&opts.DetachOnSignal, "detach-on-signal", os.Getenv("KAR_DETACH_ON_SIGNAL") == "true", "Leave a healthy instance running when stopped by a signal")
+	pflag.BoolVar(&opts.Simulate, "simulate", os.Getenv("KAR_SIMULATE") == "true", "Run against in-memory fakes instead of a cluster")
+	// Remaining flags are owned by the root command
+	pflag.CommandLine.ParseErrorsWhitelist.UnknownFlags = true
+	pflag.Parse()
+
+	buildInfo := getBuildInfo()
+	opts.Version = buildInfo.String()
+
+	// Before any kubeconfig resolution, so it works without cluster access
+	if showVersion {
+		fmt.Printf("kro-actions-runner %s\n", opts.Version)
+		return 0
+	}
+
+	// Offline subcommands such as scaffold-rgd run before any kubeconfig resolution too
+	if app.IsOfflineCommand(os.Args[1:]) {
+		if err := app.NewRootCommand(context.Background(), nil, opts).Execute(); err != nil {
+			return 1
 		}
-	}()
+		return 0
+	}
+
+	runner.SetQuiet(opts.Quiet)
+
+	// Before the log file, which keeps its own timestamps
+	if err := runner.SetLogTimestampFormat(opts.LogTimestampFormat); err != nil {
+		log.Fatalf("%v\n", err)
+	}
+
+	if opts.LogFile != "" {
+		closeLog, err := runner.SetLogFile(opts.LogFile)
+		if err != nil {
+			log.Fatalf("%v\n", err)
+		}
+		defer func() {
+			if err := closeLog(); err != nil {
+				log.Println("closing log file failed:", err)
+			}
+		}()
+	}
+
+	logBanner(buildInfo, opts.NoBanner)
+
+	opts.ScaleSetName, err = app.ResolveScaleSetName(opts.ScaleSetName, opts.ScaleSetNameFile)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	var r *runner.KRORunner
+	if opts.Simulate {
+		r = newSimulatedRunner(opts)
+	} else {
+		r, err = newClusterRunner(opts)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+	}
+
+	runner.Infof("cleanup timeout is set to: %s", getCleanupTimeout())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	rootCmd := app.NewRootCommand(ctx, r, opts)
 
-	if err := rootCmd.Execute(); err != nil && !errors.Is(errors.Cause(err), context.Canceled) {
+	err = execute(ctx, rootCmd, r, opts.DetachOnSignal)
+	if err != nil && !errors.Is(errors.Cause(err), context.Canceled) {
 		log.Println("execute command failed:", err)
 	}
+
+	// With --propagate-exit-code the orchestrator exits with the runner container's exit code
+	var exitErr *app.ExitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return 0
 }
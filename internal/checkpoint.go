@@ -0,0 +1,142 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// checkpointInterval is how often the last observed instance is checkpointed while waiting with --resume
+var checkpointInterval = 10 * time.Second
+
+// ConfigMap data keys of a watch checkpoint
+const (
+	checkpointUIDKey             = "uid"
+	checkpointResourceVersionKey = "resourceVersion"
+	checkpointStateKey           = "state"
+)
+
+// watchCheckpoint is the last instance observation a restarted orchestrator resumes the watch from
+type watchCheckpoint struct {
+	UID             types.UID
+	ResourceVersion string
+	State           string
+}
+
+// newWatchCheckpoint records an observed instance
+func newWatchCheckpoint(rg *unstructured.Unstructured) watchCheckpoint {
+	state, _, _ := unstructured.NestedString(rg.Object, "status", "state")
+	return watchCheckpoint{
+		UID:             rg.GetUID(),
+		ResourceVersion: rg.GetResourceVersion(),
+		State:           state,
+	}
+}
+
+// checkpointName is the ConfigMap holding the runner's watch checkpoint; unlike the orchestrator pod,
+// it survives an eviction
+func checkpointName(runnerName string) string {
+	return runnerName + "-kar-checkpoint"
+}
+
+// writeCheckpoint stores checkpoint in the runner's checkpoint ConfigMap, creating it if needed
+func (r *KRORunner) writeCheckpoint(ctx context.Context, runnerName string, checkpoint watchCheckpoint) error {
+	configMaps := r.kubeClient.CoreV1().ConfigMaps(r.namespace)
+	data := map[string]string{
+		checkpointUIDKey:             string(checkpoint.UID),
+		checkpointResourceVersionKey: checkpoint.ResourceVersion,
+		checkpointStateKey:           checkpoint.State,
+	}
+
+	_, err := configMaps.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: checkpointName(runnerName),
+			Labels: map[string]string{
+				rgdLabelKey:        r.scaleSetName,
+				runnerNameLabelKey: runnerName,
+			},
+		},
+		Data: data,
+	}, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		var existing *corev1.ConfigMap
+		existing, err = configMaps.Get(ctx, checkpointName(runnerName), metav1.GetOptions{})
+		if err == nil {
+			existing.Data = data
+			_, err = configMaps.Update(ctx, existing, metav1.UpdateOptions{})
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to write watch checkpoint %s", checkpointName(runnerName))
+	}
+	return nil
+}
+
+// deleteCheckpoint removes the runner's watch checkpoint, if any
+func (r *KRORunner) deleteCheckpoint(ctx context.Context, runnerName string) {
+	err := r.kubeClient.CoreV1().ConfigMaps(r.namespace).Delete(ctx, checkpointName(runnerName), metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		log.Printf("Failed to delete watch checkpoint %s: %v", checkpointName(runnerName), err)
+	}
+}
+
+// readCheckpoint returns the runner's watch checkpoint, or nil when there is none
+func (r *KRORunner) readCheckpoint(ctx context.Context, runnerName string) (*watchCheckpoint, error) {
+	configMap, err := r.kubeClient.CoreV1().ConfigMaps(r.namespace).Get(ctx, checkpointName(runnerName), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read watch checkpoint %s", checkpointName(runnerName))
+	}
+
+	return &watchCheckpoint{
+		UID:             types.UID(configMap.Data[checkpointUIDKey]),
+		ResourceVersion: configMap.Data[checkpointResourceVersionKey],
+		State:           configMap.Data[checkpointStateKey],
+	}, nil
+}
+
+// resumeResourceVersion returns the checkpointed resourceVersion to start the watch from, or "" to start
+// from the current state; a checkpoint of a different instance with the same name is ignored
+func (r *KRORunner) resumeResourceVersion(ctx context.Context, runnerName string) string {
+	if r.resumedUID == "" {
+		return ""
+	}
+
+	checkpoint, err := r.readCheckpoint(ctx, runnerName)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return ""
+	}
+	if checkpoint == nil || checkpoint.UID != r.resumedUID {
+		return ""
+	}
+
+	Infof("Resuming watch of ResourceGraph %s from checkpoint: resourceVersion=%s, state=%s",
+		runnerName, checkpoint.ResourceVersion, checkpoint.State)
+	return checkpoint.ResourceVersion
+}
@@ -0,0 +1,145 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestCheckpointRoundTrip tests writing, overwriting and reading a watch checkpoint
+func TestCheckpointRoundTrip(t *testing.T) {
+	r := NewKRORunner("default", newFakeDynamicClient(), kubefake.NewClientset(), "test-scale-set")
+
+	checkpoint, err := r.readCheckpoint(context.TODO(), "test-runner")
+	if err != nil || checkpoint != nil {
+		t.Fatalf("readCheckpoint() = %v, %v, want no checkpoint", checkpoint, err)
+	}
+
+	for _, want := range []watchCheckpoint{
+		{UID: "uid-1", ResourceVersion: "41", State: "IN_PROGRESS"},
+		{UID: "uid-1", ResourceVersion: "42", State: "ACTIVE"},
+	} {
+		if err := r.writeCheckpoint(context.TODO(), "test-runner", want); err != nil {
+			t.Fatalf("writeCheckpoint() error = %v", err)
+		}
+		got, err := r.readCheckpoint(context.TODO(), "test-runner")
+		if err != nil {
+			t.Fatalf("readCheckpoint() error = %v", err)
+		}
+		if got == nil || *got != want {
+			t.Errorf("readCheckpoint() = %+v, want %+v", got, want)
+		}
+	}
+
+	configMap, err := r.kubeClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "test-runner-kar-checkpoint", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configMap.Labels[runnerNameLabelKey] != "test-runner" || configMap.Labels[rgdLabelKey] != "test-scale-set" {
+		t.Errorf("checkpoint labels = %v", configMap.Labels)
+	}
+}
+
+// TestWaitForResourceGraphResumeCheckpoint tests that a resumed wait starts the watch from a matching checkpoint
+func TestWaitForResourceGraphResumeCheckpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		resumedUID types.UID
+		expected   string
+	}{
+		{name: "Matching instance", resumedUID: "uid-1", expected: "42"},
+		{name: "Recreated instance", resumedUID: "uid-2", expected: ""},
+		{name: "Not resumed", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, watcher, dynamicClient := newWatchedRunner(t, WithResume(true))
+			r.resumedUID = tt.resumedUID
+			if err := r.writeCheckpoint(context.TODO(), "test-runner",
+				watchCheckpoint{UID: "uid-1", ResourceVersion: "42", State: "ACTIVE"}); err != nil {
+				t.Fatal(err)
+			}
+
+			watcher.Modify(withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", true, "Succeeded"))
+			if err := r.WaitForResourceGraph(context.TODO()); err != nil {
+				t.Fatalf("WaitForResourceGraph() error = %v", err)
+			}
+
+			for _, action := range dynamicClient.Actions() {
+				if watchAction, ok := action.(k8stesting.WatchAction); ok {
+					if got := watchAction.GetWatchRestrictions().ResourceVersion; got != tt.expected {
+						t.Errorf("watch resourceVersion = %q, want %q", got, tt.expected)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphWritesCheckpoint tests that a resumable wait checkpoints observations and cleanup removes them
+func TestWaitForResourceGraphWritesCheckpoint(t *testing.T) {
+	original := checkpointInterval
+	checkpointInterval = 5 * time.Millisecond
+	t.Cleanup(func() { checkpointInterval = original })
+
+	r, watcher, _ := newWatchedRunner(t, WithResume(true))
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+	instance.SetUID("uid-1")
+
+	running := withStatus(instance, "ACTIVE", false, "Running")
+	running.SetResourceVersion("7")
+	watcher.Modify(running)
+
+	done := make(chan error, 1)
+	go func() { done <- r.WaitForResourceGraph(context.TODO()) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		checkpoint, err := r.readCheckpoint(context.TODO(), "test-runner")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := watchCheckpoint{UID: "uid-1", ResourceVersion: "7", State: "ACTIVE"}
+		if checkpoint != nil && *checkpoint == want {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("checkpoint = %+v, want %+v", checkpoint, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	watcher.Modify(withStatus(instance, "ACTIVE", true, "Succeeded"))
+	if err := <-done; err != nil {
+		t.Fatalf("WaitForResourceGraph() error = %v", err)
+	}
+
+	if err := r.DeleteResources(context.TODO()); err != nil {
+		t.Fatalf("DeleteResources() error = %v", err)
+	}
+	if checkpoint, err := r.readCheckpoint(context.TODO(), "test-runner"); err != nil || checkpoint != nil {
+		t.Errorf("readCheckpoint() after cleanup = %+v, %v, want none", checkpoint, err)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// DefaultCleanupConcurrency is the number of expired instances deleted in parallel
+const DefaultCleanupConcurrency = 5
+
+// CleanupExpired deletes the scale set's instances whose expires-at annotation is before now
+// and returns the names of the deleted instances. Instances without the annotation are kept.
+// Deletes run in parallel up to the cleanup concurrency and their errors are aggregated.
+func (r *KRORunner) CleanupExpired(ctx context.Context, now time.Time) ([]string, error) {
+	rgdInfo, err := r.findRGDByLabel(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to discover RGD for cleanup")
+	}
+
+	rgGVR := r.kroGVR(r.resourceName(rgdInfo.Kind))
+
+	instances, err := r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{rgdLabelKey: r.scaleSetName}).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list ResourceGraph instances")
+	}
+
+	names := make(chan string)
+	go func() {
+		defer close(names)
+		for i := range instances.Items {
+			if !isExpired(&instances.Items[i], now) {
+				continue
+			}
+			select {
+			case names <- instances.Items[i].GetName():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	concurrency := r.cleanupConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		deleted []string
+		errs    []error
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range names {
+				err := retryTransient(ctx, "Deleting expired ResourceGraph instance "+name, func() error {
+					return r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Delete(ctx, name, metav1.DeleteOptions{
+						GracePeriodSeconds: r.deleteGracePeriod,
+					})
+				})
+
+				mu.Lock()
+				if err != nil && !k8serrors.IsNotFound(err) {
+					errs = append(errs, errors.Wrapf(err, "failed to delete expired ResourceGraph instance %s", name))
+				} else {
+					Infof("Deleted expired ResourceGraph instance: %s", name)
+					deleted = append(deleted, name)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Strings(deleted)
+	if ctx.Err() != nil {
+		errs = append(errs, errors.Wrap(ctx.Err(), "cleanup interrupted"))
+	}
+	return deleted, utilerrors.NewAggregate(errs)
+}
+
+// isExpired reports whether an instance's expires-at annotation is before now
+func isExpired(instance *unstructured.Unstructured, now time.Time) bool {
+	value, ok := instance.GetAnnotations()[expiresAtAnnotation]
+	if !ok {
+		return false
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Printf("Warning: ignoring invalid %s=%q on %s", expiresAtAnnotation, value, instance.GetName())
+		return false
+	}
+
+	return expiresAt.Before(now)
+}
@@ -0,0 +1,251 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// withExpiresAt sets the expires-at annotation on an instance
+func withExpiresAt(instance *unstructured.Unstructured, value string) *unstructured.Unstructured {
+	instance = instance.DeepCopy()
+	annotations := instance.GetAnnotations()
+	annotations[expiresAtAnnotation] = value
+	instance.SetAnnotations(annotations)
+	return instance
+}
+
+// TestCleanupExpired tests that cleanup deletes only instances past their expires-at annotation
+func TestCleanupExpired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute).Format(time.RFC3339)
+	future := now.Add(time.Minute).Format(time.RFC3339)
+
+	dynamicClient := newFakeDynamicClient(
+		newTestRGD("pod-runner", "test-scale-set", "PodRunner"),
+		withExpiresAt(newTestInstance("default", "expired", "test-scale-set"), past),
+		withExpiresAt(newTestInstance("default", "not-expired", "test-scale-set"), future),
+		withExpiresAt(newTestInstance("default", "invalid", "test-scale-set"), "tomorrow"),
+		withExpiresAt(newTestInstance("default", "other-scale-set", "other-scale-set"), past),
+		newTestInstance("default", "no-expiry", "test-scale-set"),
+	)
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set")
+
+	deleted, err := r.CleanupExpired(context.TODO(), now)
+	if err != nil {
+		t.Fatalf("CleanupExpired() error = %v", err)
+	}
+	if !reflect.DeepEqual(deleted, []string{"expired"}) {
+		t.Errorf("CleanupExpired() deleted = %v, want [expired]", deleted)
+	}
+
+	remaining, err := dynamicClient.Resource(testRGGVR).Namespace("default").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list instances: %v", err)
+	}
+	var names []string
+	for _, item := range remaining.Items {
+		names = append(names, item.GetName())
+	}
+	sort.Strings(names)
+	want := []string{"invalid", "no-expiry", "not-expired", "other-scale-set"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("remaining instances = %v, want %v", names, want)
+	}
+}
+
+// concurrencyTrackingClient records the peak number of in-flight deletes, which the fake
+// dynamic client would serialize, and fails deletes of the named instances
+type concurrencyTrackingClient struct {
+	dynamic.Interface
+	fail     map[string]bool
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (c *concurrencyTrackingClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &concurrencyTrackingNamespaceable{NamespaceableResourceInterface: c.Interface.Resource(gvr), client: c}
+}
+
+type concurrencyTrackingNamespaceable struct {
+	dynamic.NamespaceableResourceInterface
+	client *concurrencyTrackingClient
+}
+
+func (n *concurrencyTrackingNamespaceable) Namespace(namespace string) dynamic.ResourceInterface {
+	return &concurrencyTrackingResource{ResourceInterface: n.NamespaceableResourceInterface.Namespace(namespace), client: n.client}
+}
+
+type concurrencyTrackingResource struct {
+	dynamic.ResourceInterface
+	client *concurrencyTrackingClient
+}
+
+func (r *concurrencyTrackingResource) Delete(ctx context.Context, name string, opts metav1.DeleteOptions, subresources ...string) error {
+	current := r.client.inFlight.Add(1)
+	defer r.client.inFlight.Add(-1)
+	for {
+		peak := r.client.peak.Load()
+		if current <= peak || r.client.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	// Hold the slot long enough for other workers to overlap
+	time.Sleep(20 * time.Millisecond)
+
+	if r.client.fail[name] {
+		return k8serrors.NewForbidden(schema.GroupResource{Resource: "podrunners"}, name, errors.New("denied"))
+	}
+	return r.ResourceInterface.Delete(ctx, name, opts, subresources...)
+}
+
+// TestCleanupExpiredConcurrency tests that deletes run in parallel within the concurrency limit
+// and that failures are aggregated without stopping the other deletes
+func TestCleanupExpiredConcurrency(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute).Format(time.RFC3339)
+
+	objects := []runtime.Object{newTestRGD("pod-runner", "test-scale-set", "PodRunner")}
+	var want []string
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("expired-%d", i)
+		objects = append(objects, withExpiresAt(newTestInstance("default", name, "test-scale-set"), past))
+		if name != "expired-3" && name != "expired-5" {
+			want = append(want, name)
+		}
+	}
+
+	dynamicClient := &concurrencyTrackingClient{
+		Interface: newFakeDynamicClient(objects...),
+		fail:      map[string]bool{"expired-3": true, "expired-5": true},
+	}
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set", WithCleanupConcurrency(3))
+
+	deleted, err := r.CleanupExpired(context.TODO(), now)
+	if err == nil {
+		t.Fatal("CleanupExpired() error = nil, want aggregated delete failures")
+	}
+	for _, name := range []string{"expired-3", "expired-5"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("CleanupExpired() error = %v, want it to name %s", err, name)
+		}
+	}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("CleanupExpired() deleted = %v, want %v", deleted, want)
+	}
+
+	if peak := dynamicClient.peak.Load(); peak < 2 || peak > 3 {
+		t.Errorf("peak concurrent deletes = %d, want between 2 and 3", peak)
+	}
+}
+
+// TestCreateResourcesExpiresAt tests the expires-at annotation computed from the max runtime
+func TestCreateResourcesExpiresAt(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRuntime time.Duration
+	}{
+		{name: "Max runtime set", maxRuntime: time.Hour},
+		{name: "Max runtime unset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")),
+				"test-scale-set", WithMaxRuntime(tt.maxRuntime))
+
+			before := time.Now().Truncate(time.Second)
+			if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+			after := time.Now()
+
+			instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+				context.TODO(), "test-runner", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("instance was not created: %v", err)
+			}
+
+			value, ok := instance.GetAnnotations()[expiresAtAnnotation]
+			if tt.maxRuntime == 0 {
+				if ok {
+					t.Errorf("annotation %s = %q, want absent", expiresAtAnnotation, value)
+				}
+				return
+			}
+
+			expiresAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				t.Fatalf("annotation %s = %q is not RFC3339: %v", expiresAtAnnotation, value, err)
+			}
+			if expiresAt.Before(before.Add(tt.maxRuntime)) || expiresAt.After(after.Add(tt.maxRuntime)) {
+				t.Errorf("annotation %s = %s, want between %s and %s", expiresAtAnnotation, expiresAt,
+					before.Add(tt.maxRuntime), after.Add(tt.maxRuntime))
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphMaxRuntime tests that the wait gives up once the max runtime elapses
+func TestWaitForResourceGraphMaxRuntime(t *testing.T) {
+	r, _, _ := newWatchedRunner(t, WithMaxRuntime(50*time.Millisecond))
+
+	err := r.WaitForResourceGraph(context.Background())
+	if err != context.DeadlineExceeded {
+		t.Errorf("WaitForResourceGraph() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// TestWaitWithInformerMaxRuntime tests that the informer wait is bounded by the max runtime too
+func TestWaitWithInformerMaxRuntime(t *testing.T) {
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"), instance)
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set",
+		WithInformer(true), WithMaxRuntime(time.Second))
+
+	NewAppContext("test-runner", "")
+	t.Cleanup(func() { appContext = nil })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := r.WaitForResourceGraph(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForResourceGraph() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if ctx.Err() != nil {
+		t.Error("the wait ran until the test timeout instead of the max runtime")
+	}
+}
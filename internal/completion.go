@@ -0,0 +1,118 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"log"
+
+	"github.com/google/cel-go/cel"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CompletionExprs are CEL expressions over an instance's status that decide when the runner is done or failed,
+// replacing the built-in runner pod phase checks
+type CompletionExprs struct {
+	completion cel.Program
+	failure    cel.Program
+}
+
+// NewCompletionExprs compiles the completion and failure expressions. Either may be empty; nil is returned when both are.
+// Each must evaluate to a bool with the instance's status bound to the variable status.
+func NewCompletionExprs(completionExpr, failureExpr string) (*CompletionExprs, error) {
+	if completionExpr == "" && failureExpr == "" {
+		return nil, nil
+	}
+
+	env, err := cel.NewEnv(cel.Variable("status", cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create CEL environment")
+	}
+
+	exprs := &CompletionExprs{}
+	if exprs.completion, err = compileBoolExpr(env, completionExpr); err != nil {
+		return nil, errors.Wrap(err, "invalid completion expression")
+	}
+	if exprs.failure, err = compileBoolExpr(env, failureExpr); err != nil {
+		return nil, errors.Wrap(err, "invalid failure expression")
+	}
+
+	return exprs, nil
+}
+
+// compileBoolExpr compiles expr into a program that must return a bool, or nil for an empty expr
+func compileBoolExpr(env *cel.Env, expr string) (cel.Program, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, errors.Errorf("%q returns %s, want bool", expr, ast.OutputType())
+	}
+
+	return env.Program(ast)
+}
+
+// hasCompletion reports whether a completion expression replaces the built-in completion check
+func (c *CompletionExprs) hasCompletion() bool {
+	return c != nil && c.completion != nil
+}
+
+// outcome reports whether the runner is done and whether it failed. The failure expression is
+// checked first. An expression that cannot be evaluated yet, e.g. because the status lacks
+// a field it references, counts as false.
+func (c *CompletionExprs) outcome(runnerName string, rg *unstructured.Unstructured) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+
+	status, _, _ := unstructured.NestedMap(rg.Object, "status")
+	if status == nil {
+		status = map[string]interface{}{}
+	}
+
+	if evalBool(runnerName, "failure", c.failure, status) {
+		log.Printf("ResourceGraph %s matched the failure expression", runnerName)
+		return true, ErrRunnerFailed
+	}
+	if evalBool(runnerName, "completion", c.completion, status) {
+		Infof("ResourceGraph %s matched the completion expression - runner completed", runnerName)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// evalBool evaluates program against status, treating a missing program, an error or a non-bool result as false
+func evalBool(runnerName, name string, program cel.Program, status map[string]interface{}) bool {
+	if program == nil {
+		return false
+	}
+
+	val, _, err := program.Eval(map[string]interface{}{"status": status})
+	if err != nil {
+		Infof("ResourceGraph %s %s expression not yet satisfiable: %v", runnerName, name, err)
+		return false
+	}
+
+	result, ok := val.Value().(bool)
+	return ok && result
+}
@@ -0,0 +1,132 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestNewCompletionExprs tests compiling completion and failure expressions
+func TestNewCompletionExprs(t *testing.T) {
+	tests := []struct {
+		name       string
+		completion string
+		failure    string
+		expectNil  bool
+		expectErr  bool
+	}{
+		{name: "No expressions", expectNil: true},
+		{name: "Completion only", completion: "status.phase == 'Done'"},
+		{name: "Failure only", failure: "status.phase == 'Error'"},
+		{name: "Syntax error", completion: "status.phase ==", expectErr: true},
+		{name: "Non-bool result", failure: "'Error'", expectErr: true},
+		{name: "Unknown variable", completion: "spec.phase == 'Done'", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exprs, err := NewCompletionExprs(tt.completion, tt.failure)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("NewCompletionExprs() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if !tt.expectErr && (exprs == nil) != tt.expectNil {
+				t.Errorf("NewCompletionExprs() = %v, expectNil %v", exprs, tt.expectNil)
+			}
+		})
+	}
+}
+
+// TestCompletionExprsOutcome tests evaluating expressions against sample status objects
+func TestCompletionExprsOutcome(t *testing.T) {
+	exprs, err := NewCompletionExprs(
+		"status.state == 'ACTIVE' && status.vm.phase == 'Stopped'",
+		"has(status.vm.error) || status.vm.restarts > 3",
+	)
+	if err != nil {
+		t.Fatalf("NewCompletionExprs() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		status      map[string]interface{}
+		expectDone  bool
+		expectedErr error
+	}{
+		{name: "No status"},
+		{name: "Missing field", status: map[string]interface{}{"state": "ACTIVE"}},
+		{
+			name:   "Running",
+			status: map[string]interface{}{"state": "ACTIVE", "vm": map[string]interface{}{"phase": "Running", "restarts": int64(1)}},
+		},
+		{
+			name:       "Completed",
+			status:     map[string]interface{}{"state": "ACTIVE", "vm": map[string]interface{}{"phase": "Stopped", "restarts": int64(0)}},
+			expectDone: true,
+		},
+		{
+			name:        "Failed by error field",
+			status:      map[string]interface{}{"state": "ACTIVE", "vm": map[string]interface{}{"phase": "Stopped", "error": "disk full"}},
+			expectDone:  true,
+			expectedErr: ErrRunnerFailed,
+		},
+		{
+			name:        "Failed by restarts",
+			status:      map[string]interface{}{"state": "ACTIVE", "vm": map[string]interface{}{"phase": "Running", "restarts": int64(4)}},
+			expectDone:  true,
+			expectedErr: ErrRunnerFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rg := newTestInstance("default", "test-runner", "test-scale-set")
+			if tt.status != nil {
+				_ = unstructured.SetNestedMap(rg.Object, tt.status, "status")
+			}
+
+			done, err := exprs.outcome("test-runner", rg)
+			if done != tt.expectDone || !errors.Is(err, tt.expectedErr) {
+				t.Errorf("outcome() = (%v, %v), want (%v, %v)", done, err, tt.expectDone, tt.expectedErr)
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphCompletionExpr tests that a completion expression replaces the runner pod phase check
+func TestWaitForResourceGraphCompletionExpr(t *testing.T) {
+	exprs, err := NewCompletionExprs("status.phase == 'Done'", "")
+	if err != nil {
+		t.Fatalf("NewCompletionExprs() error = %v", err)
+	}
+	r, watcher, _ := newWatchedRunner(t, WithCompletionExprs(exprs))
+
+	// A failed runner pod no longer decides the outcome
+	podFailed := withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", true, "Failed")
+	done := podFailed.DeepCopy()
+	_ = unstructured.SetNestedField(done.Object, "Done", "status", "phase")
+
+	watcher.Modify(podFailed)
+	watcher.Modify(done)
+
+	if err := r.WaitForResourceGraph(context.TODO()); err != nil {
+		t.Fatalf("WaitForResourceGraph() error = %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Diagnostic check statuses
+const (
+	CheckOK   = "ok"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+)
+
+// DiagnosticCheck is the result of a single read-only diagnostic check
+type DiagnosticCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// PreflightReport summarizes the diagnostic checks as a single health snapshot
+type PreflightReport struct {
+	// Status is the worst status of any check
+	Status string            `json:"status"`
+	Checks []DiagnosticCheck `json:"checks"`
+}
+
+// NewPreflightReport builds a report whose status is the worst status among checks
+func NewPreflightReport(checks []DiagnosticCheck) PreflightReport {
+	report := PreflightReport{Status: CheckOK, Checks: checks}
+	for _, check := range checks {
+		switch check.Status {
+		case CheckFail:
+			report.Status = CheckFail
+		case CheckWarn:
+			if report.Status == CheckOK {
+				report.Status = CheckWarn
+			}
+		}
+	}
+	return report
+}
+
+// Failed returns the number of failed checks
+func (p PreflightReport) Failed() int {
+	failed := 0
+	for _, check := range p.Checks {
+		if check.Status == CheckFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Preflight runs the diagnostic checks and returns them as a report
+func (r *KRORunner) Preflight(ctx context.Context) PreflightReport {
+	return NewPreflightReport(r.Diagnose(ctx))
+}
+
+// Diagnose runs read-only checks covering the common reasons a runner fails to start
+func (r *KRORunner) Diagnose(ctx context.Context) []DiagnosticCheck {
+	checks := []DiagnosticCheck{
+		r.checkKubeconfig(),
+		r.checkNamespace(ctx),
+		r.checkKROAPI(),
+	}
+
+	rgdCheck, rgdInfo := r.checkRGDs(ctx)
+	checks = append(checks, rgdCheck, r.checkInstanceAPI(rgdInfo))
+	checks = append(checks, r.checkRBAC(ctx, rgdInfo)...)
+
+	return append(checks, r.checkQuotaRoom(ctx, rgdInfo))
+}
+
+func (r *KRORunner) checkKubeconfig() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "kubeconfig", Status: CheckOK}
+	if r.kubeContext == "" {
+		check.Message = fmt.Sprintf("no kubeconfig context (in-cluster config), server %s", r.kubeServer)
+	} else {
+		check.Message = fmt.Sprintf("context %s, server %s", r.kubeContext, r.kubeServer)
+	}
+
+	return check
+}
+
+func (r *KRORunner) checkNamespace(ctx context.Context) DiagnosticCheck {
+	check := DiagnosticCheck{Name: "namespace"}
+
+	ns, err := r.kubeClient.CoreV1().Namespaces().Get(ctx, r.namespace, metav1.GetOptions{})
+	switch {
+	case err == nil && ns.Status.Phase == corev1.NamespaceTerminating:
+		check.Status, check.Message = CheckFail, fmt.Sprintf("namespace %s is terminating", r.namespace)
+	case err == nil:
+		check.Status, check.Message = CheckOK, fmt.Sprintf("namespace %s exists", r.namespace)
+	case k8serrors.IsForbidden(err):
+		check.Status, check.Message = CheckWarn, fmt.Sprintf("namespace %s cannot be read: %v", r.namespace, err)
+	case k8serrors.IsNotFound(err):
+		check.Status, check.Message = CheckFail, fmt.Sprintf("namespace %s does not exist", r.namespace)
+	default:
+		check.Status, check.Message = CheckFail, fmt.Sprintf("failed to get namespace %s: %v", r.namespace, err)
+	}
+
+	return check
+}
+
+func (r *KRORunner) checkKROAPI() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "kro-api"}
+	groupVersion := kroGroup + "/" + r.kroVersion()
+
+	resources, err := r.kubeClient.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		check.Status, check.Message = CheckFail, fmt.Sprintf("%s is not registered: %v", groupVersion, err)
+		return check
+	}
+
+	for _, resource := range resources.APIResources {
+		if resource.Name == "resourcegraphdefinitions" {
+			check.Status, check.Message = CheckOK, fmt.Sprintf("%s is registered", groupVersion)
+			return check
+		}
+	}
+
+	check.Status, check.Message = CheckFail, fmt.Sprintf("%s does not serve resourcegraphdefinitions", groupVersion)
+	return check
+}
+
+func (r *KRORunner) checkRGDs(ctx context.Context) (DiagnosticCheck, *RGDInfo) {
+	check := DiagnosticCheck{Name: "rgd-discovery"}
+	selector := r.rgdSelector()
+
+	rgdGVR := r.kroGVR("resourcegraphdefinitions")
+
+	rgdList, err := r.dynamicClient.Resource(rgdGVR).Namespace(r.scaleSetNamespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		check.Status, check.Message = CheckFail, fmt.Sprintf("failed to list RGDs: %v", err)
+		return check, nil
+	}
+
+	switch len(rgdList.Items) {
+	case 0:
+		check.Status, check.Message = CheckFail, fmt.Sprintf("no RGD matches %s", selector)
+		return check, nil
+	case 1:
+	default:
+		names := make([]string, 0, len(rgdList.Items))
+		for _, rgd := range rgdList.Items {
+			names = append(names, rgd.GetName())
+		}
+		check.Status = CheckFail
+		check.Message = fmt.Sprintf("%d RGDs match %s: %s", len(names), selector, strings.Join(names, ", "))
+		return check, nil
+	}
+
+	// Reuse discovery to validate the single match the same way a run would
+	rgdInfo, err := r.findRGDByLabel(ctx)
+	if err != nil {
+		check.Status, check.Message = CheckFail, err.Error()
+		return check, nil
+	}
+
+	check.Status = CheckOK
+	check.Message = fmt.Sprintf("1 RGD matches %s: %s (kind %s)", selector, rgdInfo.Name, rgdInfo.Kind)
+	return check, rgdInfo
+}
+
+// checkInstanceAPI verifies the CRD KRO generates for the RGD is registered
+func (r *KRORunner) checkInstanceAPI(rgdInfo *RGDInfo) DiagnosticCheck {
+	check := DiagnosticCheck{Name: "instance-api"}
+	if rgdInfo == nil {
+		check.Status, check.Message = CheckWarn, "skipped, no RGD discovered to derive the instance resource"
+		return check
+	}
+
+	groupVersion := kroGroup + "/" + r.kroVersion()
+	resource := r.resourceName(rgdInfo.Kind)
+
+	resources, err := r.kubeClient.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		check.Status, check.Message = CheckFail, fmt.Sprintf("%s is not registered: %v", groupVersion, err)
+		return check
+	}
+	for _, apiResource := range resources.APIResources {
+		if apiResource.Name == resource {
+			check.Status, check.Message = CheckOK, fmt.Sprintf("%s serves %s (kind %s)", groupVersion, resource, rgdInfo.Kind)
+			return check
+		}
+	}
+
+	check.Status, check.Message = CheckFail, fmt.Sprintf("%s does not serve %s, is the RGD's CRD registered?", groupVersion, resource)
+	return check
+}
+
+// checkQuotaRoom reports whether the namespace's ResourceQuotas have room for one more runner
+func (r *KRORunner) checkQuotaRoom(ctx context.Context, rgdInfo *RGDInfo) DiagnosticCheck {
+	check := DiagnosticCheck{Name: "quota"}
+	if rgdInfo == nil {
+		check.Status, check.Message = CheckWarn, "skipped, no RGD discovered to derive the expected resources"
+		return check
+	}
+
+	err := r.checkQuota(ctx, rgdInfo)
+	switch {
+	case err == nil:
+		check.Status, check.Message = CheckOK, fmt.Sprintf("resource quotas in namespace %s have room for a runner", r.namespace)
+	case k8serrors.IsForbidden(err):
+		check.Status, check.Message = CheckWarn, fmt.Sprintf("resource quotas cannot be read: %v", err)
+	default:
+		check.Status, check.Message = CheckFail, err.Error()
+	}
+
+	return check
+}
+
+func (r *KRORunner) checkRBAC(ctx context.Context, rgdInfo *RGDInfo) []DiagnosticCheck {
+	if rgdInfo == nil {
+		return []DiagnosticCheck{{
+			Name:    "rbac",
+			Status:  CheckWarn,
+			Message: "skipped, no RGD discovered to derive the instance resource",
+		}}
+	}
+
+	resource := r.resourceName(rgdInfo.Kind)
+	checks := make([]DiagnosticCheck, 0, 4)
+	for _, verb := range []string{"create", "get", "watch", "delete"} {
+		check := DiagnosticCheck{Name: fmt.Sprintf("rbac:%s:%s", verb, resource)}
+
+		review, err := r.kubeClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: r.namespace,
+					Verb:      verb,
+					Group:     kroGroup,
+					Resource:  resource,
+				},
+			},
+		}, metav1.CreateOptions{})
+
+		switch {
+		case err != nil:
+			check.Status, check.Message = CheckWarn, fmt.Sprintf("access review failed: %v", err)
+		case review.Status.Allowed:
+			check.Status, check.Message = CheckOK, fmt.Sprintf("allowed in namespace %s", r.namespace)
+		default:
+			check.Status, check.Message = CheckFail, fmt.Sprintf("denied in namespace %s %s", r.namespace, review.Status.Reason)
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
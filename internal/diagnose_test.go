@@ -0,0 +1,205 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newDiagnosableKubeClient returns a fake clientset serving the KRO API that allows the given verbs
+func newDiagnosableKubeClient(allowedVerbs ...string) *kubefake.Clientset {
+	kubeClient := kubefake.NewClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	kubeClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "kro.run/v1alpha1",
+		APIResources: []metav1.APIResource{{Name: "resourcegraphdefinitions"}, {Name: "podrunners"}},
+	}}
+
+	allowed := map[string]bool{}
+	for _, verb := range allowedVerbs {
+		allowed[verb] = true
+	}
+	kubeClient.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = allowed[review.Spec.ResourceAttributes.Verb]
+		return true, review, nil
+	})
+
+	return kubeClient
+}
+
+// checkStatuses indexes diagnostic checks by name
+func checkStatuses(checks []DiagnosticCheck) map[string]string {
+	statuses := map[string]string{}
+	for _, check := range checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+// TestDiagnoseHealthy tests that a correctly configured cluster passes every check
+func TestDiagnoseHealthy(t *testing.T) {
+	r := NewKRORunner("default",
+		newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner")),
+		newDiagnosableKubeClient("create", "get", "watch", "delete"),
+		"test-scale-set", WithClusterInfo("kind-test", "https://127.0.0.1:6443"))
+
+	for _, check := range r.Diagnose(context.TODO()) {
+		if check.Status != CheckOK {
+			t.Errorf("check %s = %s (%s), want %s", check.Name, check.Status, check.Message, CheckOK)
+		}
+	}
+}
+
+// TestDiagnoseFailures tests that missing RGDs, namespaces and permissions are reported
+func TestDiagnoseFailures(t *testing.T) {
+	t.Run("No matching RGD skips RBAC", func(t *testing.T) {
+		r := NewKRORunner("default", newFakeDynamicClient(), newDiagnosableKubeClient(), "test-scale-set")
+		statuses := checkStatuses(r.Diagnose(context.TODO()))
+
+		if statuses["rgd-discovery"] != CheckFail {
+			t.Errorf("rgd-discovery = %s, want %s", statuses["rgd-discovery"], CheckFail)
+		}
+		if statuses["rbac"] != CheckWarn {
+			t.Errorf("rbac = %s, want %s", statuses["rbac"], CheckWarn)
+		}
+	})
+
+	t.Run("Ambiguous RGDs", func(t *testing.T) {
+		r := NewKRORunner("default", newFakeDynamicClient(
+			newTestRGD("pod-runner-a", "test-scale-set", "PodRunner"),
+			newTestRGD("pod-runner-b", "test-scale-set", "PodRunner"),
+		), newDiagnosableKubeClient(), "test-scale-set")
+
+		if status := checkStatuses(r.Diagnose(context.TODO()))["rgd-discovery"]; status != CheckFail {
+			t.Errorf("rgd-discovery = %s, want %s", status, CheckFail)
+		}
+	})
+
+	t.Run("Missing namespace, KRO API and permissions", func(t *testing.T) {
+		kubeClient := newDiagnosableKubeClient()
+		kubeClient.Resources = nil
+		r := NewKRORunner("runners", newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner")),
+			kubeClient, "test-scale-set")
+		statuses := checkStatuses(r.Diagnose(context.TODO()))
+
+		for _, name := range []string{"namespace", "kro-api", "instance-api", "rbac:create:podrunners", "rbac:delete:podrunners"} {
+			if statuses[name] != CheckFail {
+				t.Errorf("%s = %s, want %s", name, statuses[name], CheckFail)
+			}
+		}
+	})
+}
+
+// TestDiagnoseIsReadOnly tests that diagnostics never create, update or delete cluster resources
+func TestDiagnoseIsReadOnly(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	kubeClient := newDiagnosableKubeClient("get")
+
+	NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set").Diagnose(context.TODO())
+
+	for _, action := range dynamicClient.Actions() {
+		if verb := action.GetVerb(); verb != "list" && verb != "get" {
+			t.Errorf("unexpected dynamic client action %s", verb)
+		}
+	}
+	for _, action := range kubeClient.Actions() {
+		verb := action.GetVerb()
+		if verb == "create" && action.GetResource().Resource == "selfsubjectaccessreviews" {
+			continue
+		}
+		if verb != "get" && verb != "list" {
+			t.Errorf("unexpected kube client action %s %s", verb, action.GetResource().Resource)
+		}
+	}
+}
+
+// TestNewPreflightReport tests that the report status is the worst check status
+func TestNewPreflightReport(t *testing.T) {
+	tests := []struct {
+		name           string
+		statuses       []string
+		expectedStatus string
+		expectedFailed int
+	}{
+		{name: "No checks", expectedStatus: CheckOK},
+		{name: "All pass", statuses: []string{CheckOK, CheckOK}, expectedStatus: CheckOK},
+		{name: "Warning", statuses: []string{CheckOK, CheckWarn, CheckOK}, expectedStatus: CheckWarn},
+		{name: "Mixed pass and fail", statuses: []string{CheckOK, CheckFail, CheckWarn, CheckFail}, expectedStatus: CheckFail, expectedFailed: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checks []DiagnosticCheck
+			for i, status := range tt.statuses {
+				checks = append(checks, DiagnosticCheck{Name: fmt.Sprintf("check-%d", i), Status: status})
+			}
+
+			report := NewPreflightReport(checks)
+			if report.Status != tt.expectedStatus {
+				t.Errorf("Status = %s, want %s", report.Status, tt.expectedStatus)
+			}
+			if report.Failed() != tt.expectedFailed {
+				t.Errorf("Failed() = %d, want %d", report.Failed(), tt.expectedFailed)
+			}
+		})
+	}
+}
+
+// TestPreflight tests a report mixing passing checks with an unregistered CRD and a full quota
+func TestPreflight(t *testing.T) {
+	kubeClient := newDiagnosableKubeClient("create", "get", "watch", "delete")
+	kubeClient.Resources[0].APIResources = []metav1.APIResource{{Name: "resourcegraphdefinitions"}}
+	if _, err := kubeClient.CoreV1().ResourceQuotas("default").Create(context.TODO(), &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "runners"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")},
+			Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")},
+		},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewKRORunner("default", newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner")),
+		kubeClient, "test-scale-set", WithClusterInfo("kind-test", "https://127.0.0.1:6443"))
+	report := r.Preflight(context.TODO())
+
+	if report.Status != CheckFail || report.Failed() != 2 {
+		t.Errorf("report = %s with %d failures, want %s with 2", report.Status, report.Failed(), CheckFail)
+	}
+	statuses := checkStatuses(report.Checks)
+	for name, expected := range map[string]string{
+		"namespace":              CheckOK,
+		"rgd-discovery":          CheckOK,
+		"rbac:create:podrunners": CheckOK,
+		"instance-api":           CheckFail,
+		"quota":                  CheckFail,
+	} {
+		if statuses[name] != expected {
+			t.Errorf("%s = %s, want %s", name, statuses[name], expected)
+		}
+	}
+}
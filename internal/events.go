@@ -0,0 +1,117 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// DefaultFailureEvents is how many recent events are logged when a runner fails or times out
+const DefaultFailureEvents = 10
+
+// failureEventsTimeout bounds fetching events, which may happen after the run context expired
+const failureEventsTimeout = 10 * time.Second
+
+// logFailureEvents logs the most recent events of the runner's instance, and of related objects
+// when configured, so failures can be diagnosed from the orchestrator log alone
+func (r *KRORunner) logFailureEvents(ctx context.Context, runnerName string) {
+	if r.failureEvents <= 0 || r.kubeClient == nil || runnerName == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), failureEventsTimeout)
+	defer cancel()
+
+	events, err := r.recentEvents(ctx, runnerName)
+	if err != nil {
+		log.Printf("Warning: failed to fetch events for ResourceGraph %s: %v", runnerName, err)
+		return
+	}
+	if len(events) == 0 {
+		log.Printf("No events found for ResourceGraph %s", runnerName)
+		return
+	}
+
+	log.Printf("Most recent events for ResourceGraph %s:", runnerName)
+	for _, event := range events {
+		log.Printf("  %s", formatEvent(event))
+	}
+}
+
+// recentEvents returns up to failureEvents of the newest events involving the instance, oldest first.
+// Related objects are those named after the runner with a suffix, such as a <runner>-job pod.
+func (r *KRORunner) recentEvents(ctx context.Context, runnerName string) ([]corev1.Event, error) {
+	opts := metav1.ListOptions{}
+	if !r.failureEventsIncludeRelated {
+		opts.FieldSelector = fields.OneTermEqualSelector("involvedObject.name", runnerName).String()
+	}
+
+	list, err := r.kubeClient.CoreV1().Events(r.namespace).List(ctx, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list events")
+	}
+
+	var events []corev1.Event
+	for _, event := range list.Items {
+		name := event.InvolvedObject.Name
+		if name == runnerName || (r.failureEventsIncludeRelated && strings.HasPrefix(name, runnerName+"-")) {
+			events = append(events, event)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	if len(events) > r.failureEvents {
+		events = events[len(events)-r.failureEvents:]
+	}
+
+	return events, nil
+}
+
+// eventTime returns when an event last occurred, falling back through the fields older reporters set
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// formatEvent renders an event like kubectl's event listing: type, reason, object and message
+func formatEvent(event corev1.Event) string {
+	line := fmt.Sprintf("%s %s %s/%s: %s", event.Type, event.Reason,
+		event.InvolvedObject.Kind, event.InvolvedObject.Name, strings.TrimSpace(event.Message))
+	if event.Count > 1 {
+		line += fmt.Sprintf(" (x%d)", event.Count)
+	}
+	return line
+}
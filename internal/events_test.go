@@ -0,0 +1,126 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestEvent returns an event for the named object that last occurred at ts
+func newTestEvent(name, kind, objectName, reason string, ts time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: name},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Name: objectName, Namespace: "default"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " happened",
+		LastTimestamp:  metav1.NewTime(ts),
+	}
+}
+
+// TestRecentEvents tests selecting, ordering and limiting the instance's events
+func TestRecentEvents(t *testing.T) {
+	now := time.Now()
+	events := []runtime.Object{
+		newTestEvent("e1", "PodRunner", "test-runner", "Oldest", now.Add(-3*time.Minute)),
+		newTestEvent("e2", "PodRunner", "test-runner", "Newest", now),
+		newTestEvent("e3", "PodRunner", "test-runner", "Middle", now.Add(-2*time.Minute)),
+		newTestEvent("e4", "Pod", "test-runner-job", "BackOff", now.Add(-time.Minute)),
+		newTestEvent("e5", "PodRunner", "other-runner", "Unrelated", now),
+		newTestEvent("e6", "PodRunner", "test-runner2", "SimilarName", now),
+	}
+
+	tests := []struct {
+		name           string
+		limit          int
+		includeRelated bool
+		expected       []string
+	}{
+		{name: "Instance events oldest first", limit: 10, expected: []string{"Oldest", "Middle", "Newest"}},
+		{name: "Limited to the most recent", limit: 2, expected: []string{"Middle", "Newest"}},
+		{name: "Related objects", limit: 10, includeRelated: true, expected: []string{"Oldest", "Middle", "BackOff", "Newest"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewKRORunner("default", nil, kubefake.NewClientset(events...), "test-scale-set",
+				WithFailureEvents(tt.limit, tt.includeRelated))
+
+			got, err := r.recentEvents(context.TODO(), "test-runner")
+			if err != nil {
+				t.Fatalf("recentEvents() error = %v", err)
+			}
+
+			var reasons []string
+			for _, event := range got {
+				reasons = append(reasons, event.Reason)
+			}
+			if strings.Join(reasons, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("recentEvents() reasons = %v, want %v", reasons, tt.expected)
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphLogsFailureEvents tests that a failed runner logs its instance's events
+func TestWaitForResourceGraphLogsFailureEvents(t *testing.T) {
+	logs := captureLogs(t)
+	r, watcher, _ := newWatchedRunner(t)
+
+	event := newTestEvent("e1", "PodRunner", "test-runner", "FailedCreate", time.Now())
+	event.Message = "pods \"test-runner-job\" is forbidden: exceeded quota"
+	event.Count = 3
+	if _, err := r.kubeClient.CoreV1().Events("default").Create(context.TODO(), event, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher.Modify(withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "FAILED", false, ""))
+
+	if err := r.WaitForResourceGraph(context.TODO()); !errors.Is(err, ErrRunnerFailed) {
+		t.Fatalf("WaitForResourceGraph() error = %v, want %v", err, ErrRunnerFailed)
+	}
+
+	expected := `Warning FailedCreate PodRunner/test-runner: pods "test-runner-job" is forbidden: exceeded quota (x3)`
+	if !strings.Contains(logs.String(), expected) {
+		t.Errorf("logs missing %q:\n%s", expected, logs.String())
+	}
+}
+
+// TestWaitForResourceGraphCancelledSkipsEvents tests that deliberate cancellation does not log events
+func TestWaitForResourceGraphCancelledSkipsEvents(t *testing.T) {
+	logs := captureLogs(t)
+	r, _, _ := newWatchedRunner(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := r.WaitForResourceGraph(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("WaitForResourceGraph() error = %v, want %v", err, context.Canceled)
+	}
+	if strings.Contains(logs.String(), "events") {
+		t.Errorf("events logged on cancellation:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,282 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Resync period for the shared instance informer
+const informerResyncPeriod = 30 * time.Second
+
+// waitWithInformer waits for a single runner through an InstanceWaiter, recording
+// the same per-runner state as the watch path
+func (r *KRORunner) waitWithInformer(ctx context.Context, runnerName string, phaseStart time.Time) error {
+	waiter, err := r.NewInstanceWaiter(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Handlers run on the informer goroutine; the last fresh instance is read back once the wait is over
+	var (
+		mu               sync.Mutex
+		last             *unstructured.Unstructured
+		statusFileWarned bool
+	)
+	waiter.only = runnerName
+	waiter.onObserve = func(rg *unstructured.Unstructured) {
+		if r.statusFile != "" {
+			if err := newLiveStatus(runnerName, rg, phaseStart).WriteFile(r.statusFile); err != nil && !statusFileWarned {
+				log.Printf("Warning: %v", err)
+				statusFileWarned = true
+			}
+		}
+
+		if isStaleStatus(rg) {
+			return
+		}
+		if _, found, _ := unstructured.NestedString(rg.Object, "status", "state"); !found {
+			return
+		}
+
+		r.healthy.Store(instanceHealthy(rg) && fatalWaitingReason(rg, r.fatalWaitingReasons) == nil)
+		mu.Lock()
+		last = rg
+		mu.Unlock()
+	}
+
+	informerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if err := waiter.Start(informerCtx); err != nil {
+		return err
+	}
+
+	err = waiter.Wait(ctx, runnerName)
+
+	mu.Lock()
+	if last != nil {
+		r.conditions = extractConditions(last)
+		if code, ok := containerExitCode(last); ok {
+			r.exitCode = &code
+		}
+	}
+	mu.Unlock()
+
+	if r.maxRuntime > 0 && errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("ResourceGraph %s exceeded max runtime of %s", runnerName, r.maxRuntime)
+	}
+	if errors.Is(err, ErrRunnerCancelled) {
+		if delErr := r.DeleteResources(ctx); delErr != nil {
+			log.Printf("Failed to delete cancelled runner %s: %v", runnerName, delErr)
+		}
+	}
+	return err
+}
+
+// instanceResult is the terminal outcome of a single ResourceGraph instance
+type instanceResult struct {
+	done chan struct{}
+	err  error
+}
+
+// InstanceWaiter resolves completion for many ResourceGraph instances of a
+// scale set from one shared informer instead of one watch per instance
+type InstanceWaiter struct {
+	factory  dynamicinformer.DynamicSharedInformerFactory
+	informer cache.SharedIndexInformer
+
+	// fatalWaitingReasons fail an instance whose runner pod is stuck
+	fatalWaitingReasons []string
+	// onMissingPodStatus decides the outcome of a ready instance without a runner pod phase
+	onMissingPodStatus string
+
+	// only restricts evaluation to one instance when set, so other runners of the scale set stay silent
+	only string
+	// onObserve is called with every evaluated instance before its outcome is checked
+	onObserve func(rg *unstructured.Unstructured)
+
+	mu      sync.Mutex
+	results map[string]*instanceResult
+}
+
+// NewInstanceWaiter discovers the scale set's RGD and prepares an informer
+// over its instances, filtered by the scale set label
+func (r *KRORunner) NewInstanceWaiter(ctx context.Context) (*InstanceWaiter, error) {
+	rgdInfo, err := r.findRGDByLabel(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to discover RGD for informer")
+	}
+
+	rgGVR := r.kroGVR(r.resourceName(rgdInfo.Kind))
+
+	selector := labels.SelectorFromSet(labels.Set{rgdLabelKey: r.scaleSetName}).String()
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(r.dynamicClient, informerResyncPeriod, r.namespace,
+		func(opts *metav1.ListOptions) {
+			opts.LabelSelector = selector
+		})
+
+	w := &InstanceWaiter{
+		factory:  factory,
+		informer: factory.ForResource(rgGVR).Informer(),
+		results:  make(map[string]*instanceResult),
+
+		fatalWaitingReasons: r.fatalWaitingReasons,
+		onMissingPodStatus:  r.onMissingPodStatus,
+	}
+
+	_, err = w.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    w.observe,
+		UpdateFunc: func(_, obj interface{}) { w.observe(obj) },
+		DeleteFunc: w.observeDelete,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to register informer handlers")
+	}
+
+	return w, nil
+}
+
+// Start runs the informer until ctx is done and waits for the initial sync
+func (w *InstanceWaiter) Start(ctx context.Context) error {
+	w.factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), w.informer.HasSynced) {
+		return errors.New("failed to sync ResourceGraph instance informer")
+	}
+	return nil
+}
+
+// Wait blocks until the named instance reaches a terminal state or ctx is done
+func (w *InstanceWaiter) Wait(ctx context.Context, runnerName string) error {
+	result := w.result(runnerName)
+
+	select {
+	case <-result.done:
+		return result.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// result returns the tracked result for an instance, creating it on first use
+func (w *InstanceWaiter) result(runnerName string) *instanceResult {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	result, ok := w.results[runnerName]
+	if !ok {
+		result = &instanceResult{done: make(chan struct{})}
+		w.results[runnerName] = result
+	}
+	return result
+}
+
+// resolve records the terminal outcome for an instance; later outcomes are ignored
+func (w *InstanceWaiter) resolve(runnerName string, err error) {
+	result := w.result(runnerName)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	select {
+	case <-result.done:
+	default:
+		result.err = err
+		close(result.done)
+	}
+}
+
+// observe evaluates an added or updated instance
+func (w *InstanceWaiter) observe(obj interface{}) {
+	rg, ok := obj.(*unstructured.Unstructured)
+	if !ok || (w.only != "" && rg.GetName() != w.only) {
+		return
+	}
+
+	if w.onObserve != nil {
+		w.onObserve(rg)
+	}
+	if done, err := instanceOutcome(rg, w.fatalWaitingReasons, w.onMissingPodStatus); done {
+		w.resolve(rg.GetName(), err)
+	}
+}
+
+// observeDelete treats a deleted instance as finished
+func (w *InstanceWaiter) observeDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	rg, ok := obj.(*unstructured.Unstructured)
+	if !ok || (w.only != "" && rg.GetName() != w.only) {
+		return
+	}
+
+	Infof("ResourceGraph %s deleted", rg.GetName())
+	w.resolve(rg.GetName(), nil)
+}
+
+// instanceOutcome reports whether an instance has reached a terminal state and
+// its outcome. FAILED is terminal immediately; the failed grace window only
+// applies to the single-watch path.
+func instanceOutcome(rg *unstructured.Unstructured, fatalWaitingReasons []string, onMissingPodStatus string) (bool, error) {
+	runnerName := rg.GetName()
+
+	if rg.GetAnnotations()[cancelAnnotation] == "true" {
+		log.Printf("ResourceGraph %s has %s=true, cancelling runner", runnerName, cancelAnnotation)
+		return true, ErrRunnerCancelled
+	}
+
+	state, found, err := unstructured.NestedString(rg.Object, "status", "state")
+	if err != nil || !found || isStaleStatus(rg) {
+		return false, nil
+	}
+
+	if err := fatalWaitingReason(rg, fatalWaitingReasons); err != nil {
+		log.Printf("ResourceGraph %s: %v", runnerName, err)
+		logConditions(runnerName, extractConditions(rg))
+		return true, err
+	}
+
+	switch state {
+	case "ACTIVE":
+		done, err := readyOutcome(runnerName, rg, onMissingPodStatus)
+		if done && err != nil {
+			logConditions(runnerName, extractConditions(rg))
+		}
+		return done, err
+	case "FAILED":
+		log.Printf("ResourceGraph %s failed", runnerName)
+		logConditions(runnerName, extractConditions(rg))
+		return true, ErrRunnerFailed
+	case "DELETED":
+		Infof("ResourceGraph %s deleted", runnerName)
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// TestInstanceOutcome tests terminal state evaluation for informer events
+func TestInstanceOutcome(t *testing.T) {
+	base := newTestInstance("default", "runner-a", "test-scale-set")
+	cancelled := base.DeepCopy()
+	cancelled.SetAnnotations(map[string]string{cancelAnnotation: "true"})
+
+	tests := []struct {
+		name     string
+		instance *unstructured.Unstructured
+		wantDone bool
+		wantErr  error
+	}{
+		{name: "no status", instance: base},
+		{name: "active not ready", instance: withStatus(base, "ACTIVE", false, "")},
+		{name: "ready succeeded", instance: withStatus(base, "ACTIVE", true, "Succeeded"), wantDone: true},
+		{name: "ready failed", instance: withStatus(base, "ACTIVE", true, "Failed"), wantDone: true, wantErr: ErrRunnerFailed},
+		{name: "failed", instance: withStatus(base, "FAILED", false, ""), wantDone: true, wantErr: ErrRunnerFailed},
+		{name: "stale failed", instance: withGeneration(withStatus(base, "FAILED", false, ""), 2, 1)},
+		{name: "cancelled", instance: cancelled, wantDone: true, wantErr: ErrRunnerCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done, err := instanceOutcome(tt.instance, DefaultFatalWaitingReasons, MissingPodStatusAssumeSuccess)
+			if done != tt.wantDone {
+				t.Errorf("instanceOutcome() done = %v, want %v", done, tt.wantDone)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("instanceOutcome() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestInstanceWaiter tests that one informer resolves completion for several instances
+func TestInstanceWaiter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	runnerA := newTestInstance("default", "runner-a", "test-scale-set")
+	runnerB := newTestInstance("default", "runner-b", "test-scale-set")
+	runnerC := newTestInstance("default", "runner-c", "test-scale-set")
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"), runnerA, runnerB, runnerC)
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set")
+
+	waiter, err := r.NewInstanceWaiter(ctx)
+	if err != nil {
+		t.Fatalf("NewInstanceWaiter() error = %v", err)
+	}
+	if err := waiter.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	instances := dynamicClient.Resource(testRGGVR).Namespace("default")
+	if _, err := instances.Update(ctx, withStatus(runnerA, "ACTIVE", true, "Succeeded"), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update runner-a: %v", err)
+	}
+	if _, err := instances.Update(ctx, withStatus(runnerB, "FAILED", false, ""), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update runner-b: %v", err)
+	}
+	if err := instances.Delete(ctx, "runner-c", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete runner-c: %v", err)
+	}
+
+	if err := waiter.Wait(ctx, "runner-a"); err != nil {
+		t.Errorf("Wait(runner-a) error = %v, want nil", err)
+	}
+	if err := waiter.Wait(ctx, "runner-b"); !errors.Is(err, ErrRunnerFailed) {
+		t.Errorf("Wait(runner-b) error = %v, want %v", err, ErrRunnerFailed)
+	}
+	if err := waiter.Wait(ctx, "runner-c"); err != nil {
+		t.Errorf("Wait(runner-c) error = %v, want nil", err)
+	}
+}
+
+// TestWaitForResourceGraphWithInformer tests that WithInformer routes the wait through the informer
+func TestWaitForResourceGraphWithInformer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	instance := withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", true, "Succeeded")
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"), instance)
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set", WithInformer(true))
+
+	NewAppContext("test-runner", "")
+	t.Cleanup(func() { appContext = nil })
+
+	if err := r.WaitForResourceGraph(ctx); err != nil {
+		t.Errorf("WaitForResourceGraph() error = %v, want nil", err)
+	}
+}
+
+// TestWaitWithInformerRecordsRunnerState tests that the informer wait records the runner's own state and ignores other instances
+func TestWaitWithInformerRecordsRunnerState(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	failed := withTerminatedContainers(withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "FAILED", false, ""), "runnerPod", 2)
+	other := withStatus(newTestInstance("default", "other-runner", "test-scale-set"), "FAILED", false, "")
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"), failed, other)
+
+	statusFile := filepath.Join(t.TempDir(), "status.json")
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set",
+		WithInformer(true), WithStatusFile(statusFile))
+	r.healthy.Store(true)
+
+	NewAppContext("test-runner", "")
+	t.Cleanup(func() { appContext = nil })
+	logs := captureLogs(t)
+
+	if err := r.WaitForResourceGraph(ctx); !errors.Is(err, ErrRunnerFailed) {
+		t.Fatalf("WaitForResourceGraph() error = %v, want %v", err, ErrRunnerFailed)
+	}
+
+	if len(r.Conditions()) == 0 {
+		t.Error("Conditions() is empty, want the observed instance conditions")
+	}
+	if code, ok := r.ExitCode(); !ok || code != 2 {
+		t.Errorf("ExitCode() = (%d, %v), want (2, true)", code, ok)
+	}
+	if r.healthy.Load() {
+		t.Error("healthy = true, want false for a failed instance")
+	}
+	if _, err := os.Stat(statusFile); err != nil {
+		t.Errorf("status file not written: %v", err)
+	}
+	if out := logs.String(); strings.Contains(out, "other-runner") {
+		t.Errorf("logged another runner of the scale set:\n%s", out)
+	}
+}
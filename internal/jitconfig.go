@@ -0,0 +1,67 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNoRunnerNameInJITConfig is returned when a JIT config does not carry a runner name
+var ErrNoRunnerNameInJITConfig = errors.New("JIT config does not contain a runner name")
+
+// jitRunnerFile is the .runner file embedded in an encoded JIT config
+type jitRunnerFile struct {
+	AgentName string `json:"agentName"`
+}
+
+// RunnerNameFromJITConfig decodes an encoded JIT config, a base64 JSON map of runner
+// files whose values are themselves base64, and returns the AgentName from its .runner file
+func RunnerNameFromJITConfig(jitConfig string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(jitConfig)
+	if err != nil {
+		return "", errors.Wrap(err, "JIT config is not base64")
+	}
+
+	var files map[string]string
+	if err := json.Unmarshal(decoded, &files); err != nil {
+		return "", errors.Wrap(err, "JIT config is not a JSON object")
+	}
+
+	encodedRunner, ok := files[".runner"]
+	if !ok {
+		return "", errors.Wrap(ErrNoRunnerNameInJITConfig, "missing .runner file")
+	}
+
+	rawRunner, err := base64.StdEncoding.DecodeString(encodedRunner)
+	if err != nil {
+		return "", errors.Wrap(err, "JIT config .runner file is not base64")
+	}
+
+	// encoding/json matches field names case-insensitively, so AgentName also decodes
+	var runnerFile jitRunnerFile
+	if err := json.Unmarshal(rawRunner, &runnerFile); err != nil {
+		return "", errors.Wrap(err, "JIT config .runner file is not JSON")
+	}
+	if runnerFile.AgentName == "" {
+		return "", ErrNoRunnerNameInJITConfig
+	}
+
+	return runnerFile.AgentName, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// encodeJITConfig builds an encoded JIT config from runner file contents, as ARC receives it
+func encodeJITConfig(files map[string]string) string {
+	encoded := map[string]string{}
+	for name, content := range files {
+		encoded[name] = base64.StdEncoding.EncodeToString([]byte(content))
+	}
+	data, _ := json.Marshal(encoded)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// TestRunnerNameFromJITConfig tests extracting the runner name from an encoded JIT config
+func TestRunnerNameFromJITConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		jitConfig string
+		expected  string
+		expectErr bool
+		wantErr   error
+	}{
+		{
+			name: "Sample JIT config",
+			jitConfig: encodeJITConfig(map[string]string{
+				".runner":      `{"AgentId":"42","AgentName":"arc-runner-abcde","PoolId":"1","PoolName":"Default","ServerUrl":"https://pipelines.actions.githubusercontent.com/x","GitHubUrl":"https://github.com/org/repo","WorkFolder":"_work"}`,
+				".credentials": `{"Scheme":"OAuth","Data":{"clientId":"id"}}`,
+			}),
+			expected: "arc-runner-abcde",
+		},
+		{
+			name:      "Not base64",
+			jitConfig: "not base64!",
+			expectErr: true,
+		},
+		{
+			name:      "Not a JSON object",
+			jitConfig: base64.StdEncoding.EncodeToString([]byte("[]")),
+			expectErr: true,
+		},
+		{
+			name:      "Missing .runner file",
+			jitConfig: encodeJITConfig(map[string]string{".credentials": "{}"}),
+			expectErr: true,
+			wantErr:   ErrNoRunnerNameInJITConfig,
+		},
+		{
+			name:      "Empty agent name",
+			jitConfig: encodeJITConfig(map[string]string{".runner": `{"AgentId":"42"}`}),
+			expectErr: true,
+			wantErr:   ErrNoRunnerNameInJITConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RunnerNameFromJITConfig(tt.jitConfig)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("RunnerNameFromJITConfig() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("RunnerNameFromJITConfig() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("RunnerNameFromJITConfig() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
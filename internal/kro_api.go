@@ -0,0 +1,71 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"log"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// API group of KRO resources
+	kroGroup = "kro.run"
+
+	// DefaultKROAPIVersion is used when the served KRO version cannot be discovered
+	DefaultKROAPIVersion = "v1alpha1"
+)
+
+// kroVersion returns the configured KRO API version, discovering and caching the
+// server's preferred version when none is configured
+func (r *KRORunner) kroVersion() string {
+	r.kroAPIVersionMu.Lock()
+	defer r.kroAPIVersionMu.Unlock()
+
+	if r.kroAPIVersion == "" {
+		r.kroAPIVersion = r.discoverKROVersion()
+	}
+	return r.kroAPIVersion
+}
+
+// discoverKROVersion returns the server's preferred KRO API version, falling back to DefaultKROAPIVersion
+func (r *KRORunner) discoverKROVersion() string {
+	if r.kubeClient == nil {
+		return DefaultKROAPIVersion
+	}
+
+	groups, err := r.kubeClient.Discovery().ServerGroups()
+	if err != nil {
+		log.Printf("Warning: failed to discover KRO API version, using %s: %v", DefaultKROAPIVersion, err)
+		return DefaultKROAPIVersion
+	}
+
+	for _, group := range groups.Groups {
+		if group.Name == kroGroup && group.PreferredVersion.Version != "" {
+			Infof("Discovered KRO API version %s", group.PreferredVersion.Version)
+			return group.PreferredVersion.Version
+		}
+	}
+
+	log.Printf("Warning: API group %s is not served, using %s", kroGroup, DefaultKROAPIVersion)
+	return DefaultKROAPIVersion
+}
+
+// kroGVR returns the GroupVersionResource of a KRO resource at the resolved API version
+func (r *KRORunner) kroGVR(resource string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: kroGroup, Version: r.kroVersion(), Resource: resource}
+}
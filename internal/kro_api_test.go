@@ -0,0 +1,102 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newKROVersionedClient returns a fake clientset whose discovery serves the KRO group at versions
+func newKROVersionedClient(objects []runtime.Object, versions ...string) *kubefake.Clientset {
+	kubeClient := kubefake.NewClientset(objects...)
+	for _, version := range versions {
+		kubeClient.Resources = append(kubeClient.Resources, &metav1.APIResourceList{
+			GroupVersion: kroGroup + "/" + version,
+			APIResources: []metav1.APIResource{{Name: "resourcegraphdefinitions", Kind: "ResourceGraphDefinition"}},
+		})
+	}
+	return kubeClient
+}
+
+// TestKROVersion tests explicit and discovered KRO API version resolution
+func TestKROVersion(t *testing.T) {
+	tests := []struct {
+		name           string
+		configured     string
+		served         []string
+		discoveryError bool
+		expected       string
+	}{
+		{name: "Explicit version skips discovery", configured: "v1", served: []string{"v1beta1"}, expected: "v1"},
+		{name: "Preferred version discovered", served: []string{"v1beta1", "v1alpha1"}, expected: "v1beta1"},
+		{name: "Group not served falls back", expected: DefaultKROAPIVersion},
+		{name: "Discovery error falls back", served: []string{"v1beta1"}, discoveryError: true, expected: DefaultKROAPIVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := newKROVersionedClient(nil, tt.served...)
+			if tt.discoveryError {
+				kubeClient.PrependReactor("get", "group", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("discovery unavailable")
+				})
+			}
+
+			r := NewKRORunner("default", nil, kubeClient, "test-scale-set", WithKROAPIVersion(tt.configured))
+			if got := r.kroVersion(); got != tt.expected {
+				t.Errorf("kroVersion() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesDiscoveredVersion tests that RGD discovery and instance creation use the discovered version
+func TestCreateResourcesDiscoveredVersion(t *testing.T) {
+	rgdGVR := schema.GroupVersionResource{Group: kroGroup, Version: "v1beta1", Resource: "resourcegraphdefinitions"}
+	instanceGVR := schema.GroupVersionResource{Group: kroGroup, Version: "v1beta1", Resource: "podrunners"}
+
+	rgd := newTestRGD("pod-runner", "test-scale-set", "PodRunner")
+	rgd.SetAPIVersion(kroGroup + "/v1beta1")
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			rgdGVR:      "ResourceGraphDefinitionList",
+			instanceGVR: "PodRunnerList",
+		}, rgd)
+	kubeClient := newKROVersionedClient([]runtime.Object{newTestPod("default", "test-runner")}, "v1beta1")
+
+	r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set")
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+
+	instance, err := dynamicClient.Resource(instanceGVR).Namespace("default").Get(context.TODO(), "test-runner", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("instance was not created at v1beta1: %v", err)
+	}
+	if instance.GetAPIVersion() != kroGroup+"/v1beta1" {
+		t.Errorf("instance apiVersion = %q, want %q", instance.GetAPIVersion(), kroGroup+"/v1beta1")
+	}
+}
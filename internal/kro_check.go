@@ -0,0 +1,65 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"log"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultKROControllerNamespace and DefaultKROControllerName locate the KRO controller Deployment of a default install
+const (
+	DefaultKROControllerNamespace = "kro"
+	DefaultKROControllerName      = "kro"
+)
+
+// ErrKROAbsent is returned when the KRO controller does not appear to be running, so an instance would never reconcile
+var ErrKROAbsent = errors.New("KRO controller appears absent")
+
+// checkKROController fails with ErrKROAbsent when the KRO controller Deployment has no available replicas.
+// When the Deployment cannot be read, for example without RBAC in its namespace or under a different name,
+// it falls back to whether KRO has reconciled the RGD's status
+func (r *KRORunner) checkKROController(ctx context.Context, rgdInfo *RGDInfo) error {
+	namespace, name := r.kroControllerNamespace, r.kroControllerName
+
+	deployment, err := r.kubeClient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		if deployment.Status.AvailableReplicas == 0 {
+			return errors.Wrapf(ErrKROAbsent, "deployment %s/%s has no available replicas", namespace, name)
+		}
+		Infof("KRO controller %s/%s is available", namespace, name)
+		return nil
+	}
+
+	if k8serrors.IsNotFound(err) {
+		log.Printf("Warning: KRO controller deployment %s/%s not found, checking RGD %s status instead",
+			namespace, name, rgdInfo.Name)
+	} else {
+		log.Printf("Warning: cannot read KRO controller deployment %s/%s, checking RGD %s status instead: %v",
+			namespace, name, rgdInfo.Name, err)
+	}
+
+	if rgdInfo.State == "" {
+		return errors.Wrapf(ErrKROAbsent, "RGD %s has no status, so KRO has never reconciled it", rgdInfo.Name)
+	}
+	Infof("RGD %s was reconciled by KRO: state=%s", rgdInfo.Name, rgdInfo.State)
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestKROController builds the KRO controller Deployment with the given available replicas
+func newTestKROController(available int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: DefaultKROControllerName, Namespace: DefaultKROControllerNamespace},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: available},
+	}
+}
+
+// TestCreateResourcesCheckKRO tests failing before create when the KRO controller appears absent
+func TestCreateResourcesCheckKRO(t *testing.T) {
+	tests := []struct {
+		name          string
+		controller    *appsv1.Deployment
+		forbidden     bool
+		rgdReconciled bool
+		checkKRO      bool
+		wantErr       error
+	}{
+		{name: "Controller available", controller: newTestKROController(1), checkKRO: true},
+		{name: "Controller scaled to zero", controller: newTestKROController(0), rgdReconciled: true, checkKRO: true, wantErr: ErrKROAbsent},
+		{name: "Controller absent and RGD never reconciled", checkKRO: true, wantErr: ErrKROAbsent},
+		{name: "Controller elsewhere but RGD reconciled", rgdReconciled: true, checkKRO: true},
+		{name: "Controller unreadable and RGD never reconciled", forbidden: true, checkKRO: true, wantErr: ErrKROAbsent},
+		{name: "Not checked when disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captureLogs(t)
+			rgd := newTestRGD("pod-runner", "test-scale-set", "PodRunner")
+			if tt.rgdReconciled {
+				_ = unstructured.SetNestedField(rgd.Object, "Active", "status", "state")
+			}
+			dynamicClient := newFakeDynamicClient(rgd)
+
+			objects := []runtime.Object{newTestPod("default", "test-runner")}
+			if tt.controller != nil {
+				objects = append(objects, tt.controller)
+			}
+			kubeClient := kubefake.NewClientset(objects...)
+			if tt.forbidden {
+				kubeClient.PrependReactor("get", "deployments", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, k8serrors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "kro", nil)
+				})
+			}
+
+			r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set",
+				WithCheckKRO(tt.checkKRO, DefaultKROControllerNamespace, DefaultKROControllerName))
+			err := r.CreateResources(context.TODO(), "test-runner", "test-config")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateResources() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// No instance may be created when KRO is absent
+			list, _ := dynamicClient.Resource(testRGGVR).Namespace("default").List(context.TODO(), metav1.ListOptions{})
+			if created := len(list.Items) > 0; created != (err == nil) {
+				t.Errorf("instance created = %v, want %v", created, err == nil)
+			}
+		})
+	}
+}
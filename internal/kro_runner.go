@@ -21,14 +21,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -39,15 +51,128 @@ const (
 	// Label for RGD discovery - matches scale set name
 	rgdLabelKey = "actions.github.com/scale-set-name"
 
+	// Label identifying the runner an instance belongs to
+	runnerNameLabelKey = "kro.run/runner-name"
+
+	// Labels correlating an instance with the GitHub workflow run it serves
+	runIDLabelKey      = "actions.github.com/run-id"
+	runAttemptLabelKey = "actions.github.com/run-attempt"
+
 	// Annotation to store runner metadata
 	runnerMetadataAnnotation = "actions.github.com/runner-metadata"
+
+	// Annotation recording the runner name of an instance whose name is a hash
+	runnerNameAnnotation = "actions.github.com/runner-name"
+
+	// Annotation requesting cancellation of a running instance
+	cancelAnnotation = "actions.github.com/cancel"
+
+	// Annotations recording the orchestrator that created an instance
+	creatorPodAnnotation            = "actions.github.com/creator-pod"
+	creatorNodeAnnotation           = "actions.github.com/creator-node"
+	creatorServiceAccountAnnotation = "actions.github.com/creator-service-account"
+
+	// Annotation linking an instance to the GitHub workflow run it serves
+	runURLAnnotation = "actions.github.com/run-url"
+
+	// Annotation asking an external TTL controller to delete an instance this many seconds after creation
+	ttlSecondsAnnotation = "actions.github.com/ttl-seconds"
+
+	// Annotation marking when an instance may be reaped by cleanup tooling
+	expiresAtAnnotation = "actions.github.com/expires-at"
+
+	// Annotation recording how many seconds kar allows for tearing an instance down, 0 for no limit
+	cleanupTimeoutAnnotation = "actions.github.com/cleanup-timeout-seconds"
+
+	// Key holding the JIT config in the ARC-created secret
+	jitConfigSecretKey = ".jitconfig"
+
+	// Env var naming the orchestrator pod when it differs from the runner name
+	orchestratorPodNameEnv = "KAR_ORCHESTRATOR_POD_NAME"
+
+	// Placeholder for sensitive values in logged output
+	redactedValue = "REDACTED"
+
+	// DefaultCreateTimeout bounds the create phase when no timeout is configured
+	DefaultCreateTimeout = 60 * time.Second
+
+	// DefaultMaxWatchReconnects and DefaultWatchReconnectInterval bound reconnecting a closed watch
+	DefaultMaxWatchReconnects     = 5
+	DefaultWatchReconnectInterval = time.Second
+
+	// maxMalformedStatusReads is how many consecutive malformed instance statuses fail the wait
+	maxMalformedStatusReads = 3
+
+	// DefaultWatchResyncInterval is how often the instance is re-read in case the watch missed an event
+	DefaultWatchResyncInterval = 30 * time.Second
 )
 
+// Styles for referencing the ARC-created JIT secret in the instance spec
+const (
+	// JITReferenceNone omits the reference; the RGD derives the secret name from runnerName
+	JITReferenceNone = "none"
+	// JITReferenceName sets jitConfigSecret to the secret name
+	JITReferenceName = "name"
+	// JITReferenceSecretKeyRef sets jitConfigSecret to a map with the secret name and key
+	JITReferenceSecretKeyRef = "secretKeyRef"
+)
+
+// Policies for an instance that already exists under the runner name
+const (
+	// ConflictFail fails the create
+	ConflictFail = "fail"
+	// ConflictAdopt watches the existing instance when its spec matches
+	ConflictAdopt = "adopt"
+	// ConflictReplace deletes the existing instance, waits for it to go and creates a new one
+	ConflictReplace = "replace"
+)
+
+// Policies for a ready instance whose runner pod phase cannot be determined, e.g. for RGDs
+// that do not expose per-resource status
+const (
+	// MissingPodStatusAssumeSuccess treats the runner as succeeded
+	MissingPodStatusAssumeSuccess = "assume-success"
+	// MissingPodStatusAssumeFailure treats the runner as failed
+	MissingPodStatusAssumeFailure = "assume-failure"
+	// MissingPodStatusError fails with ErrPodStatusMissing
+	MissingPodStatusError = "error"
+)
+
+// deletionPollInterval is how often a replaced instance is checked for deletion
+var deletionPollInterval = time.Second
+
+// Strategies for choosing an RGD when several match the discovery selector
+const (
+	// RGDSelectionStrict fails discovery when more than one RGD matches
+	RGDSelectionStrict = "strict"
+	// RGDSelectionNewest picks the most recently created RGD
+	RGDSelectionNewest = "newest"
+	// RGDSelectionOldest picks the earliest created RGD
+	RGDSelectionOldest = "oldest"
+)
+
+// DefaultFatalWaitingReasons are container waiting reasons that fail the runner immediately
+var DefaultFatalWaitingReasons = []string{"ImagePullBackOff", "ErrImagePull", "CrashLoopBackOff"}
+
 // Errors
 var (
-	ErrEmptyRunnerName = errors.New("empty runner name")
-	ErrEmptyJitConfig  = errors.New("empty JIT config")
-	ErrRunnerFailed    = errors.New("runner execution failed")
+	ErrEmptyRunnerName      = errors.New("empty runner name")
+	ErrEmptyJitConfig       = errors.New("empty JIT config")
+	ErrRunnerFailed         = errors.New("runner execution failed")
+	ErrKindNotAllowed       = errors.New("RGD kind not allowed")
+	ErrCreateTimeout        = errors.New("timed out creating resources")
+	ErrUnknownSpecKeys      = errors.New("spec overrides contain fields not in the RGD schema")
+	ErrRunnerCancelled      = errors.New("runner cancelled")
+	ErrRunnerPodStuck       = errors.New("runner pod cannot start")
+	ErrWatchUnavailable     = errors.New("watch unavailable")
+	ErrInstanceMismatch     = errors.New("existing instance does not match the requested spec")
+	ErrInvalidRGDKind       = errors.New("RGD has an empty spec.schema.kind")
+	ErrInstanceInvalid      = errors.New("instance failed validation")
+	ErrNamespaceTerminating = errors.New("namespace is terminating")
+	ErrInvalidMetadata      = errors.New("instance labels or annotations are invalid")
+	ErrMalformedStatus      = errors.New("instance status is malformed")
+	ErrInstanceExists       = errors.New("instance already exists")
+	ErrPodStatusMissing     = errors.New("runner pod status is missing")
 )
 
 // AppContext stores runner context for cleanup
@@ -85,11 +210,69 @@ func (ac *AppContext) GetDataVolumeName() string {
 	return ac.dataVolumeName
 }
 
+// runnerMetadataSchemaVersion is the current format of the runner metadata annotation.
+// Annotations written before versioning have no schemaVersion and parse as version 0.
+const runnerMetadataSchemaVersion = 1
+
+// runnerMetadata mirrors the JSON stored in the runner metadata annotation.
+// Fields are only ever added, so every version decodes into this struct.
+type runnerMetadata struct {
+	SchemaVersion    int    `json:"schemaVersion"`
+	RunnerName       string `json:"runnerName"`
+	ScaleSetName     string `json:"scaleSetName"`
+	JitConfigSecret  string `json:"jitConfigSecret"`
+	CreatedTimestamp string `json:"createdTimestamp"`
+}
+
+// parseRunnerMetadata decodes the runner metadata annotation of an instance
+func parseRunnerMetadata(obj *unstructured.Unstructured) (*runnerMetadata, error) {
+	raw, ok := obj.GetAnnotations()[runnerMetadataAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("instance %s missing %s annotation", obj.GetName(), runnerMetadataAnnotation)
+	}
+
+	metadata := &runnerMetadata{}
+	if err := json.Unmarshal([]byte(raw), metadata); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s annotation on %s", runnerMetadataAnnotation, obj.GetName())
+	}
+
+	// A newer writer may add fields this version ignores; the known fields are still usable
+	if metadata.SchemaVersion > runnerMetadataSchemaVersion {
+		log.Printf("Warning: %s annotation on %s has schema version %d, newer than %d; ignoring unknown fields",
+			runnerMetadataAnnotation, obj.GetName(), metadata.SchemaVersion, runnerMetadataSchemaVersion)
+	}
+
+	return metadata, nil
+}
+
 // RGDInfo holds information about a discovered ResourceGraphDefinition
 type RGDInfo struct {
 	Name      string
 	Namespace string
 	Kind      string // The Kind from RGD schema (e.g., "PodRunner", "VMRunner")
+
+	// SpecSchema is the RGD's spec.schema.spec, nil when not introspectable
+	SpecSchema map[string]interface{}
+
+	// Annotations are the RGD's annotations
+	Annotations map[string]string
+
+	// State is the RGD's status.state, empty until KRO reconciles it
+	State string
+}
+
+// CreateResult identifies the ResourceGraph instance created for a runner
+type CreateResult struct {
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+	UID       types.UID
+}
+
+// String renders the result as a key=value record for log parsers
+func (c CreateResult) String() string {
+	return fmt.Sprintf("event=created group=%s version=%s resource=%s namespace=%s name=%s uid=%s",
+		c.GVR.Group, c.GVR.Version, c.GVR.Resource, c.Namespace, c.Name, c.UID)
 }
 
 // Runner interface for KRO-based runners
@@ -105,64 +288,324 @@ type KRORunner struct {
 	kubeClient    kubernetes.Interface
 	namespace     string
 	scaleSetName  string
+
+	// kroAPIVersion is the KRO API version, discovered from the server when empty
+	kroAPIVersion   string
+	kroAPIVersionMu sync.Mutex
+
+	// scaleSetNamespace scopes RGD discovery for forks with namespaced RGDs; empty lists cluster-wide
+	scaleSetNamespace string
+
+	// printSpec logs the constructed instance spec before create
+	printSpec bool
+
+	// allowedKinds restricts which RGD kinds may be instantiated (empty allows all)
+	allowedKinds []string
+
+	// resume reattaches to a previously created instance instead of creating a new one
+	resume bool
+
+	// deterministicName names the instance after a hash of the scale set, runner name and run ID
+	deterministicName bool
+
+	// resumedUID is the UID of the instance reattached to by resume, whose watch checkpoint may be used
+	resumedUID types.UID
+
+	// createTimeout bounds pod lookup, RGD discovery and instance creation (zero disables)
+	createTimeout time.Duration
+
+	// rgdExtraSelector narrows RGD discovery with additional label requirements
+	rgdExtraSelector map[string]string
+
+	// startupRetryBudget caps the total backoff spent retrying discovery and create (zero leaves retries unbounded)
+	startupRetryBudget time.Duration
+
+	// failureEvents is how many recent instance events are logged on failure (zero disables)
+	failureEvents int
+
+	// failureEventsIncludeRelated also logs events of objects named <runner>-*, such as the runner pod
+	failureEventsIncludeRelated bool
+
+	// watchByLabel selects the watched instance by runner name label instead of a metadata.name field selector
+	watchByLabel bool
+
+	// jitSecretName names ARC's JIT secret when it differs from the runner name
+	jitSecretName string
+
+	// getOrCreate reuses an instance of the same scale set under the instance name instead of creating one
+	getOrCreate bool
+
+	// onConflict decides what happens when the instance already exists, fail by default
+	onConflict string
+
+	// onMissingPodStatus decides the outcome of a ready instance without a runner pod phase, assume-success by default
+	onMissingPodStatus string
+
+	// rgdSelection picks an RGD when several match the discovery selector, strict by default
+	rgdSelection string
+
+	// runnerLabels are the GitHub runner labels passed to the RGD under spec.runnerLabels
+	runnerLabels []string
+
+	// runnerGroup is the GitHub runner group passed to the RGD under spec.runnerGroup, empty when unset
+	runnerGroup string
+
+	// imagePullSecrets are passed to the RGD under spec.imagePullSecrets for pods from private registries
+	imagePullSecrets []string
+
+	// scheduling holds node selector and tolerations passed to the RGD under spec.scheduling, nil when unset
+	scheduling *Scheduling
+
+	// completionExprs replace the built-in completion and failure checks when set
+	completionExprs *CompletionExprs
+
+	// rgdResourceName is used verbatim as the instance resource, bypassing toResourceName
+	rgdResourceName string
+
+	// failedGrace is how long a FAILED state may persist before it is considered terminal
+	failedGrace time.Duration
+
+	// deleteGracePeriod overrides the grace period used when deleting the instance (nil uses the server default)
+	deleteGracePeriod *int64
+
+	// kubeContext and kubeServer describe the resolved kubeconfig for diagnostics
+	kubeContext string
+	kubeServer  string
+
+	// stampCreator annotates instances with the orchestrator pod, node and service account
+	stampCreator bool
+
+	// cleanupTimeout is the orchestrator's budget for tearing the instance down, zero for none
+	cleanupTimeout time.Duration
+
+	// stampCleanupTimeout annotates instances with cleanupTimeout for external reapers
+	stampCleanupTimeout bool
+
+	// fatalWaitingReasons are runner pod container waiting reasons that fail the runner immediately
+	fatalWaitingReasons []string
+
+	// maxWatchReconnects and watchReconnectInterval cap the number and rate of watch reconnects
+	maxWatchReconnects     int
+	watchReconnectInterval time.Duration
+
+	// watchResyncInterval is how often the instance is re-read alongside the watch, zero disables it
+	watchResyncInterval time.Duration
+
+	// logSampleRate logs one in every logSampleRate identical per-event lines of the watch
+	logSampleRate int
+
+	// statusFile receives the live runner status as JSON on each observed instance, empty disables it
+	statusFile string
+
+	// maxRuntime bounds the wait and sets the instance expiry, zero disables both
+	maxRuntime time.Duration
+
+	// instanceTTL is stamped for an external TTL controller to reap the instance, zero disables it
+	instanceTTL time.Duration
+
+	// registeredStatusPath is the instance field the RGD sets once the runner registers with GitHub
+	registeredStatusPath []string
+
+	// watchPodFallback watches the pod named by status.runnerPodName when the status lacks its phase
+	watchPodFallback bool
+
+	// checkQuotaBeforeCreate checks the namespace's ResourceQuotas before creating the instance
+	checkQuotaBeforeCreate bool
+
+	// checkKRO checks the KRO controller is running before creating the instance
+	checkKRO               bool
+	kroControllerNamespace string
+	kroControllerName      string
+
+	// waitActiveOnCreate makes create block until the instance is ACTIVE
+	waitActiveOnCreate bool
+
+	// cleanupConcurrency bounds parallel deletes in CleanupExpired
+	cleanupConcurrency int
+
+	// created identifies the instance from the last successful create
+	created *CreateResult
+
+	// orchestratorPodName overrides the pod fetched for the owner reference
+	orchestratorPodName string
+
+	// terminateOnFirstEvent returns from the wait after the first status-bearing event (debug only)
+	terminateOnFirstEvent bool
+
+	// jitReferenceStyle shapes the jitConfigSecret spec field
+	jitReferenceStyle string
+
+	// validate issues a server-side dry-run create before the real create
+	validate bool
+
+	// useInformer waits through a shared instance informer instead of a single watch
+	useInformer bool
+
+	// specOverrides are merged into the generated instance spec
+	specOverrides map[string]interface{}
+
+	// specTemplate renders the base instance spec instead of {runnerName}, nil when unset
+	specTemplate *SpecTemplate
+
+	// rgdInfo caches the RGD discovered during create so cleanup need not rediscover it
+	rgdInfo *RGDInfo
+
+	// createdAt records when the instance was created, for phase timing
+	createdAt time.Time
+
+	// conditions holds the most recently observed instance status conditions
+	conditions []Condition
+
+	// exitCode holds the runner container exit code once the instance status reports it
+	exitCode *int
+
+	// healthy reports whether the last observed instance was still running without failure; read by signal handling
+	healthy atomic.Bool
 }
 
 var _ Runner = (*KRORunner)(nil)
 
 // NewKRORunner creates a new KRO-based runner
-func NewKRORunner(namespace string, dynamicClient dynamic.Interface, kubeClient kubernetes.Interface, scaleSetName string) *KRORunner {
-	return &KRORunner{
+func NewKRORunner(namespace string, dynamicClient dynamic.Interface, kubeClient kubernetes.Interface, scaleSetName string, opts ...Option) *KRORunner {
+	r := &KRORunner{
 		namespace:     namespace,
 		dynamicClient: dynamicClient,
 		kubeClient:    kubeClient,
 		scaleSetName:  scaleSetName,
+		createTimeout: DefaultCreateTimeout,
+
+		fatalWaitingReasons:    DefaultFatalWaitingReasons,
+		maxWatchReconnects:     DefaultMaxWatchReconnects,
+		watchReconnectInterval: DefaultWatchReconnectInterval,
+		watchResyncInterval:    DefaultWatchResyncInterval,
+		cleanupConcurrency:     DefaultCleanupConcurrency,
+		failureEvents:          DefaultFailureEvents,
 	}
+	r.Configure(opts...)
+
+	return r
 }
 
 // findRGDByLabel discovers an RGD by matching the actions.github.com/scale-set-name label
 func (r *KRORunner) findRGDByLabel(ctx context.Context) (*RGDInfo, error) {
-	log.Printf("Discovering RGD with label %s=%s", rgdLabelKey, r.scaleSetName)
+	Infof("Discovering RGD with label %s=%s", rgdLabelKey, r.scaleSetName)
+	defer logPhaseDuration("discovery", r.scaleSetName, time.Now())
 
-	rgdGVR := schema.GroupVersionResource{
-		Group:    "kro.run",
-		Version:  "v1alpha1",
-		Resource: "resourcegraphdefinitions",
+	rgds, err := r.listRGDs(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// List all RGDs with matching label
-	rgdList, err := r.dynamicClient.Resource(rgdGVR).List(ctx, metav1.ListOptions{
-		LabelSelector: fmt.Sprintf("%s=%s", rgdLabelKey, r.scaleSetName),
-	})
+	selector := r.rgdSelector()
+	if len(rgds) == 0 {
+		return nil, fmt.Errorf("no RGD found with label selector %s", selector)
+	}
+
+	rgd := &rgds[0]
+	if len(rgds) > 1 {
+		rgd = selectRGD(rgds, r.rgdSelection)
+		if rgd == nil {
+			return nil, fmt.Errorf("multiple RGDs found with label selector %s, expected exactly one", selector)
+		}
+		Infof("Selected RGD %s from %d matches with --rgd-selection %s", rgd.GetName(), len(rgds), r.rgdSelection)
+	}
+
+	info, err := newRGDInfo(rgd)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list RGDs")
+		return nil, err
+	}
+
+	Infof("Discovered RGD: name=%s, namespace=%s, kind=%s", info.Name, info.Namespace, info.Kind)
+	return info, nil
+}
+
+// selectRGD picks the newest or oldest RGD by creationTimestamp, breaking ties by name.
+// It returns nil for the strict strategy.
+func selectRGD(rgds []unstructured.Unstructured, strategy string) *unstructured.Unstructured {
+	if strategy != RGDSelectionNewest && strategy != RGDSelectionOldest {
+		return nil
+	}
+
+	sorted := make([]*unstructured.Unstructured, len(rgds))
+	for i := range rgds {
+		sorted[i] = &rgds[i]
 	}
+	sort.Slice(sorted, func(i, j int) bool {
+		ti, tj := sorted[i].GetCreationTimestamp(), sorted[j].GetCreationTimestamp()
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return sorted[i].GetName() < sorted[j].GetName()
+	})
 
-	if len(rgdList.Items) == 0 {
-		return nil, fmt.Errorf("no RGD found with label %s=%s", rgdLabelKey, r.scaleSetName)
+	if strategy == RGDSelectionNewest {
+		return sorted[len(sorted)-1]
 	}
+	return sorted[0]
+}
 
-	if len(rgdList.Items) > 1 {
-		return nil, fmt.Errorf("multiple RGDs found with label %s=%s, expected exactly one", rgdLabelKey, r.scaleSetName)
+// listRGDs lists the RGDs matching the discovery selector
+func (r *KRORunner) listRGDs(ctx context.Context) ([]unstructured.Unstructured, error) {
+	rgdGVR := r.kroGVR("resourcegraphdefinitions")
+
+	// List all RGDs with matching label; RGDs are cluster-scoped unless a scale set namespace is set.
+	// Many orchestrators starting at once can be throttled by priority and fairness, so wait as told.
+	var rgdList *unstructured.UnstructuredList
+	err := retryThrottled(ctx, "Listing RGDs", func() error {
+		var err error
+		rgdList, err = r.dynamicClient.Resource(rgdGVR).Namespace(r.scaleSetNamespace).List(ctx, metav1.ListOptions{
+			LabelSelector: r.rgdSelector(),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list RGDs")
 	}
 
-	rgd := &rgdList.Items[0]
+	return rgdList.Items, nil
+}
 
+// newRGDInfo extracts the instance Kind and spec schema from an RGD
+func newRGDInfo(rgd *unstructured.Unstructured) (*RGDInfo, error) {
 	// Extract the Kind from RGD schema
 	kind, found, err := unstructured.NestedString(rgd.Object, "spec", "schema", "kind")
 	if err != nil || !found {
 		return nil, fmt.Errorf("RGD %s missing spec.schema.kind", rgd.GetName())
 	}
+	// An empty kind would otherwise yield the resource "s"
+	if strings.TrimSpace(kind) == "" {
+		return nil, errors.Wrapf(ErrInvalidRGDKind, "RGD %s", rgd.GetName())
+	}
 
 	info := &RGDInfo{
-		Name:      rgd.GetName(),
-		Namespace: rgd.GetNamespace(),
-		Kind:      kind,
+		Name:        rgd.GetName(),
+		Namespace:   rgd.GetNamespace(),
+		Kind:        kind,
+		Annotations: rgd.GetAnnotations(),
+	}
+
+	info.State, _, _ = unstructured.NestedString(rgd.Object, "status", "state")
+
+	// The spec schema is optional; it is only used to validate overrides
+	if specSchema, found, err := unstructured.NestedMap(rgd.Object, "spec", "schema", "spec"); err == nil && found {
+		info.SpecSchema = specSchema
 	}
 
-	log.Printf("Discovered RGD: name=%s, namespace=%s, kind=%s", info.Name, info.Namespace, info.Kind)
 	return info, nil
 }
 
+// rgdSelector builds the RGD discovery selector from the scale set label and any extra requirements
+func (r *KRORunner) rgdSelector() string {
+	set := labels.Set{}
+	for key, val := range r.rgdExtraSelector {
+		set[key] = val
+	}
+	// The scale set label always wins over extra requirements
+	set[rgdLabelKey] = r.scaleSetName
+
+	return labels.SelectorFromSet(set).String()
+}
+
 // CreateResources creates a ResourceGraph instance for the runner
 func (r *KRORunner) CreateResources(ctx context.Context, runnerName string, jitConfig string) error {
 	if len(runnerName) == 0 {
@@ -173,53 +616,171 @@ func (r *KRORunner) CreateResources(ctx context.Context, runnerName string, jitC
 		return ErrEmptyJitConfig
 	}
 
+	defer logPhaseDuration("create", runnerName, time.Now())
+
+	// Bound the startup API calls separately from the runner's own runtime
+	createCtx := ctx
+	if r.createTimeout > 0 {
+		var cancel context.CancelFunc
+		createCtx, cancel = context.WithTimeout(ctx, r.createTimeout)
+		defer cancel()
+	}
+	if r.startupRetryBudget > 0 {
+		createCtx = withRetryBudget(createCtx, r.startupRetryBudget)
+	}
+
+	err := r.createResources(createCtx, runnerName)
+	if err != nil && ctx.Err() == nil && errors.Is(createCtx.Err(), context.DeadlineExceeded) {
+		return errors.Wrapf(ErrCreateTimeout, "no response within %s: %v", r.createTimeout, err)
+	}
+
+	return err
+}
+
+// createResources discovers the RGD and creates the instance within the create-phase context
+func (r *KRORunner) createResources(ctx context.Context, runnerName string) error {
 	// Get the orchestrator pod to set as owner reference
-	orchestratorPod, err := r.kubeClient.CoreV1().Pods(r.namespace).Get(ctx, runnerName, metav1.GetOptions{})
+	podName := resolveOrchestratorPodName(r.orchestratorPodName, runnerName)
+	var orchestratorPod *corev1.Pod
+	err := retryWithinBudget(ctx, "Getting orchestrator pod "+podName, func() error {
+		var err error
+		orchestratorPod, err = r.kubeClient.CoreV1().Pods(r.namespace).Get(ctx, podName, metav1.GetOptions{})
+		return err
+	})
 	if err != nil {
-		return errors.Wrap(err, "failed to get orchestrator pod for owner reference")
+		return errors.Wrapf(err, "failed to get orchestrator pod %s for owner reference", podName)
 	}
 
 	// Discover the RGD
-	rgdInfo, err := r.findRGDByLabel(ctx)
+	var rgdInfo *RGDInfo
+	err = retryWithinBudget(ctx, "Discovering RGD", func() error {
+		var err error
+		rgdInfo, err = r.findRGDByLabel(ctx)
+		return err
+	})
 	if err != nil {
 		return errors.Wrap(err, "failed to discover RGD")
 	}
 
-	// Note: We don't create a JIT secret - ARC already created one with the runner name
+	if !r.isKindAllowed(rgdInfo.Kind) {
+		return errors.Wrapf(ErrKindNotAllowed, "RGD %s has kind %s, allowed kinds are %v",
+			rgdInfo.Name, rgdInfo.Kind, r.allowedKinds)
+	}
+
+	r.rgdInfo = rgdInfo
+
+	if r.resume {
+		existing, err := r.findExistingInstance(ctx, rgdInfo, runnerName)
+		if err != nil {
+			return errors.Wrap(err, "failed to look up existing instance")
+		}
+
+		if existing != nil {
+			Infof("Resuming existing ResourceGraph instance: kind=%s, name=%s", rgdInfo.Kind, existing.GetName())
+			r.createdAt = existing.GetCreationTimestamp().Time
+			r.resumedUID = existing.GetUID()
+			NewAppContext(existing.GetName(), "")
+			return nil
+		}
+
+		Infof("No existing instance found for runner %s, creating a new one", runnerName)
+	}
+
+	if r.getOrCreate {
+		existing, err := r.getExistingInstance(ctx, rgdInfo, r.instanceName(runnerName))
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			Infof("Reusing existing ResourceGraph instance: kind=%s, name=%s", rgdInfo.Kind, existing.GetName())
+			r.createdAt = existing.GetCreationTimestamp().Time
+			NewAppContext(existing.GetName(), "")
+			return nil
+		}
+	}
+
+	// Without a KRO controller the instance would never reconcile and the wait would hang
+	if r.checkKRO {
+		if err := r.checkKROController(ctx, rgdInfo); err != nil {
+			return err
+		}
+	}
+
+	// Fail fast rather than create an instance whose pod would stay pending on quota
+	if r.checkQuotaBeforeCreate {
+		if err := r.checkQuota(ctx, rgdInfo); err != nil {
+			return err
+		}
+	}
+
+	// Note: We don't create a JIT secret - ARC already created one, named after the runner by default
 	// The RGD will reference the ARC-created secret directly
-	log.Printf("Using ARC-created secret: %s", runnerName)
+	secretName := r.secretName(runnerName)
+	Infof("Using ARC-created secret: %s", secretName)
 
 	// Create ResourceGraph instance
 	rgInstance := &unstructured.Unstructured{}
 	rgInstance.SetGroupVersionKind(schema.GroupVersionKind{
-		Group:   "kro.run",
-		Version: "v1alpha1",
+		Group:   kroGroup,
+		Version: r.kroVersion(),
 		Kind:    rgdInfo.Kind,
 	})
-	rgInstance.SetName(runnerName)
+	instanceName := r.instanceName(runnerName)
+	rgInstance.SetName(instanceName)
 	rgInstance.SetNamespace(r.namespace)
 
 	// Set metadata annotation with runner info
-	metadata := map[string]interface{}{
-		"runnerName":       runnerName,
-		"scaleSetName":     r.scaleSetName,
-		"jitConfigSecret":  runnerName, // ARC creates secret with same name as runner
-		"createdTimestamp": time.Now().Format(time.RFC3339),
+	metadata := runnerMetadata{
+		SchemaVersion:    runnerMetadataSchemaVersion,
+		RunnerName:       runnerName,
+		ScaleSetName:     r.scaleSetName,
+		JitConfigSecret:  secretName,
+		CreatedTimestamp: time.Now().Format(time.RFC3339),
 	}
 	metadataJSON, _ := json.Marshal(metadata)
 
 	annotations := map[string]string{
 		runnerMetadataAnnotation: string(metadataJSON),
 	}
+	if instanceName != runnerName {
+		annotations[runnerNameAnnotation] = runnerName
+	}
+	if r.stampCreator {
+		for key, val := range creatorAnnotations(orchestratorPod) {
+			annotations[key] = val
+		}
+	}
+	if url := runURL(); url != "" {
+		Infof("Workflow run: %s", url)
+		annotations[runURLAnnotation] = url
+	}
+	if r.instanceTTL > 0 {
+		annotations[ttlSecondsAnnotation] = ttlSeconds(r.instanceTTL)
+	}
+	if r.stampCleanupTimeout {
+		annotations[cleanupTimeoutAnnotation] = ttlSeconds(r.cleanupTimeout)
+	}
+	if r.maxRuntime > 0 {
+		annotations[expiresAtAnnotation] = time.Now().Add(r.maxRuntime).UTC().Format(time.RFC3339)
+	}
 	rgInstance.SetAnnotations(annotations)
 
 	// Set labels for tracking
 	labels := map[string]string{
 		"actions.github.com/scale-set-name": r.scaleSetName,
-		"kro.run/runner-name":               runnerName,
+		runnerNameLabelKey:                  runnerName,
+	}
+	for key, val := range runLabels() {
+		labels[key] = val
 	}
 	rgInstance.SetLabels(labels)
 
+	// Fail with the offending key before the API server rejects the create
+	if err := validateInstanceMetadata(labels, annotations); err != nil {
+		return err
+	}
+
 	// Set owner reference to orchestrator pod for garbage collection
 	rgInstance.SetOwnerReferences([]metav1.OwnerReference{
 		{
@@ -231,60 +792,126 @@ func (r *KRORunner) CreateResources(ctx context.Context, runnerName string, jitC
 		},
 	})
 
-	// Build the spec - just pass the runner name
+	// Build the spec - just pass the runner name, unless a template describes it
 	// The RGD will use this to reference the ARC-created secret
 	spec := map[string]interface{}{
 		"runnerName": runnerName,
 	}
+	if r.specTemplate != nil {
+		spec, err = r.specTemplate.Render(SpecTemplateData{
+			RunnerName:   runnerName,
+			ScaleSetName: r.scaleSetName,
+			JITSecret:    secretName,
+			Namespace:    r.namespace,
+			Env:          environMap(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if ref := jitConfigSecretRef(r.jitReferenceStyle, secretName); ref != nil {
+		spec["jitConfigSecret"] = ref
+	}
+	if len(r.runnerLabels) > 0 {
+		spec["runnerLabels"] = runnerLabelsSpec(r.runnerLabels)
+	}
+	if r.runnerGroup != "" {
+		spec["runnerGroup"] = r.runnerGroup
+	}
+	if len(r.imagePullSecrets) > 0 {
+		r.warnMissingImagePullSecrets(ctx)
+		spec["imagePullSecrets"] = imagePullSecretRefs(r.imagePullSecrets)
+	}
+	if r.scheduling != nil {
+		spec["scheduling"] = r.scheduling.spec()
+	}
+
+	if len(r.specOverrides) > 0 {
+		if err := validateSpecOverrides(rgdInfo, r.specOverrides); err != nil {
+			return err
+		}
+		mergeSpec(spec, r.specOverrides)
+	}
 
 	rgInstance.Object["spec"] = spec
 
-	log.Printf("Creating ResourceGraph instance: kind=%s, name=%s", rgdInfo.Kind, runnerName)
+	if r.printSpec {
+		specJSON, err := json.Marshal(redactSpec(spec))
+		if err != nil {
+			log.Printf("Warning: failed to marshal spec for printing: %v", err)
+		} else {
+			log.Printf("ResourceGraph instance spec: %s", specJSON)
+		}
+	}
+
+	Infof("Creating ResourceGraph instance: kind=%s, name=%s", rgdInfo.Kind, instanceName)
 
 	// Create the RG instance
-	rgGVR := schema.GroupVersionResource{
-		Group:    "kro.run",
-		Version:  "v1alpha1",
-		Resource: toResourceName(rgdInfo.Kind), // PodRunner -> podrunners
+	rgGVR := r.kroGVR(r.resourceName(rgdInfo.Kind))
+
+	// Let the API server and admission webhooks validate the instance before the real create
+	if r.validate {
+		err = retryTransient(ctx, "Validating ResourceGraph instance "+instanceName, func() error {
+			_, err := r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Create(ctx, rgInstance, metav1.CreateOptions{
+				DryRun: []string{metav1.DryRunAll},
+			})
+			return err
+		})
+		if err != nil {
+			return errors.Wrap(err, "ResourceGraph instance failed server-side validation")
+		}
+		Infof("ResourceGraph instance passed server-side validation: %s", instanceName)
 	}
 
-	_, err = r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Create(ctx, rgInstance, metav1.CreateOptions{})
+	var created *unstructured.Unstructured
+	err = retryTransient(ctx, "Creating ResourceGraph instance "+instanceName, func() error {
+		var err error
+		created, err = r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Create(ctx, rgInstance, metav1.CreateOptions{})
+		return err
+	})
+	if k8serrors.IsAlreadyExists(err) {
+		switch r.onConflict {
+		case ConflictAdopt:
+			return r.adoptInstance(ctx, rgGVR, instanceName, spec)
+		case ConflictReplace:
+			created, err = r.replaceInstance(ctx, rgGVR, rgInstance)
+		default:
+			return errors.Wrapf(ErrInstanceExists, "ResourceGraph instance %s", instanceName)
+		}
+	}
 	if err != nil {
 		return errors.Wrap(err, "failed to create ResourceGraph instance")
 	}
 
-	log.Printf("ResourceGraph instance created successfully: %s", runnerName)
+	r.created = &CreateResult{
+		GVR:       rgGVR,
+		Namespace: created.GetNamespace(),
+		Name:      created.GetName(),
+		UID:       created.GetUID(),
+	}
+	Infof("%s", r.created)
+	r.createdAt = time.Now()
 
 	// Store in app context for cleanup
 	// Note: No separate secret to track - ARC manages the secret lifecycle
-	NewAppContext(runnerName, "")
+	NewAppContext(instanceName, "")
+
+	if r.waitActiveOnCreate {
+		return r.waitActive(ctx, rgGVR, created)
+	}
 
 	return nil
 }
 
-// WaitForResourceGraph watches the ResourceGraph instance until completion
-func (r *KRORunner) WaitForResourceGraph(ctx context.Context) error {
-	appCtx := GetAppContext()
-	runnerName := appCtx.GetVMIName() // Reusing VMI name field for runner name
-
-	log.Printf("Watching ResourceGraph instance: %s", runnerName)
-
-	// First, discover the RGD to get the Kind
-	rgdInfo, err := r.findRGDByLabel(ctx)
-	if err != nil {
-		return errors.Wrap(err, "failed to discover RGD for watching")
-	}
-
-	rgGVR := schema.GroupVersionResource{
-		Group:    "kro.run",
-		Version:  "v1alpha1",
-		Resource: toResourceName(rgdInfo.Kind),
-	}
+// waitActive watches a newly created instance until KRO reports it ACTIVE, failing on the
+// FAILED state or a validation error condition. ctx bounds the wait to the create timeout.
+func (r *KRORunner) waitActive(ctx context.Context, rgGVR schema.GroupVersionResource, created *unstructured.Unstructured) error {
+	runnerName := created.GetName()
+	Infof("Waiting for ResourceGraph instance %s to become ACTIVE", runnerName)
 
-	// Watch the RG instance
-	watcher, err := r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Watch(ctx, metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("metadata.name=%s", runnerName),
-	})
+	opts := r.instanceWatchOptions(runnerName)
+	opts.ResourceVersion = created.GetResourceVersion()
+	watcher, err := r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Watch(ctx, opts)
 	if err != nil {
 		return errors.Wrap(err, "failed to watch ResourceGraph instance")
 	}
@@ -292,125 +919,1106 @@ func (r *KRORunner) WaitForResourceGraph(ctx context.Context) error {
 
 	for {
 		select {
-		case event := <-watcher.ResultChan():
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return errors.Wrap(ErrWatchUnavailable, "watch closed before the instance became ACTIVE")
+			}
 			if event.Type == watch.Error {
 				return fmt.Errorf("watch error: %v", event.Object)
 			}
 
 			rg, ok := event.Object.(*unstructured.Unstructured)
-			if !ok {
+			if !ok || rg.GetName() != runnerName || isStaleStatus(rg) {
 				continue
 			}
 
-			// Get the state from status
-			state, found, err := unstructured.NestedString(rg.Object, "status", "state")
-			if err != nil || !found {
-				log.Printf("ResourceGraph %s status not yet available", runnerName)
-				continue
+			if condition := validationErrorCondition(rg); condition != nil {
+				log.Printf("ResourceGraph %s was rejected: %s: %s", runnerName, condition.Reason, condition.Message)
+				return errors.Wrapf(ErrInstanceInvalid, "%s: %s", condition.Reason, condition.Message)
 			}
 
-			log.Printf("ResourceGraph %s state: %s", runnerName, state)
-
+			state, _, _ := unstructured.NestedString(rg.Object, "status", "state")
 			switch state {
 			case "ACTIVE":
-				// Check if resources are ready (which means Pod completed due to readyWhen)
-				conditions, found, err := unstructured.NestedSlice(rg.Object, "status", "conditions")
-				if err == nil && found {
-					for _, cond := range conditions {
-						condMap, ok := cond.(map[string]interface{})
-						if !ok {
-							continue
-						}
-						condType, _ := condMap["type"].(string)
-						condStatus, _ := condMap["status"].(string)
-
-						// ResourcesReady means all readyWhen conditions are met (Pod completed)
-						if condType == "ResourcesReady" && condStatus == "True" {
-							log.Printf("ResourceGraph %s resources ready - runner completed", runnerName)
-
-							// Check if it was success or failure by looking at pod status
-							podStatus, found, err := unstructured.NestedMap(rg.Object, "status", "resources", "runnerPod", "status")
-							if err == nil && found {
-								phase, _ := podStatus["phase"].(string)
-								switch phase {
-								case "Succeeded":
-									log.Printf("Runner pod completed successfully")
-									return nil
-								case "Failed":
-									log.Printf("Runner pod failed")
-									return ErrRunnerFailed
-								}
-							}
-
-							// Fallback: if we can't get pod status, assume success since ResourcesReady is true
-							log.Printf("Runner completed (unable to determine pod phase, assuming success)")
-							return nil
-						}
-					}
-				}
-
+				Infof("ResourceGraph %s is ACTIVE", runnerName)
+				return nil
 			case "FAILED":
-				log.Printf("ResourceGraph %s failed", runnerName)
+				logConditions(runnerName, extractConditions(rg))
 				return ErrRunnerFailed
-
-			case "DELETED":
-				log.Printf("ResourceGraph %s deleted", runnerName)
-				return nil
 			}
 
 		case <-ctx.Done():
-			log.Printf("Context cancelled, stopping watch")
 			return ctx.Err()
 		}
 	}
 }
 
-// DeleteResources cleans up the ResourceGraph instance and secret
-func (r *KRORunner) DeleteResources(ctx context.Context) error {
-	appCtx := GetAppContext()
-	runnerName := appCtx.GetVMIName()
-	secretName := appCtx.GetDataVolumeName() // Reusing DataVolume name field for secret name
-
-	log.Printf("Cleaning up ResourceGraph resources for runner: %s", runnerName)
-
-	// Discover the RGD to get the Kind
-	rgdInfo, err := r.findRGDByLabel(ctx)
-	if err != nil {
-		log.Printf("Warning: failed to discover RGD for cleanup: %v", err)
-		// Continue with cleanup anyway
+// instanceWatchOptions selects the runner's instance by name, or by its runner name label when
+// watching by label. Label selection may match other objects, so handlers must check the name.
+func (r *KRORunner) instanceWatchOptions(runnerName string) metav1.ListOptions {
+	if r.watchByLabel {
+		// Some API servers handle field selectors on custom resources poorly
+		return metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", runnerNameLabelKey, runnerName)}
 	}
+	return metav1.ListOptions{FieldSelector: fmt.Sprintf("metadata.name=%s", runnerName)}
+}
 
-	if rgdInfo != nil {
-		// Delete the ResourceGraph instance
-		rgGVR := schema.GroupVersionResource{
-			Group:    "kro.run",
-			Version:  "v1alpha1",
-			Resource: toResourceName(rgdInfo.Kind),
+// validationErrorCondition returns the first False condition whose reason reports
+// invalid input, or nil when there is none
+func validationErrorCondition(rg *unstructured.Unstructured) *Condition {
+	for _, condition := range extractConditions(rg) {
+		if condition.Status != "False" {
+			continue
 		}
-
-		if err := r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Delete(
-			ctx, runnerName, metav1.DeleteOptions{}); err != nil {
-			if !k8serrors.IsNotFound(err) {
-				log.Printf("Failed to delete ResourceGraph instance %s: %v", runnerName, err)
-			}
-		} else {
-			log.Printf("Deleted ResourceGraph instance: %s", runnerName)
+		reason := strings.ToLower(condition.Reason)
+		if strings.Contains(reason, "invalid") || strings.Contains(reason, "validation") {
+			return &condition
 		}
 	}
+	return nil
+}
 
-	// Delete the JIT secret
-	if len(secretName) > 0 {
-		if err := r.kubeClient.CoreV1().Secrets(r.namespace).Delete(
-			ctx, secretName, metav1.DeleteOptions{}); err != nil {
-			if !k8serrors.IsNotFound(err) {
-				log.Printf("Failed to delete JIT secret %s: %v", secretName, err)
-			}
-		} else {
-			log.Printf("Deleted JIT secret: %s", secretName)
-		}
+// WaitForResourceGraph watches the ResourceGraph instance until completion
+func (r *KRORunner) WaitForResourceGraph(ctx context.Context) error {
+	err := r.waitForResourceGraph(ctx)
+
+	// Explain failures and timeouts, but not deliberate cancellation
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, ErrRunnerCancelled) {
+		r.logFailureEvents(ctx, GetAppContext().GetVMIName())
 	}
 
-	return nil
+	return err
+}
+
+// waitForResourceGraph watches the instance until it completes, fails or ctx is done
+func (r *KRORunner) waitForResourceGraph(ctx context.Context) error {
+	appCtx := GetAppContext()
+	runnerName := appCtx.GetVMIName() // Reusing VMI name field for runner name
+
+	Infof("Watching ResourceGraph instance: %s", runnerName)
+
+	// Lifecycle phases are measured from instance creation when known
+	phaseStart := r.createdAt
+	if phaseStart.IsZero() {
+		phaseStart = time.Now()
+	}
+
+	// Bound both wait strategies, since the instance is stamped with the expiry either way
+	if r.maxRuntime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, phaseStart.Add(r.maxRuntime))
+		defer cancel()
+	}
+
+	if r.useInformer {
+		return r.waitWithInformer(ctx, runnerName, phaseStart)
+	}
+
+	activeLogged := false
+	registeredLogged := false
+
+	// First, discover the RGD to get the Kind
+	rgdInfo, err := r.findRGDByLabel(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to discover RGD for watching")
+	}
+
+	rgGVR := r.kroGVR(r.resourceName(rgdInfo.Kind))
+
+	// Watch the RG instance, from the last re-listed resourceVersion when the watch has expired
+	resourceVersion := ""
+	if r.resume {
+		resourceVersion = r.resumeResourceVersion(ctx, runnerName)
+	}
+	openWatch := func() (watch.Interface, error) {
+		opts := r.instanceWatchOptions(runnerName)
+		opts.ResourceVersion = resourceVersion
+		return r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Watch(ctx, opts)
+	}
+	var watcher watch.Interface
+	err = retryTransient(ctx, "Watching ResourceGraph instance "+runnerName, func() error {
+		var err error
+		watcher, err = openWatch()
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to watch ResourceGraph instance")
+	}
+	defer func() { watcher.Stop() }()
+
+	// Consecutive reconnect attempts since the last event
+	reconnects := 0
+
+	// Pending failure while waiting for recovery within the failed grace window
+	var failedGrace <-chan time.Time
+
+	// Periodic Get of the instance, nil when resync is disabled
+	var resync <-chan time.Time
+	if r.watchResyncInterval > 0 {
+		ticker := time.NewTicker(r.watchResyncInterval)
+		defer ticker.Stop()
+		resync = ticker.C
+	}
+
+	// Consecutive instance statuses whose fields have the wrong type
+	malformedReads := 0
+
+	// With resume, the last observation is checkpointed so a restarted orchestrator can resume the watch from it
+	var checkpointTick <-chan time.Time
+	var observed, checkpointed watchCheckpoint
+	if r.resume {
+		ticker := time.NewTicker(checkpointInterval)
+		defer ticker.Stop()
+		checkpointTick = ticker.C
+	}
+
+	// Only the first failed status file write is logged, the file is rewritten on every event
+	statusFileWarned := false
+
+	// Repeated per-event lines are sampled; state changes and terminal outcomes always log
+	sampler := newLogSampler(r.logSampleRate)
+
+	// Terminal phase of the runner pod watched directly, for RGDs whose status lacks it; nil until
+	// the fallback starts and again once its watch ends without a phase
+	var podPhases <-chan corev1.PodPhase
+	podWatchStarted := false
+	podCtx, stopPodWatch := context.WithCancel(ctx)
+	defer stopPodWatch()
+
+	// handle evaluates an observed instance, from a watch event or a resync Get, and reports whether the wait is over
+	handle := func(rg *unstructured.Unstructured) (bool, error) {
+		observed = newWatchCheckpoint(rg)
+
+		if r.statusFile != "" {
+			if err := newLiveStatus(runnerName, rg, phaseStart).WriteFile(r.statusFile); err != nil && !statusFileWarned {
+				log.Printf("Warning: %v", err)
+				statusFileWarned = true
+			}
+		}
+
+		// Operators can cancel a runner declaratively by annotating the instance
+		if rg.GetAnnotations()[cancelAnnotation] == "true" {
+			log.Printf("ResourceGraph %s has %s=true, cancelling runner", runnerName, cancelAnnotation)
+			if err := r.DeleteResources(ctx); err != nil {
+				log.Printf("Failed to delete cancelled runner %s: %v", runnerName, err)
+			}
+			return true, ErrRunnerCancelled
+		}
+
+		// A wrongly typed field is not a missing one; it may be transient, but never waits forever
+		if err := malformedStatus(rg); err != nil {
+			malformedReads++
+			log.Printf("Warning: ResourceGraph %s has a malformed status (%d/%d): %v",
+				runnerName, malformedReads, maxMalformedStatusReads, err)
+			if malformedReads >= maxMalformedStatusReads {
+				return true, errors.Wrapf(ErrMalformedStatus, "ResourceGraph %s: %v", runnerName, err)
+			}
+			return false, nil
+		}
+		malformedReads = 0
+
+		// Get the state from status
+		state, found, _ := unstructured.NestedString(rg.Object, "status", "state")
+		if !found {
+			sampler.Infof("ResourceGraph %s status not yet available", runnerName)
+			return false, nil
+		}
+
+		sampler.Infof("ResourceGraph %s state: %s", runnerName, state)
+
+		// Ignore status that predates KRO reconciling our spec
+		if isStaleStatus(rg) {
+			observed, _, _ := unstructured.NestedInt64(rg.Object, "status", "observedGeneration")
+			sampler.Infof("Skipping stale status for ResourceGraph %s: observedGeneration=%d, generation=%d",
+				runnerName, observed, rg.GetGeneration())
+			return false, nil
+		}
+
+		r.conditions = extractConditions(rg)
+		r.healthy.Store(instanceHealthy(rg))
+		if code, ok := containerExitCode(rg); ok {
+			r.exitCode = &code
+		}
+
+		if r.terminateOnFirstEvent {
+			log.Printf("Debug: stopping after first status event for ResourceGraph %s: state=%s", runnerName, state)
+			logConditions(runnerName, r.conditions)
+			return true, nil
+		}
+
+		if !registeredLogged && isRegistered(rg, r.registeredStatusPath) {
+			Infof("ResourceGraph %s runner registered with GitHub", runnerName)
+			logPhaseDuration("registered", runnerName, phaseStart)
+			registeredLogged = true
+		}
+
+		// A pod that cannot pull its image or crash-loops would otherwise wait until timeout
+		if err := fatalWaitingReason(rg, r.fatalWaitingReasons); err != nil {
+			log.Printf("ResourceGraph %s: %v", runnerName, err)
+			logConditions(runnerName, r.conditions)
+			r.healthy.Store(false)
+			return true, err
+		}
+
+		if done, err := r.completionExprs.outcome(runnerName, rg); done {
+			logPhaseDuration("ready", runnerName, phaseStart)
+			if err != nil {
+				logConditions(runnerName, r.conditions)
+			}
+			return true, err
+		}
+
+		switch state {
+		case "ACTIVE":
+			if failedGrace != nil {
+				Infof("ResourceGraph %s recovered from FAILED", runnerName)
+				failedGrace = nil
+			}
+
+			if !activeLogged {
+				logPhaseDuration("active", runnerName, phaseStart)
+				activeLogged = true
+			}
+
+			// Resources become ready once the Pod completes due to readyWhen,
+			// unless a completion expression replaces the runner pod phase check
+			if r.completionExprs.hasCompletion() {
+				return false, nil
+			}
+			// Without a pod phase in the status, the runner pod itself decides when the RGD names it
+			if podPhases != nil {
+				return false, nil
+			}
+			if r.watchPodFallback && !podWatchStarted && runnerPodPhase(rg) == "" {
+				if podName := runnerPodName(rg); podName != "" {
+					Infof("ResourceGraph %s does not report the runner pod phase, watching pod %s directly", runnerName, podName)
+					podPhases = r.watchPodPhase(podCtx, podName)
+					podWatchStarted = true
+					return false, nil
+				}
+			}
+			if done, err := readyOutcome(runnerName, rg, r.onMissingPodStatus); done {
+				logPhaseDuration("ready", runnerName, phaseStart)
+				if err != nil {
+					logConditions(runnerName, r.conditions)
+				}
+				return true, err
+			}
+
+		case "FAILED":
+			if r.failedGrace <= 0 {
+				log.Printf("ResourceGraph %s failed", runnerName)
+				logConditions(runnerName, r.conditions)
+				return true, ErrRunnerFailed
+			}
+
+			if failedGrace == nil {
+				log.Printf("ResourceGraph %s failed, waiting up to %s for recovery", runnerName, r.failedGrace)
+				failedGrace = time.After(r.failedGrace)
+			}
+
+		case "DELETED":
+			Infof("ResourceGraph %s deleted", runnerName)
+			return true, nil
+		}
+
+		return false, nil
+	}
+
+	for {
+		select {
+		case <-failedGrace:
+			log.Printf("ResourceGraph %s did not recover from FAILED within %s", runnerName, r.failedGrace)
+			logConditions(runnerName, r.conditions)
+			return ErrRunnerFailed
+
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				reconnected, err := r.reconnectWatch(ctx, runnerName, openWatch, &reconnects)
+				if err != nil {
+					return err
+				}
+				watcher = reconnected
+				continue
+			}
+
+			if event.Type == watch.Error {
+				watchErr := k8serrors.FromObject(event.Object)
+				if !k8serrors.IsResourceExpired(watchErr) && !k8serrors.IsGone(watchErr) {
+					return fmt.Errorf("watch error: %v", event.Object)
+				}
+
+				// The resourceVersion is too old to resume from; re-list for a fresh one
+				// and evaluate the current instance, since events in between were lost
+				log.Printf("Warning: watch for ResourceGraph %s expired, re-listing: %v", runnerName, watchErr)
+				watcher.Stop()
+				rg, listVersion, err := r.relistInstance(ctx, rgGVR, runnerName)
+				if err != nil {
+					return err
+				}
+				if rg != nil {
+					if done, err := handle(rg); done {
+						return err
+					}
+				}
+				resourceVersion = listVersion
+
+				// Expired watches count toward the reconnect budget, so a watch that
+				// keeps expiring before delivering an event does not loop forever
+				reconnected, err := r.reconnectWatch(ctx, runnerName, openWatch, &reconnects)
+				if err != nil {
+					return err
+				}
+				watcher = reconnected
+				continue
+			}
+			reconnects = 0
+
+			rg, ok := event.Object.(*unstructured.Unstructured)
+			if !ok || rg.GetName() != runnerName {
+				continue
+			}
+
+			if done, err := handle(rg); done {
+				return err
+			}
+
+		case phase, ok := <-podPhases:
+			if !ok {
+				// Completion falls back to the instance status from the next event on
+				log.Printf("Warning: lost the watch on the runner pod of ResourceGraph %s, using the instance status", runnerName)
+				podPhases = nil
+				continue
+			}
+
+			logPhaseDuration("ready", runnerName, phaseStart)
+			if phase == corev1.PodFailed {
+				log.Printf("Runner pod failed")
+				logConditions(runnerName, r.conditions)
+				return ErrRunnerFailed
+			}
+			Infof("Runner pod completed successfully")
+			return nil
+
+		case <-resync:
+			// A safety net for terminal transitions the watch missed
+			rg, err := r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Get(ctx, runnerName, metav1.GetOptions{})
+			if err != nil {
+				if ctx.Err() == nil {
+					Infof("Resync of ResourceGraph %s failed: %v", runnerName, err)
+				}
+				continue
+			}
+
+			if done, err := handle(rg); done {
+				Infof("ResourceGraph %s reached a terminal state on resync", runnerName)
+				return err
+			}
+
+		case <-checkpointTick:
+			if observed.ResourceVersion == "" || observed == checkpointed {
+				continue
+			}
+			if err := r.writeCheckpoint(ctx, runnerName, observed); err != nil {
+				log.Printf("Warning: %v", err)
+				continue
+			}
+			checkpointed = observed
+
+		case <-ctx.Done():
+			if r.maxRuntime > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				log.Printf("ResourceGraph %s exceeded max runtime of %s", runnerName, r.maxRuntime)
+			} else {
+				Infof("Context cancelled, stopping watch")
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// reconnectWatch re-establishes a closed watch, waiting watchReconnectInterval before each attempt
+// and giving up with ErrWatchUnavailable once maxWatchReconnects consecutive attempts are spent
+func (r *KRORunner) reconnectWatch(ctx context.Context, runnerName string, open func() (watch.Interface, error), attempts *int) (watch.Interface, error) {
+	for {
+		if *attempts >= r.maxWatchReconnects {
+			return nil, errors.Wrapf(ErrWatchUnavailable, "watch for ResourceGraph %s closed after %d reconnect attempts",
+				runnerName, *attempts)
+		}
+		*attempts++
+
+		log.Printf("Watch for ResourceGraph %s closed, reconnect attempt %d/%d in %s",
+			runnerName, *attempts, r.maxWatchReconnects, r.watchReconnectInterval)
+
+		select {
+		case <-time.After(r.watchReconnectInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		watcher, err := open()
+		if err == nil {
+			return watcher, nil
+		}
+		if !IsRetryable(err) {
+			return nil, errors.Wrapf(err, "failed to re-establish watch for ResourceGraph %s", runnerName)
+		}
+		log.Printf("Failed to re-establish watch for ResourceGraph %s: %v", runnerName, err)
+	}
+}
+
+// relistInstance lists the runner's instance for a fresh resourceVersion after a watch expired,
+// returning the instance or nil when it is not listed
+func (r *KRORunner) relistInstance(ctx context.Context, gvr schema.GroupVersionResource, runnerName string) (*unstructured.Unstructured, string, error) {
+	var list *unstructured.UnstructuredList
+	err := retryTransient(ctx, "Re-listing ResourceGraph instance "+runnerName, func() error {
+		var err error
+		list, err = r.dynamicClient.Resource(gvr).Namespace(r.namespace).List(ctx, r.instanceWatchOptions(runnerName))
+		return err
+	})
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to re-list ResourceGraph instance %s", runnerName)
+	}
+
+	for i := range list.Items {
+		if list.Items[i].GetName() == runnerName {
+			return &list.Items[i], list.GetResourceVersion(), nil
+		}
+	}
+	return nil, list.GetResourceVersion(), nil
+}
+
+// malformedStatus returns the first extraction error of the status fields the wait relies on.
+// Missing fields are not errors; only fields of the wrong type are.
+func malformedStatus(rg *unstructured.Unstructured) error {
+	if _, _, err := unstructured.NestedString(rg.Object, "status", "state"); err != nil {
+		return err
+	}
+	if _, _, err := unstructured.NestedSlice(rg.Object, "status", "conditions"); err != nil {
+		return err
+	}
+	if _, _, err := unstructured.NestedMap(rg.Object, "status", "resources", "runnerPod", "status"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// instanceHealthy reports whether an instance is still running its job without failure:
+// ACTIVE or IN_PROGRESS, with a runner pod that has not finished
+func instanceHealthy(rg *unstructured.Unstructured) bool {
+	state, _, _ := unstructured.NestedString(rg.Object, "status", "state")
+	if state != "ACTIVE" && state != "IN_PROGRESS" {
+		return false
+	}
+
+	phase, _, _ := unstructured.NestedString(rg.Object, "status", "resources", "runnerPod", "status", "phase")
+	return phase != "Succeeded" && phase != "Failed"
+}
+
+// InstanceHealthy reports whether the last instance observed by the wait was still running without failure
+func (r *KRORunner) InstanceHealthy() bool {
+	return r.healthy.Load()
+}
+
+// readyOutcome reports whether an ACTIVE instance has completed, i.e. its
+// ResourcesReady condition is True, and whether the runner pod failed. Without
+// a runner pod phase the outcome follows the onMissing policy.
+func readyOutcome(runnerName string, rg *unstructured.Unstructured, onMissing string) (bool, error) {
+	conditions, found, err := unstructured.NestedSlice(rg.Object, "status", "conditions")
+	if err != nil || !found {
+		return false, nil
+	}
+
+	for _, cond := range conditions {
+		condMap, ok := cond.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _ := condMap["type"].(string)
+		condStatus, _ := condMap["status"].(string)
+
+		// ResourcesReady means all readyWhen conditions are met (Pod completed)
+		if condType != "ResourcesReady" || condStatus != "True" {
+			continue
+		}
+
+		Infof("ResourceGraph %s resources ready - runner completed", runnerName)
+
+		// Check if it was success or failure by looking at pod status
+		podStatus, found, err := unstructured.NestedMap(rg.Object, "status", "resources", "runnerPod", "status")
+		if err == nil && found {
+			phase, _ := podStatus["phase"].(string)
+			switch phase {
+			case "Succeeded":
+				Infof("Runner pod completed successfully")
+				return true, nil
+			case "Failed":
+				log.Printf("Runner pod failed")
+				return true, ErrRunnerFailed
+			}
+		}
+
+		// Fallback: without a pod phase, ResourcesReady alone decides unless the policy is stricter
+		switch onMissing {
+		case MissingPodStatusAssumeFailure:
+			log.Printf("Runner completed (unable to determine pod phase, assuming failure)")
+			return true, ErrRunnerFailed
+		case MissingPodStatusError:
+			log.Printf("Runner completed but status.resources.runnerPod.status.phase is missing")
+			return true, errors.Wrapf(ErrPodStatusMissing, "ResourceGraph %s", runnerName)
+		default:
+			Infof("Runner completed (unable to determine pod phase, assuming success)")
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// isRegistered reports whether the field at path is truthy: true, a non-zero number,
+// or a non-empty string other than "false". An empty path never matches.
+func isRegistered(rg *unstructured.Unstructured, path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	val, found, err := unstructured.NestedFieldNoCopy(rg.Object, path...)
+	if err != nil || !found {
+		return false
+	}
+
+	switch v := val.(type) {
+	case bool:
+		return v
+	case string:
+		return v != "" && !strings.EqualFold(v, "false")
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	default:
+		return false
+	}
+}
+
+// fatalWaitingReason returns an error naming the first runner pod container
+// waiting for one of reasons, or nil if there is none
+func fatalWaitingReason(rg *unstructured.Unstructured, reasons []string) error {
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	podStatus, found, err := unstructured.NestedMap(rg.Object, "status", "resources", "runnerPod", "status")
+	if err != nil || !found {
+		return nil
+	}
+
+	for _, field := range []string{"initContainerStatuses", "containerStatuses"} {
+		statuses, _ := podStatus[field].([]interface{})
+		for _, s := range statuses {
+			status, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			reason, _, _ := unstructured.NestedString(status, "state", "waiting", "reason")
+			if reason == "" {
+				continue
+			}
+
+			for _, fatal := range reasons {
+				if reason != fatal {
+					continue
+				}
+
+				name, _ := status["name"].(string)
+				message, _, _ := unstructured.NestedString(status, "state", "waiting", "message")
+				if message != "" {
+					return errors.Wrapf(ErrRunnerPodStuck, "container %s is waiting with reason %s (%s)", name, reason, message)
+				}
+				return errors.Wrapf(ErrRunnerPodStuck, "container %s is waiting with reason %s", name, reason)
+			}
+		}
+	}
+
+	return nil
+}
+
+// DeleteResources cleans up the ResourceGraph instance and secret. Each step is attempted even if
+// another fails, and the failed steps are returned as an aggregate error; already deleted
+// resources are not failures.
+func (r *KRORunner) DeleteResources(ctx context.Context) error {
+	appCtx := GetAppContext()
+	runnerName := appCtx.GetVMIName()
+	secretName := appCtx.GetDataVolumeName() // Reusing DataVolume name field for secret name
+
+	// A signal can arrive before any instance was created; there is nothing to delete yet
+	if runnerName == "" {
+		Infof("No ResourceGraph instance was created, skipping cleanup")
+		return nil
+	}
+
+	Infof("Cleaning up ResourceGraph resources for runner: %s", runnerName)
+	defer logPhaseDuration("cleanup", runnerName, time.Now())
+
+	// Every step runs even if an earlier one failed; the failures are returned together
+	var errs []error
+
+	// Prefer the RGD from the create phase; the cleanup context may be too short to rediscover it
+	rgdInfo := r.rgdInfo
+	if rgdInfo == nil {
+		var err error
+		rgdInfo, err = r.findRGDByLabel(ctx)
+		if err != nil {
+			log.Printf("Warning: failed to discover RGD for cleanup: %v", err)
+			errs = append(errs, errors.Wrapf(err, "failed to discover RGD to delete ResourceGraph instance %s", runnerName))
+			// Continue with cleanup anyway
+		}
+	}
+
+	if rgdInfo != nil {
+		// Delete the ResourceGraph instance
+		rgGVR := r.kroGVR(r.resourceName(rgdInfo.Kind))
+
+		err := retryTransient(ctx, "Deleting ResourceGraph instance "+runnerName, func() error {
+			return r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Delete(ctx, runnerName, metav1.DeleteOptions{
+				GracePeriodSeconds: r.deleteGracePeriod,
+			})
+		})
+		switch {
+		case k8serrors.IsNotFound(err):
+			Infof("ResourceGraph instance %s already deleted", runnerName)
+		case err != nil:
+			log.Printf("Failed to delete ResourceGraph instance %s: %v", runnerName, err)
+			errs = append(errs, errors.Wrapf(err, "failed to delete ResourceGraph instance %s", runnerName))
+		default:
+			Infof("Deleted ResourceGraph instance: %s", runnerName)
+		}
+	}
+
+	if r.resume {
+		r.deleteCheckpoint(ctx, runnerName)
+	}
+
+	// Delete the JIT secret
+	if len(secretName) > 0 {
+		err := retryTransient(ctx, "Deleting JIT secret "+secretName, func() error {
+			return r.kubeClient.CoreV1().Secrets(r.namespace).Delete(ctx, secretName, metav1.DeleteOptions{})
+		})
+		switch {
+		case k8serrors.IsNotFound(err):
+			Infof("JIT secret %s already deleted", secretName)
+		case err != nil:
+			log.Printf("Failed to delete JIT secret %s: %v", secretName, err)
+			errs = append(errs, errors.Wrapf(err, "failed to delete JIT secret %s", secretName))
+		default:
+			Infof("Deleted JIT secret: %s", secretName)
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Conditions returns the most recently observed status conditions of the instance
+func (r *KRORunner) Conditions() []Condition {
+	return r.conditions
+}
+
+// ExitCode returns the runner container exit code, if the instance status reported one
+func (r *KRORunner) ExitCode() (int, bool) {
+	if r.exitCode == nil {
+		return 0, false
+	}
+	return *r.exitCode, true
+}
+
+// jitConfigSecretRef returns the jitConfigSecret spec value for style, or nil when it is omitted
+func jitConfigSecretRef(style, secretName string) interface{} {
+	switch style {
+	case JITReferenceName:
+		return secretName
+	case JITReferenceSecretKeyRef:
+		return map[string]interface{}{
+			"name": secretName,
+			"key":  jitConfigSecretKey,
+		}
+	default:
+		return nil
+	}
+}
+
+// secretName returns the name of ARC's JIT secret, which is the runner name unless configured otherwise
+func (r *KRORunner) secretName(runnerName string) string {
+	if r.jitSecretName != "" {
+		return r.jitSecretName
+	}
+	return runnerName
+}
+
+// Namespace returns the runner namespace, where instances are created, watched and cleaned up
+func (r *KRORunner) Namespace() string {
+	return r.namespace
+}
+
+// Created returns the instance from the last successful create, or nil if none was created
+func (r *KRORunner) Created() *CreateResult {
+	return r.created
+}
+
+// adoptInstance reattaches to an instance that already exists under the runner name,
+// refusing when its spec does not match the spec we would have created
+func (r *KRORunner) adoptInstance(ctx context.Context, rgGVR schema.GroupVersionResource, runnerName string, spec map[string]interface{}) error {
+	existing, err := r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Get(ctx, runnerName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get existing ResourceGraph instance")
+	}
+
+	existingSpec, _, _ := unstructured.NestedMap(existing.Object, "spec")
+	if mismatched := specMismatches(existingSpec, spec); len(mismatched) > 0 {
+		log.Printf("Warning: ResourceGraph instance %s already exists with a different spec (%s), refusing to adopt it",
+			runnerName, strings.Join(mismatched, ", "))
+		return errors.Wrapf(ErrInstanceMismatch, "instance %s differs in %s", runnerName, strings.Join(mismatched, ", "))
+	}
+
+	Infof("Adopting existing ResourceGraph instance: %s", runnerName)
+	r.createdAt = existing.GetCreationTimestamp().Time
+	NewAppContext(runnerName, "")
+
+	return nil
+}
+
+// replaceInstance deletes the instance that exists under the runner name, waits until it is gone
+// and creates rgInstance in its place
+func (r *KRORunner) replaceInstance(ctx context.Context, rgGVR schema.GroupVersionResource, rgInstance *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	runnerName := rgInstance.GetName()
+	client := r.dynamicClient.Resource(rgGVR).Namespace(r.namespace)
+
+	existing, err := client.Get(ctx, runnerName, metav1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		// Deleted since the create conflicted
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to get existing ResourceGraph instance")
+	default:
+		log.Printf("Warning: replacing existing ResourceGraph instance %s", runnerName)
+
+		// The UID precondition keeps us from deleting an instance someone else recreated meanwhile
+		uid := existing.GetUID()
+		err = retryTransient(ctx, "Deleting existing ResourceGraph instance "+runnerName, func() error {
+			return client.Delete(ctx, runnerName, metav1.DeleteOptions{
+				GracePeriodSeconds: r.deleteGracePeriod,
+				Preconditions:      &metav1.Preconditions{UID: &uid},
+			})
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return nil, errors.Wrap(err, "failed to delete existing ResourceGraph instance")
+		}
+
+		if err := r.waitDeleted(ctx, rgGVR, runnerName, uid); err != nil {
+			return nil, err
+		}
+	}
+
+	var created *unstructured.Unstructured
+	err = retryTransient(ctx, "Creating ResourceGraph instance "+runnerName, func() error {
+		var err error
+		created, err = client.Create(ctx, rgInstance, metav1.CreateOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	Infof("Replaced ResourceGraph instance: %s", runnerName)
+	return created, nil
+}
+
+// waitDeleted polls until the instance with uid no longer exists under name
+func (r *KRORunner) waitDeleted(ctx context.Context, rgGVR schema.GroupVersionResource, name string, uid types.UID) error {
+	Infof("Waiting for ResourceGraph instance %s to be deleted", name)
+
+	for {
+		existing, err := r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case k8serrors.IsNotFound(err):
+			return nil
+		case err != nil:
+			Infof("Checking deletion of ResourceGraph instance %s failed: %v", name, err)
+		case existing.GetUID() != uid:
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "waiting for ResourceGraph instance %s to be deleted", name)
+		case <-time.After(deletionPollInterval):
+		}
+	}
+}
+
+// findExistingInstance looks for an instance previously created for this runner and scale set
+func (r *KRORunner) findExistingInstance(ctx context.Context, rgdInfo *RGDInfo, runnerName string) (*unstructured.Unstructured, error) {
+	rgGVR := r.kroGVR(r.resourceName(rgdInfo.Kind))
+
+	instances, err := r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", runnerNameLabelKey, runnerName),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list instances")
+	}
+
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+
+		metadata, err := parseRunnerMetadata(instance)
+		if err != nil {
+			Infof("Skipping instance %s: %v", instance.GetName(), err)
+			continue
+		}
+
+		if metadata.ScaleSetName == r.scaleSetName {
+			return instance, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getExistingInstance gets the instance named instanceName, returning nil when it does not exist
+// or belongs to another scale set, in which case the create goes ahead and --on-conflict applies
+func (r *KRORunner) getExistingInstance(ctx context.Context, rgdInfo *RGDInfo, instanceName string) (*unstructured.Unstructured, error) {
+	rgGVR := r.kroGVR(r.resourceName(rgdInfo.Kind))
+
+	var existing *unstructured.Unstructured
+	err := retryTransient(ctx, "Getting ResourceGraph instance "+instanceName, func() error {
+		var err error
+		existing, err = r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).Get(ctx, instanceName, metav1.GetOptions{})
+		return err
+	})
+	if k8serrors.IsNotFound(err) {
+		Infof("No existing instance %s found, creating it", instanceName)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get existing ResourceGraph instance")
+	}
+
+	if scaleSet := existing.GetLabels()["actions.github.com/scale-set-name"]; scaleSet != r.scaleSetName {
+		log.Printf("Warning: ResourceGraph instance %s belongs to scale set %q, not reusing it", instanceName, scaleSet)
+		return nil, nil
+	}
+
+	return existing, nil
+}
+
+// isStaleStatus reports whether the status was observed for an older generation of the spec.
+// Instances whose status does not report observedGeneration are never considered stale.
+func isStaleStatus(rg *unstructured.Unstructured) bool {
+	observed, found, err := unstructured.NestedInt64(rg.Object, "status", "observedGeneration")
+	if err != nil || !found {
+		return false
+	}
+
+	return observed < rg.GetGeneration()
+}
+
+// resolveOrchestratorPodName picks the orchestrator pod name: explicit name, then
+// KAR_ORCHESTRATOR_POD_NAME, then the runner name, which ARC uses for the pod
+func resolveOrchestratorPodName(name, runnerName string) string {
+	if name != "" {
+		return name
+	}
+	if env := os.Getenv(orchestratorPodNameEnv); env != "" {
+		return env
+	}
+	return runnerName
+}
+
+// creatorAnnotations describes the orchestrator pod, preferring downward API env over the fetched pod
+func creatorAnnotations(pod *corev1.Pod) map[string]string {
+	sources := []struct {
+		annotation string
+		env        string
+		fallback   string
+	}{
+		{creatorPodAnnotation, "POD_NAME", pod.Name},
+		{creatorNodeAnnotation, "NODE_NAME", pod.Spec.NodeName},
+		{creatorServiceAccountAnnotation, "POD_SERVICE_ACCOUNT", pod.Spec.ServiceAccountName},
+	}
+
+	annotations := map[string]string{}
+	for _, source := range sources {
+		val := os.Getenv(source.env)
+		if val == "" {
+			val = source.fallback
+		}
+		if val != "" {
+			annotations[source.annotation] = val
+		}
+	}
+
+	return annotations
+}
+
+// runLabels returns run ID and attempt labels from GITHUB_RUN_ID and GITHUB_RUN_ATTEMPT, omitting unset values
+func runLabels() map[string]string {
+	runLabels := map[string]string{}
+	for key, env := range map[string]string{
+		runIDLabelKey:      "GITHUB_RUN_ID",
+		runAttemptLabelKey: "GITHUB_RUN_ATTEMPT",
+	} {
+		val := strings.TrimSpace(os.Getenv(env))
+		if val == "" {
+			continue
+		}
+		if errs := validation.IsValidLabelValue(val); len(errs) > 0 {
+			log.Printf("Warning: ignoring %s=%q, not a valid label value: %s", env, val, strings.Join(errs, "; "))
+			continue
+		}
+		runLabels[key] = val
+	}
+	return runLabels
+}
+
+// validateInstanceMetadata checks labels and annotations against the Kubernetes key format,
+// the 63 character label value limit and the 256KB total annotation size
+func validateInstanceMetadata(labels, annotations map[string]string) error {
+	labelsPath := field.NewPath("metadata", "labels")
+	annotationsPath := field.NewPath("metadata", "annotations")
+
+	var problems []string
+	for _, key := range sortedKeys(labels) {
+		for _, msg := range validation.IsQualifiedName(key) {
+			problems = append(problems, fmt.Sprintf("%s: invalid key: %s", labelsPath.Key(key), msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(labels[key]) {
+			problems = append(problems, fmt.Sprintf("%s: invalid value: %s", labelsPath.Key(key), msg))
+		}
+	}
+	for _, key := range sortedKeys(annotations) {
+		for _, msg := range validation.IsQualifiedName(strings.ToLower(key)) {
+			problems = append(problems, fmt.Sprintf("%s: invalid key: %s", annotationsPath.Key(key), msg))
+		}
+	}
+	if err := apivalidation.ValidateAnnotationsSize(annotations); err != nil {
+		problems = append(problems, fmt.Sprintf("%s: %v", annotationsPath, err))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.Wrap(ErrInvalidMetadata, strings.Join(problems, "; "))
+}
+
+// sortedKeys returns the keys of m in order so validation errors are stable
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ttlSeconds formats a TTL as whole seconds, rounding up so a sub-second TTL is never zero
+func ttlSeconds(ttl time.Duration) string {
+	return strconv.FormatInt(int64((ttl+time.Second-1)/time.Second), 10)
+}
+
+// runURL returns the workflow run URL from GITHUB_SERVER_URL, GITHUB_REPOSITORY and GITHUB_RUN_ID, or "" if any is unset
+func runURL() string {
+	server := strings.TrimSuffix(strings.TrimSpace(os.Getenv("GITHUB_SERVER_URL")), "/")
+	repository := strings.Trim(strings.TrimSpace(os.Getenv("GITHUB_REPOSITORY")), "/")
+	runID := strings.TrimSpace(os.Getenv("GITHUB_RUN_ID"))
+	if server == "" || repository == "" || runID == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/%s/actions/runs/%s", server, repository, runID)
+}
+
+// logPhaseDuration emits the elapsed time of a lifecycle phase as structured fields
+func logPhaseDuration(phase, subject string, start time.Time) {
+	Infof("phase=%s subject=%s phase_duration_ms=%d", phase, subject, time.Since(start).Milliseconds())
+}
+
+// isKindAllowed reports whether instances of the given kind may be created
+func (r *KRORunner) isKindAllowed(kind string) bool {
+	if len(r.allowedKinds) == 0 {
+		return true
+	}
+
+	for _, allowed := range r.allowedKinds {
+		if allowed == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactSpec returns a copy of the spec with JIT config and credential values masked, keeping Secret names
+func redactSpec(spec map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(spec))
+	for key, val := range spec {
+		if isSensitiveKey(key) {
+			out[key] = redactedValue
+			continue
+		}
+
+		switch v := val.(type) {
+		case map[string]interface{}:
+			out[key] = redactSpec(v)
+		case []interface{}:
+			items := make([]interface{}, len(v))
+			for i, item := range v {
+				if m, ok := item.(map[string]interface{}); ok {
+					items[i] = redactSpec(m)
+				} else {
+					items[i] = item
+				}
+			}
+			out[key] = items
+		default:
+			out[key] = val
+		}
+	}
+
+	return out
+}
+
+// secretReferenceSuffixes end spec keys that name a Secret rather than carry its data, e.g. jitConfigSecretName
+// or imagePullSecrets; --print-spec exists to check such references, so they are never masked
+var secretReferenceSuffixes = []string{"secretname", "secretref", "secretkeyref", "pullsecrets"}
+
+// isSensitiveKey reports whether a spec key may carry JIT config or other credential material
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+
+	// kar's own jitConfigSecret field only references ARC's secret by name
+	if lower == "jitconfigsecret" {
+		return false
+	}
+	for _, suffix := range secretReferenceSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return false
+		}
+	}
+
+	for _, marker := range []string{"jitconfig", "secret", "token", "password"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resourceName returns the plural resource of an RGD kind, honouring the configured override
+func (r *KRORunner) resourceName(kind string) string {
+	if r.rgdResourceName != "" {
+		return r.rgdResourceName
+	}
+	return toResourceName(kind)
 }
 
 // toResourceName converts Kind to resource name (PodRunner -> podrunners)
@@ -17,10 +17,129 @@ limitations under the License.
 package runner
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+var (
+	testRGDGVR = schema.GroupVersionResource{Group: "kro.run", Version: "v1alpha1", Resource: "resourcegraphdefinitions"}
+	testRGGVR  = schema.GroupVersionResource{Group: "kro.run", Version: "v1alpha1", Resource: "podrunners"}
 )
 
+// newTestRGD builds an RGD labelled for the given scale set
+func newTestRGD(name, scaleSetName, kind string) *unstructured.Unstructured {
+	rgd := &unstructured.Unstructured{}
+	rgd.SetGroupVersionKind(schema.GroupVersionKind{Group: "kro.run", Version: "v1alpha1", Kind: "ResourceGraphDefinition"})
+	rgd.SetName(name)
+	rgd.SetLabels(map[string]string{rgdLabelKey: scaleSetName})
+	_ = unstructured.SetNestedField(rgd.Object, kind, "spec", "schema", "kind")
+	return rgd
+}
+
+// newTestPod builds the orchestrator pod used for owner references
+func newTestPod(namespace, name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       "orchestrator-uid",
+		},
+	}
+}
+
+// newTestInstance builds a PodRunner instance as created by CreateResources
+func newTestInstance(namespace, runnerName, scaleSetName string) *unstructured.Unstructured {
+	instance := &unstructured.Unstructured{}
+	instance.SetGroupVersionKind(schema.GroupVersionKind{Group: "kro.run", Version: "v1alpha1", Kind: "PodRunner"})
+	instance.SetName(runnerName)
+	instance.SetNamespace(namespace)
+	instance.SetLabels(map[string]string{
+		rgdLabelKey:        scaleSetName,
+		runnerNameLabelKey: runnerName,
+	})
+	instance.SetAnnotations(map[string]string{
+		runnerMetadataAnnotation: fmt.Sprintf(`{"runnerName":%q,"scaleSetName":%q}`, runnerName, scaleSetName),
+	})
+	return instance
+}
+
+// withStatus sets the KRO status on an instance; an empty podPhase omits the runner pod status
+func withStatus(instance *unstructured.Unstructured, state string, resourcesReady bool, podPhase string) *unstructured.Unstructured {
+	instance = instance.DeepCopy()
+	_ = unstructured.SetNestedField(instance.Object, state, "status", "state")
+
+	readyStatus := "False"
+	if resourcesReady {
+		readyStatus = "True"
+	}
+	_ = unstructured.SetNestedSlice(instance.Object, []interface{}{
+		map[string]interface{}{"type": "ResourcesReady", "status": readyStatus},
+	}, "status", "conditions")
+
+	if podPhase != "" {
+		_ = unstructured.SetNestedField(instance.Object, podPhase, "status", "resources", "runnerPod", "status", "phase")
+	}
+
+	return instance
+}
+
+// newWatchedRunner returns a runner for "test-runner" whose instance watch is driven by the returned fake watcher
+func newWatchedRunner(t *testing.T, opts ...Option) (*KRORunner, *watch.FakeWatcher, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	watcher := watch.NewFakeWithChanSize(10, false)
+	dynamicClient.PrependWatchReactor("podrunners", k8stesting.DefaultWatchReactor(watcher, nil))
+
+	NewAppContext("test-runner", "")
+	t.Cleanup(func() { appContext = nil })
+
+	return NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set", opts...), watcher, dynamicClient
+}
+
+// newFakeDynamicClient returns a fake dynamic client that can list RGDs and PodRunner instances
+func newFakeDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			testRGDGVR: "ResourceGraphDefinitionList",
+			testRGGVR:  "PodRunnerList",
+		}, objects...)
+}
+
+// captureLogs redirects the standard logger into a buffer for the duration of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	return &buf
+}
+
 // TestToResourceName tests the toResourceName function
 func TestToResourceName(t *testing.T) {
 	tests := []struct {
@@ -252,3 +371,2132 @@ func TestRGDInfo(t *testing.T) {
 		t.Errorf("RGDInfo.Kind = %q, want %q", info.Kind, "PodRunner")
 	}
 }
+
+// TestCreateResourcesPrintSpec tests that the spec is logged before the instance is created
+func TestCreateResourcesPrintSpec(t *testing.T) {
+	tests := []struct {
+		name      string
+		printSpec bool
+	}{
+		{name: "Print spec enabled", printSpec: true},
+		{name: "Print spec disabled", printSpec: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+			kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+
+			r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set", WithPrintSpec(tt.printSpec))
+			if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+
+			if _, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+				context.TODO(), "test-runner", metav1.GetOptions{}); err != nil {
+				t.Errorf("instance was not created: %v", err)
+			}
+
+			printed := strings.Contains(logs.String(), `ResourceGraph instance spec: {"runnerName":"test-runner"}`)
+			if printed != tt.printSpec {
+				t.Errorf("spec printed = %v, want %v; logs:\n%s", printed, tt.printSpec, logs.String())
+			}
+			if strings.Contains(logs.String(), "test-config") {
+				t.Error("JIT config leaked into logs")
+			}
+		})
+	}
+}
+
+// TestRedactSpec tests that sensitive spec values are masked
+func TestRedactSpec(t *testing.T) {
+	spec := map[string]interface{}{
+		"runnerName":          "test-runner",
+		"jitConfigSecret":     "test-runner",
+		"jitConfigSecretName": "arc-jit-secret",
+		"jitConfig":           "eyJydW5uZXIiOiJ0ZXN0In0=",
+		"clientSecret":        "s3cr3t",
+		"imagePullSecrets":    []interface{}{map[string]interface{}{"name": "regcred"}},
+		"env": map[string]interface{}{
+			"GITHUB_TOKEN": "ghp_abc",
+			"LOG_LEVEL":    "debug",
+		},
+		"items": []interface{}{
+			map[string]interface{}{"password": "hunter2"},
+			"plain",
+		},
+	}
+
+	redacted := redactSpec(spec)
+
+	if redacted["runnerName"] != "test-runner" {
+		t.Errorf("runnerName = %v, want unchanged", redacted["runnerName"])
+	}
+
+	// Secret names are what --print-spec is used to check
+	if redacted["jitConfigSecret"] != "test-runner" {
+		t.Errorf("jitConfigSecret = %v, want unchanged", redacted["jitConfigSecret"])
+	}
+	if redacted["jitConfigSecretName"] != "arc-jit-secret" {
+		t.Errorf("jitConfigSecretName = %v, want unchanged", redacted["jitConfigSecretName"])
+	}
+	pullSecrets := redacted["imagePullSecrets"].([]interface{})
+	if name := pullSecrets[0].(map[string]interface{})["name"]; name != "regcred" {
+		t.Errorf("imagePullSecrets[0].name = %v, want unchanged", name)
+	}
+
+	// Credential material is masked
+	if redacted["jitConfig"] != redactedValue {
+		t.Errorf("jitConfig = %v, want %q", redacted["jitConfig"], redactedValue)
+	}
+	if redacted["clientSecret"] != redactedValue {
+		t.Errorf("clientSecret = %v, want %q", redacted["clientSecret"], redactedValue)
+	}
+	env := redacted["env"].(map[string]interface{})
+	if env["GITHUB_TOKEN"] != redactedValue {
+		t.Errorf("env.GITHUB_TOKEN = %v, want %q", env["GITHUB_TOKEN"], redactedValue)
+	}
+	if env["LOG_LEVEL"] != "debug" {
+		t.Errorf("env.LOG_LEVEL = %v, want unchanged", env["LOG_LEVEL"])
+	}
+	items := redacted["items"].([]interface{})
+	if items[0].(map[string]interface{})["password"] != redactedValue {
+		t.Errorf("items[0].password = %v, want %q", items[0], redactedValue)
+	}
+	if items[1] != "plain" {
+		t.Errorf("items[1] = %v, want unchanged", items[1])
+	}
+
+	// The original spec must not be modified
+	if spec["jitConfig"] != "eyJydW5uZXIiOiJ0ZXN0In0=" {
+		t.Error("redactSpec modified the original spec")
+	}
+}
+
+// TestCreateResourcesAllowedKinds tests that only allowlisted RGD kinds are instantiated
+func TestCreateResourcesAllowedKinds(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowedKinds []string
+		expectedErr  error
+	}{
+		{name: "Empty allowlist allows all", allowedKinds: nil},
+		{name: "Kind in allowlist", allowedKinds: []string{"VMRunner", "PodRunner"}},
+		{name: "Kind not in allowlist", allowedKinds: []string{"VMRunner"}, expectedErr: ErrKindNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+			kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+
+			r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set", WithAllowedKinds(tt.allowedKinds))
+			err := r.CreateResources(context.TODO(), "test-runner", "test-config")
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("CreateResources() error = %v, want %v", err, tt.expectedErr)
+			}
+
+			_, getErr := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+				context.TODO(), "test-runner", metav1.GetOptions{})
+			created := getErr == nil
+			if created != (tt.expectedErr == nil) {
+				t.Errorf("instance created = %v, want %v", created, tt.expectedErr == nil)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesResume tests reattaching to an instance left behind by a previous orchestrator
+func TestCreateResourcesResume(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      []runtime.Object
+		expectCreate  bool
+		expectRunning string
+	}{
+		{
+			name:          "Existing instance is reattached",
+			existing:      []runtime.Object{newTestInstance("default", "test-runner", "test-scale-set")},
+			expectCreate:  false,
+			expectRunning: "test-runner",
+		},
+		{
+			name:          "No existing instance creates a new one",
+			expectCreate:  true,
+			expectRunning: "test-runner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appContext = nil
+			objects := append([]runtime.Object{newTestRGD("pod-runner", "test-scale-set", "PodRunner")}, tt.existing...)
+			dynamicClient := newFakeDynamicClient(objects...)
+			kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+
+			r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set", WithResume(true))
+			if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+
+			created := false
+			for _, action := range dynamicClient.Actions() {
+				if action.GetVerb() == "create" {
+					created = true
+				}
+			}
+			if created != tt.expectCreate {
+				t.Errorf("create called = %v, want %v", created, tt.expectCreate)
+			}
+
+			if got := GetAppContext().GetVMIName(); got != tt.expectRunning {
+				t.Errorf("app context runner = %q, want %q", got, tt.expectRunning)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesGetOrCreate tests that get-or-create reuses an instance of the same scale set without creating
+func TestCreateResourcesGetOrCreate(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     []runtime.Object
+		expectCreate bool
+		expectedErr  error
+	}{
+		{
+			name:         "Existing instance is reused",
+			existing:     []runtime.Object{newTestInstance("default", "test-runner", "test-scale-set")},
+			expectCreate: false,
+		},
+		{
+			name:         "No existing instance creates a new one",
+			expectCreate: true,
+		},
+		{
+			name:         "Instance of another scale set falls through to the conflict policy",
+			existing:     []runtime.Object{newTestInstance("default", "test-runner", "other-scale-set")},
+			expectCreate: true,
+			expectedErr:  ErrInstanceExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { appContext = nil })
+			objects := append([]runtime.Object{newTestRGD("pod-runner", "test-scale-set", "PodRunner")}, tt.existing...)
+			dynamicClient := newFakeDynamicClient(objects...)
+			kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+
+			r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set",
+				WithGetOrCreate(true), WithOnConflict(ConflictFail))
+			err := r.CreateResources(context.TODO(), "test-runner", "test-config")
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("CreateResources() error = %v, want %v", err, tt.expectedErr)
+			}
+
+			var gets, creates int
+			for _, action := range dynamicClient.Actions() {
+				if action.GetResource().Resource != "podrunners" {
+					continue
+				}
+				switch action.GetVerb() {
+				case "get":
+					gets++
+				case "create":
+					creates++
+				}
+			}
+			if gets == 0 {
+				t.Error("expected a get before creating")
+			}
+			if created := creates > 0; created != tt.expectCreate {
+				t.Errorf("create called = %v, want %v", created, tt.expectCreate)
+			}
+
+			if tt.expectedErr == nil {
+				if got := GetAppContext().GetVMIName(); got != "test-runner" {
+					t.Errorf("app context runner = %q, want %q", got, "test-runner")
+				}
+			}
+		})
+	}
+}
+
+// TestFindExistingInstanceScaleSetMismatch tests that instances from another scale set are ignored
+func TestFindExistingInstanceScaleSetMismatch(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newTestInstance("default", "test-runner", "other-scale-set"))
+	r := NewKRORunner("default", dynamicClient, nil, "test-scale-set")
+
+	instance, err := r.findExistingInstance(context.TODO(), &RGDInfo{Kind: "PodRunner"}, "test-runner")
+	if err != nil {
+		t.Fatalf("findExistingInstance() error = %v", err)
+	}
+	if instance != nil {
+		t.Errorf("findExistingInstance() = %s, want nil", instance.GetName())
+	}
+}
+
+// TestCreateResourcesTimeout tests that a hung create call is bounded by the create timeout
+func TestCreateResourcesTimeout(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+
+	// Block the create like an unresponsive API server until the client gives up
+	dynamicClient.PrependReactor("create", "podrunners", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		time.Sleep(100 * time.Millisecond)
+		return true, nil, context.DeadlineExceeded
+	})
+
+	r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set", WithCreateTimeout(10*time.Millisecond))
+	err := r.CreateResources(context.TODO(), "test-runner", "test-config")
+	if !errors.Is(err, ErrCreateTimeout) {
+		t.Fatalf("CreateResources() error = %v, want %v", err, ErrCreateTimeout)
+	}
+}
+
+// TestCreateResourcesWaitActive tests that create blocks until the instance is ACTIVE or rejected
+func TestCreateResourcesWaitActive(t *testing.T) {
+	invalid := newTestInstance("default", "test-runner", "test-scale-set")
+	_ = unstructured.SetNestedSlice(invalid.Object, []interface{}{
+		map[string]interface{}{"type": "InstanceSynced", "status": "False", "reason": "InvalidSpec", "message": "spec.image is required"},
+	}, "status", "conditions")
+
+	tests := []struct {
+		name    string
+		events  []*unstructured.Unstructured
+		wantErr error
+	}{
+		{
+			name: "Becomes ACTIVE",
+			events: []*unstructured.Unstructured{
+				withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "IN_PROGRESS", false, ""),
+				withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", false, "Pending"),
+			},
+		},
+		{
+			name:    "Validation error condition",
+			events:  []*unstructured.Unstructured{invalid},
+			wantErr: ErrInstanceInvalid,
+		},
+		{
+			name:    "FAILED state",
+			events:  []*unstructured.Unstructured{withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "FAILED", false, "")},
+			wantErr: ErrRunnerFailed,
+		},
+		{
+			name:    "Never ACTIVE within the create timeout",
+			wantErr: ErrCreateTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+			watcher := watch.NewFakeWithChanSize(10, false)
+			dynamicClient.PrependWatchReactor("podrunners", k8stesting.DefaultWatchReactor(watcher, nil))
+			for _, event := range tt.events {
+				watcher.Modify(event)
+			}
+			t.Cleanup(func() { appContext = nil })
+
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")),
+				"test-scale-set", WithWaitActiveOnCreate(true), WithCreateTimeout(200*time.Millisecond))
+			err := r.CreateResources(context.TODO(), "test-runner", "test-config")
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateResources() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// The instance exists and is tracked for cleanup either way
+			if name := GetAppContext().GetVMIName(); name != "test-runner" {
+				t.Errorf("app context runner = %q, want test-runner", name)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesParentCancelled tests that parent cancellation is not reported as a create timeout
+func TestCreateResourcesParentCancelled(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dynamicClient.PrependReactor("create", "podrunners", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		cancel()
+		return true, nil, context.Canceled
+	})
+
+	r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set")
+	err := r.CreateResources(ctx, "test-runner", "test-config")
+	if err == nil || errors.Is(err, ErrCreateTimeout) {
+		t.Fatalf("CreateResources() error = %v, want a non-timeout error", err)
+	}
+}
+
+// TestPhaseDurationLogs tests that each lifecycle phase reports its elapsed time
+func TestPhaseDurationLogs(t *testing.T) {
+	logs := captureLogs(t)
+	r, watcher, _ := newWatchedRunner(t)
+
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+	watcher.Modify(withStatus(instance, "ACTIVE", false, ""))
+	watcher.Modify(withStatus(instance, "ACTIVE", true, "Succeeded"))
+
+	if err := r.WaitForResourceGraph(context.TODO()); err != nil {
+		t.Fatalf("WaitForResourceGraph() error = %v", err)
+	}
+	if err := r.DeleteResources(context.TODO()); err != nil {
+		t.Fatalf("DeleteResources() error = %v", err)
+	}
+
+	for _, phase := range []string{"discovery", "active", "ready", "cleanup"} {
+		if !strings.Contains(logs.String(), "phase="+phase+" ") {
+			t.Errorf("missing phase_duration_ms for phase %q; logs:\n%s", phase, logs.String())
+		}
+	}
+	if strings.Count(logs.String(), "phase=active ") != 1 {
+		t.Errorf("phase=active logged %d times, want 1", strings.Count(logs.String(), "phase=active "))
+	}
+}
+
+// withGeneration sets metadata.generation and status.observedGeneration on an instance
+func withGeneration(instance *unstructured.Unstructured, generation, observed int64) *unstructured.Unstructured {
+	instance = instance.DeepCopy()
+	instance.SetGeneration(generation)
+	_ = unstructured.SetNestedField(instance.Object, observed, "status", "observedGeneration")
+	return instance
+}
+
+// TestWaitForResourceGraphSkipsStaleGeneration tests that status from before reconcile is ignored
+func TestWaitForResourceGraphSkipsStaleGeneration(t *testing.T) {
+	logs := captureLogs(t)
+	r, watcher, _ := newWatchedRunner(t)
+
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+	watcher.Modify(withGeneration(withStatus(instance, "ACTIVE", true, "Failed"), 2, 1))
+	watcher.Modify(withGeneration(withStatus(instance, "ACTIVE", true, "Succeeded"), 2, 2))
+
+	if err := r.WaitForResourceGraph(context.TODO()); err != nil {
+		t.Fatalf("WaitForResourceGraph() error = %v, want nil", err)
+	}
+	if !strings.Contains(logs.String(), "Skipping stale status") {
+		t.Errorf("stale event was not logged; logs:\n%s", logs.String())
+	}
+}
+
+// TestIsStaleStatus tests the observedGeneration comparison
+func TestIsStaleStatus(t *testing.T) {
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+
+	tests := []struct {
+		name     string
+		instance *unstructured.Unstructured
+		expected bool
+	}{
+		{name: "No observedGeneration", instance: instance, expected: false},
+		{name: "Observed older generation", instance: withGeneration(instance, 3, 2), expected: true},
+		{name: "Observed current generation", instance: withGeneration(instance, 3, 3), expected: false},
+		{name: "Observed newer generation", instance: withGeneration(instance, 3, 4), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStaleStatus(tt.instance); got != tt.expected {
+				t.Errorf("isStaleStatus() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFindRGDByLabelExtraSelector tests narrowing discovery when several RGDs share a scale set name
+func TestFindRGDByLabelExtraSelector(t *testing.T) {
+	devRGD := newTestRGD("pod-runner-dev", "test-scale-set", "DevPodRunner")
+	devRGD.SetLabels(map[string]string{rgdLabelKey: "test-scale-set", "env": "dev"})
+	prodRGD := newTestRGD("pod-runner-prod", "test-scale-set", "PodRunner")
+	prodRGD.SetLabels(map[string]string{rgdLabelKey: "test-scale-set", "env": "prod", "tier": "gold"})
+
+	tests := []struct {
+		name         string
+		extra        map[string]string
+		expectedName string
+		expectErr    bool
+	}{
+		{name: "Scale set label alone is ambiguous", expectErr: true},
+		{name: "Extra selector picks dev", extra: map[string]string{"env": "dev"}, expectedName: "pod-runner-dev"},
+		{name: "Multiple extra requirements", extra: map[string]string{"env": "prod", "tier": "gold"}, expectedName: "pod-runner-prod"},
+		{name: "No match", extra: map[string]string{"env": "staging"}, expectErr: true},
+		{name: "Extra selector cannot replace the scale set label", extra: map[string]string{rgdLabelKey: "other", "env": "dev"}, expectedName: "pod-runner-dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewKRORunner("default", newFakeDynamicClient(devRGD, prodRGD), nil, "test-scale-set",
+				WithRGDExtraSelector(tt.extra))
+
+			info, err := r.findRGDByLabel(context.TODO())
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("findRGDByLabel() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err == nil && info.Name != tt.expectedName {
+				t.Errorf("findRGDByLabel() = %q, want %q", info.Name, tt.expectedName)
+			}
+		})
+	}
+}
+
+// TestValidateInstanceMetadata tests label and annotation validation against Kubernetes limits
+func TestValidateInstanceMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		expected    string
+	}{
+		{
+			name:        "Valid metadata",
+			labels:      map[string]string{rgdLabelKey: "test-scale-set"},
+			annotations: map[string]string{runnerMetadataAnnotation: "{}"},
+		},
+		{
+			name:     "Invalid label key",
+			labels:   map[string]string{"bad key!": "value"},
+			expected: "metadata.labels[bad key!]: invalid key",
+		},
+		{
+			name:     "Label value too long",
+			labels:   map[string]string{rgdLabelKey: strings.Repeat("a", 64)},
+			expected: "metadata.labels[actions.github.com/scale-set-name]: invalid value: must be no more than 63 characters",
+		},
+		{
+			name:        "Over-long annotation",
+			annotations: map[string]string{runnerMetadataAnnotation: strings.Repeat("a", 256*1024)},
+			expected:    "is larger than limit 262144",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInstanceMetadata(tt.labels, tt.annotations)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("validateInstanceMetadata() error = %v, want nil", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidMetadata) {
+				t.Fatalf("validateInstanceMetadata() error = %v, want %v", err, ErrInvalidMetadata)
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("validateInstanceMetadata() error = %q, want it to contain %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesInvalidMetadata tests that invalid metadata fails before the instance is created
+func TestCreateResourcesInvalidMetadata(t *testing.T) {
+	// Instance names may be up to 253 characters, but the runner name label value is capped at 63
+	runnerName := "test-runner-" + strings.Repeat("a", 60)
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	kubeClient := kubefake.NewClientset(newTestPod("default", runnerName))
+
+	r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set")
+	err := r.CreateResources(context.TODO(), runnerName, "test-config")
+	if !errors.Is(err, ErrInvalidMetadata) {
+		t.Fatalf("CreateResources() error = %v, want %v", err, ErrInvalidMetadata)
+	}
+	if !strings.Contains(err.Error(), runnerNameLabelKey) {
+		t.Errorf("CreateResources() error = %q, want it to name the label", err)
+	}
+
+	list, err := dynamicClient.Resource(testRGGVR).Namespace("default").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("instances = %d, want none created", len(list.Items))
+	}
+}
+
+// TestFindRGDByLabelSelection tests each strategy for picking among several matching RGDs
+func TestFindRGDByLabelSelection(t *testing.T) {
+	now := time.Now()
+	newRGD := func(name string, age time.Duration) *unstructured.Unstructured {
+		rgd := newTestRGD(name, "test-scale-set", "PodRunner")
+		rgd.SetCreationTimestamp(metav1.NewTime(now.Add(-age)))
+		return rgd
+	}
+	stable := newRGD("pod-runner-stable", time.Hour)
+	canary := newRGD("pod-runner-canary", time.Minute)
+	legacy := newRGD("pod-runner-legacy", 24*time.Hour)
+
+	tests := []struct {
+		name         string
+		strategy     string
+		rgds         []runtime.Object
+		expectedName string
+		expectErr    bool
+	}{
+		{name: "Default is strict", rgds: []runtime.Object{stable, canary}, expectErr: true},
+		{name: "Strict", strategy: RGDSelectionStrict, rgds: []runtime.Object{stable, canary}, expectErr: true},
+		{name: "Strict single match", strategy: RGDSelectionStrict, rgds: []runtime.Object{stable}, expectedName: "pod-runner-stable"},
+		{name: "Newest", strategy: RGDSelectionNewest, rgds: []runtime.Object{stable, canary, legacy}, expectedName: "pod-runner-canary"},
+		{name: "Oldest", strategy: RGDSelectionOldest, rgds: []runtime.Object{stable, canary, legacy}, expectedName: "pod-runner-legacy"},
+		{
+			name:         "Ties are broken by name",
+			strategy:     RGDSelectionOldest,
+			rgds:         []runtime.Object{newRGD("pod-runner-b", time.Hour), newRGD("pod-runner-a", time.Hour)},
+			expectedName: "pod-runner-a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewKRORunner("default", newFakeDynamicClient(tt.rgds...), nil, "test-scale-set",
+				WithRGDSelection(tt.strategy))
+
+			info, err := r.findRGDByLabel(context.TODO())
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("findRGDByLabel() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err == nil && info.Name != tt.expectedName {
+				t.Errorf("findRGDByLabel() = %q, want %q", info.Name, tt.expectedName)
+			}
+		})
+	}
+}
+
+// TestFindRGDByLabelInvalidKind tests rejecting RGDs whose schema kind is missing or empty
+func TestFindRGDByLabelInvalidKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		unset   bool
+		wantErr error
+	}{
+		{name: "Empty kind", kind: "", wantErr: ErrInvalidRGDKind},
+		{name: "Whitespace-only kind", kind: "  ", wantErr: ErrInvalidRGDKind},
+		{name: "Missing kind", unset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rgd := newTestRGD("pod-runner", "test-scale-set", tt.kind)
+			if tt.unset {
+				unstructured.RemoveNestedField(rgd.Object, "spec", "schema", "kind")
+			}
+			r := NewKRORunner("default", newFakeDynamicClient(rgd), nil, "test-scale-set")
+
+			_, err := r.findRGDByLabel(context.TODO())
+			if err == nil {
+				t.Fatal("findRGDByLabel() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("findRGDByLabel() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphFailedGrace tests transient FAILED states within the grace window
+func TestWaitForResourceGraphFailedGrace(t *testing.T) {
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+
+	tests := []struct {
+		name        string
+		grace       time.Duration
+		events      []*unstructured.Unstructured
+		expectedErr error
+	}{
+		{
+			name:        "No grace fails immediately",
+			events:      []*unstructured.Unstructured{withStatus(instance, "FAILED", false, "")},
+			expectedErr: ErrRunnerFailed,
+		},
+		{
+			name:  "Recovery within grace succeeds",
+			grace: time.Minute,
+			events: []*unstructured.Unstructured{
+				withStatus(instance, "FAILED", false, ""),
+				withStatus(instance, "ACTIVE", true, "Succeeded"),
+			},
+		},
+		{
+			name:        "Persistent failure fails after grace",
+			grace:       20 * time.Millisecond,
+			events:      []*unstructured.Unstructured{withStatus(instance, "FAILED", false, "")},
+			expectedErr: ErrRunnerFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, watcher, _ := newWatchedRunner(t, WithFailedGrace(tt.grace))
+			for _, event := range tt.events {
+				watcher.Modify(event)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := r.WaitForResourceGraph(ctx); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("WaitForResourceGraph() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphCancelAnnotation tests cancelling a runner by annotating its instance
+func TestWaitForResourceGraphCancelAnnotation(t *testing.T) {
+	r, watcher, dynamicClient := newWatchedRunner(t)
+
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+	watcher.Modify(withStatus(instance, "ACTIVE", false, ""))
+
+	cancelled := withStatus(instance, "ACTIVE", false, "")
+	annotations := cancelled.GetAnnotations()
+	annotations[cancelAnnotation] = "true"
+	cancelled.SetAnnotations(annotations)
+	watcher.Modify(cancelled)
+
+	if err := r.WaitForResourceGraph(context.TODO()); !errors.Is(err, ErrRunnerCancelled) {
+		t.Fatalf("WaitForResourceGraph() error = %v, want %v", err, ErrRunnerCancelled)
+	}
+
+	deleted := false
+	for _, action := range dynamicClient.Actions() {
+		if action.GetVerb() == "delete" && action.GetResource() == testRGGVR {
+			deleted = true
+		}
+	}
+	if !deleted {
+		t.Error("cancelled instance was not deleted")
+	}
+}
+
+// TestDeleteResourcesGracePeriod tests that the configured grace period is passed to the instance delete
+func TestDeleteResourcesGracePeriod(t *testing.T) {
+	tests := []struct {
+		name        string
+		gracePeriod *int64
+	}{
+		{name: "Server default", gracePeriod: nil},
+		{name: "Explicit grace period", gracePeriod: ptr.To(int64(120))},
+		{name: "Immediate deletion", gracePeriod: ptr.To(int64(0))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := newFakeDynamicClient(
+				newTestRGD("pod-runner", "test-scale-set", "PodRunner"),
+				newTestInstance("default", "test-runner", "test-scale-set"),
+			)
+			NewAppContext("test-runner", "")
+			t.Cleanup(func() { appContext = nil })
+
+			r := NewKRORunner("default", dynamicClient, nil, "test-scale-set", WithDeleteGracePeriod(tt.gracePeriod))
+			if err := r.DeleteResources(context.TODO()); err != nil {
+				t.Fatalf("DeleteResources() error = %v", err)
+			}
+
+			var deleteAction k8stesting.DeleteAction
+			for _, action := range dynamicClient.Actions() {
+				if a, ok := action.(k8stesting.DeleteAction); ok {
+					deleteAction = a
+				}
+			}
+			if deleteAction == nil {
+				t.Fatal("instance delete was not issued")
+			}
+
+			got := deleteAction.GetDeleteOptions().GracePeriodSeconds
+			if !reflect.DeepEqual(got, tt.gracePeriod) {
+				t.Errorf("GracePeriodSeconds = %v, want %v", ptr.Deref(got, -1), ptr.Deref(tt.gracePeriod, -1))
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphFailureConditions tests that conditions are snapshotted and logged on failure
+func TestWaitForResourceGraphFailureConditions(t *testing.T) {
+	logs := captureLogs(t)
+	r, watcher, _ := newWatchedRunner(t)
+
+	failed := withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "FAILED", false, "")
+	_ = unstructured.SetNestedSlice(failed.Object, []interface{}{
+		map[string]interface{}{
+			"type":               "InstanceSynced",
+			"status":             "False",
+			"reason":             "ReconcileError",
+			"message":            "pod admission denied",
+			"lastTransitionTime": "2024-01-01T00:00:00Z",
+		},
+	}, "status", "conditions")
+	watcher.Modify(failed)
+
+	if err := r.WaitForResourceGraph(context.TODO()); !errors.Is(err, ErrRunnerFailed) {
+		t.Fatalf("WaitForResourceGraph() error = %v, want %v", err, ErrRunnerFailed)
+	}
+
+	expected := []Condition{{
+		Type:               "InstanceSynced",
+		Status:             "False",
+		Reason:             "ReconcileError",
+		Message:            "pod admission denied",
+		LastTransitionTime: "2024-01-01T00:00:00Z",
+	}}
+	if !reflect.DeepEqual(r.Conditions(), expected) {
+		t.Errorf("Conditions() = %+v, want %+v", r.Conditions(), expected)
+	}
+	if !strings.Contains(logs.String(), `reason=ReconcileError message="pod admission denied"`) {
+		t.Errorf("failure conditions were not logged; logs:\n%s", logs.String())
+	}
+}
+
+// TestCreateResourcesStampCreator tests the orchestrator identity annotations
+func TestCreateResourcesStampCreator(t *testing.T) {
+	tests := []struct {
+		name         string
+		stampCreator bool
+		env          map[string]string
+		expected     map[string]string
+	}{
+		{
+			name:         "Downward API env takes precedence",
+			stampCreator: true,
+			env:          map[string]string{"POD_NAME": "env-pod", "NODE_NAME": "env-node", "POD_SERVICE_ACCOUNT": "env-sa"},
+			expected: map[string]string{
+				creatorPodAnnotation:            "env-pod",
+				creatorNodeAnnotation:           "env-node",
+				creatorServiceAccountAnnotation: "env-sa",
+			},
+		},
+		{
+			name:         "Fetched pod fills missing env",
+			stampCreator: true,
+			expected: map[string]string{
+				creatorPodAnnotation:            "test-runner",
+				creatorNodeAnnotation:           "node-1",
+				creatorServiceAccountAnnotation: "kar",
+			},
+		},
+		{
+			name:     "Disabled",
+			env:      map[string]string{"POD_NAME": "env-pod"},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"POD_NAME", "NODE_NAME", "POD_SERVICE_ACCOUNT"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			pod := newTestPod("default", "test-runner")
+			pod.Spec.NodeName = "node-1"
+			pod.Spec.ServiceAccountName = "kar"
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(pod), "test-scale-set", WithStampCreator(tt.stampCreator))
+			if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+
+			instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+				context.TODO(), "test-runner", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("instance was not created: %v", err)
+			}
+
+			annotations := instance.GetAnnotations()
+			if _, ok := annotations[runnerMetadataAnnotation]; !ok {
+				t.Error("runner metadata annotation is missing")
+			}
+			for _, key := range []string{creatorPodAnnotation, creatorNodeAnnotation, creatorServiceAccountAnnotation} {
+				if annotations[key] != tt.expected[key] {
+					t.Errorf("annotation %s = %q, want %q", key, annotations[key], tt.expected[key])
+				}
+			}
+		})
+	}
+}
+
+// dryRunRecordingClient records create options, which the fake dynamic client drops, and
+// answers dry-run creates itself since the fake tracker does not honour them
+type dryRunRecordingClient struct {
+	dynamic.Interface
+	dryRuns   []bool
+	rejectDry bool
+}
+
+func (c *dryRunRecordingClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &dryRunRecordingNamespaceable{NamespaceableResourceInterface: c.Interface.Resource(gvr), client: c}
+}
+
+type dryRunRecordingNamespaceable struct {
+	dynamic.NamespaceableResourceInterface
+	client *dryRunRecordingClient
+}
+
+func (n *dryRunRecordingNamespaceable) Namespace(namespace string) dynamic.ResourceInterface {
+	return &dryRunRecordingResource{ResourceInterface: n.NamespaceableResourceInterface.Namespace(namespace), client: n.client}
+}
+
+type dryRunRecordingResource struct {
+	dynamic.ResourceInterface
+	client *dryRunRecordingClient
+}
+
+func (r *dryRunRecordingResource) Create(ctx context.Context, obj *unstructured.Unstructured, opts metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	dryRun := len(opts.DryRun) > 0
+	r.client.dryRuns = append(r.client.dryRuns, dryRun)
+	if !dryRun {
+		return r.ResourceInterface.Create(ctx, obj, opts, subresources...)
+	}
+	if r.client.rejectDry {
+		return nil, k8serrors.NewBadRequest("admission webhook denied the request")
+	}
+	return obj, nil
+}
+
+// TestCreateResourcesValidate tests that --validate issues a dry-run create before the real one
+func TestCreateResourcesValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		validate    bool
+		rejectDry   bool
+		wantErr     bool
+		wantDryRuns []bool
+	}{
+		{
+			name:        "Dry run precedes create",
+			validate:    true,
+			wantDryRuns: []bool{true, false},
+		},
+		{
+			name:        "Rejected dry run skips create",
+			validate:    true,
+			rejectDry:   true,
+			wantErr:     true,
+			wantDryRuns: []bool{true},
+		},
+		{
+			name:        "Disabled",
+			wantDryRuns: []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := &dryRunRecordingClient{
+				Interface: newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner")),
+				rejectDry: tt.rejectDry,
+			}
+
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")),
+				"test-scale-set", WithValidate(tt.validate))
+			err := r.CreateResources(context.TODO(), "test-runner", "test-config")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateResources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(dynamicClient.dryRuns, tt.wantDryRuns) {
+				t.Errorf("creates dryRun = %v, want %v", dynamicClient.dryRuns, tt.wantDryRuns)
+			}
+		})
+	}
+}
+
+// withWaitingContainer adds a runner pod container status waiting for reason
+func withWaitingContainer(instance *unstructured.Unstructured, field, container, reason string) *unstructured.Unstructured {
+	instance = instance.DeepCopy()
+	_ = unstructured.SetNestedSlice(instance.Object, []interface{}{
+		map[string]interface{}{
+			"name":  container,
+			"state": map[string]interface{}{"waiting": map[string]interface{}{"reason": reason}},
+		},
+	}, "status", "resources", "runnerPod", "status", field)
+	return instance
+}
+
+// TestFatalWaitingReason tests detection of runner pod containers that cannot start
+func TestFatalWaitingReason(t *testing.T) {
+	base := withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", false, "Pending")
+
+	tests := []struct {
+		name     string
+		instance *unstructured.Unstructured
+		reasons  []string
+		expected string
+	}{
+		{
+			name:     "Image pull backoff",
+			instance: withWaitingContainer(base, "containerStatuses", "runner", "ImagePullBackOff"),
+			reasons:  DefaultFatalWaitingReasons,
+			expected: "container runner is waiting with reason ImagePullBackOff",
+		},
+		{
+			name:     "Crash looping init container",
+			instance: withWaitingContainer(base, "initContainerStatuses", "setup", "CrashLoopBackOff"),
+			reasons:  DefaultFatalWaitingReasons,
+			expected: "container setup is waiting with reason CrashLoopBackOff",
+		},
+		{
+			name:     "Benign waiting reason",
+			instance: withWaitingContainer(base, "containerStatuses", "runner", "ContainerCreating"),
+			reasons:  DefaultFatalWaitingReasons,
+		},
+		{
+			name:     "Custom reasons",
+			instance: withWaitingContainer(base, "containerStatuses", "runner", "CreateContainerConfigError"),
+			reasons:  []string{"CreateContainerConfigError"},
+			expected: "container runner is waiting with reason CreateContainerConfigError",
+		},
+		{
+			name:     "Detection disabled",
+			instance: withWaitingContainer(base, "containerStatuses", "runner", "ImagePullBackOff"),
+		},
+		{
+			name:     "No container statuses",
+			instance: base,
+			reasons:  DefaultFatalWaitingReasons,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fatalWaitingReason(tt.instance, tt.reasons)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("fatalWaitingReason() error = %v, want nil", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrRunnerPodStuck) {
+				t.Fatalf("fatalWaitingReason() error = %v, want %v", err, ErrRunnerPodStuck)
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("fatalWaitingReason() error = %q, want it to contain %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphFatalWaitingReason tests that a stuck runner pod fails the wait immediately
+func TestWaitForResourceGraphFatalWaitingReason(t *testing.T) {
+	r, watcher, _ := newWatchedRunner(t)
+
+	instance := withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", false, "Pending")
+	watcher.Modify(withWaitingContainer(instance, "containerStatuses", "runner", "ErrImagePull"))
+
+	if err := r.WaitForResourceGraph(context.TODO()); !errors.Is(err, ErrRunnerPodStuck) {
+		t.Errorf("WaitForResourceGraph() error = %v, want %v", err, ErrRunnerPodStuck)
+	}
+}
+
+// TestWaitForResourceGraphMissingPodStatus tests each policy for a ready instance whose status lacks resources
+func TestWaitForResourceGraphMissingPodStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		expectedErr error
+	}{
+		{name: "Default assumes success", policy: ""},
+		{name: "Assume success", policy: MissingPodStatusAssumeSuccess},
+		{name: "Assume failure", policy: MissingPodStatusAssumeFailure, expectedErr: ErrRunnerFailed},
+		{name: "Error", policy: MissingPodStatusError, expectedErr: ErrPodStatusMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, watcher, _ := newWatchedRunner(t, WithOnMissingPodStatus(tt.policy))
+
+			instance := withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", true, "")
+			if _, found, _ := unstructured.NestedFieldNoCopy(instance.Object, "status", "resources"); found {
+				t.Fatal("test instance unexpectedly has status.resources")
+			}
+			watcher.Modify(instance)
+
+			if err := r.WaitForResourceGraph(context.TODO()); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("WaitForResourceGraph() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+// TestIsRegistered tests truthiness of the registration status field
+func TestIsRegistered(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     []string
+		value    interface{}
+		expected bool
+	}{
+		{name: "No path configured", value: true, expected: false},
+		{name: "Field missing", path: []string{"status", "registered"}, expected: false},
+		{name: "Bool true", path: []string{"status", "registered"}, value: true, expected: true},
+		{name: "Bool false", path: []string{"status", "registered"}, value: false, expected: false},
+		{name: "String true", path: []string{"status", "registered"}, value: "True", expected: true},
+		{name: "String false", path: []string{"status", "registered"}, value: "false", expected: false},
+		{name: "Empty string", path: []string{"status", "registered"}, value: "", expected: false},
+		{name: "Non-zero number", path: []string{"status", "registered"}, value: int64(1), expected: true},
+		{name: "Zero number", path: []string{"status", "registered"}, value: int64(0), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rg := newTestInstance("default", "test-runner", "test-scale-set")
+			if tt.value != nil {
+				_ = unstructured.SetNestedField(rg.Object, tt.value, "status", "registered")
+			}
+
+			if got := isRegistered(rg, tt.path); got != tt.expected {
+				t.Errorf("isRegistered() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphRegistered tests logging the registration transition once, separately from completion
+func TestWaitForResourceGraphRegistered(t *testing.T) {
+	logs := captureLogs(t)
+	r, watcher, _ := newWatchedRunner(t, WithRegisteredStatusPath("status.registered"))
+
+	pending := withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", false, "Running")
+	registered := pending.DeepCopy()
+	_ = unstructured.SetNestedField(registered.Object, true, "status", "registered")
+	completed := withStatus(registered, "ACTIVE", true, "Succeeded")
+
+	watcher.Modify(pending)
+	watcher.Modify(registered)
+	watcher.Modify(registered)
+	watcher.Modify(completed)
+
+	if err := r.WaitForResourceGraph(context.TODO()); err != nil {
+		t.Fatalf("WaitForResourceGraph() error = %v", err)
+	}
+
+	out := logs.String()
+	if n := strings.Count(out, "runner registered with GitHub"); n != 1 {
+		t.Errorf("registration logged %d times, want 1:\n%s", n, out)
+	}
+	if !strings.Contains(out, "phase=registered subject=test-runner") {
+		t.Errorf("registered phase duration not logged:\n%s", out)
+	}
+	if strings.Index(out, "runner registered") > strings.Index(out, "phase=ready") {
+		t.Errorf("registration logged after completion:\n%s", out)
+	}
+}
+
+// TestWaitForResourceGraphTerminateOnFirstEvent tests returning after the first status-bearing event
+func TestWaitForResourceGraphTerminateOnFirstEvent(t *testing.T) {
+	r, watcher, _ := newWatchedRunner(t, WithTerminateOnFirstEvent(true))
+
+	// Events without a status are skipped; the first with a state ends the wait
+	watcher.Modify(newTestInstance("default", "test-runner", "test-scale-set"))
+	watcher.Modify(withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "IN_PROGRESS", false, ""))
+
+	if err := r.WaitForResourceGraph(context.TODO()); err != nil {
+		t.Fatalf("WaitForResourceGraph() error = %v", err)
+	}
+	if len(r.conditions) != 1 || r.conditions[0].Type != "ResourcesReady" {
+		t.Errorf("conditions = %v, want the observed ResourcesReady condition", r.conditions)
+	}
+}
+
+// TestResolveOrchestratorPodName tests the precedence of the flag, env var and runner name
+func TestResolveOrchestratorPodName(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     string
+		env      string
+		expected string
+	}{
+		{name: "Flag wins over env", flag: "flag-pod", env: "env-pod", expected: "flag-pod"},
+		{name: "Env wins over runner name", env: "env-pod", expected: "env-pod"},
+		{name: "Falls back to runner name", expected: "test-runner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(orchestratorPodNameEnv, tt.env)
+
+			if got := resolveOrchestratorPodName(tt.flag, "test-runner"); got != tt.expected {
+				t.Errorf("resolveOrchestratorPodName() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesOrchestratorPodName tests owning the instance by an explicitly named pod
+func TestCreateResourcesOrchestratorPodName(t *testing.T) {
+	t.Setenv(orchestratorPodNameEnv, "")
+
+	pod := newTestPod("default", "orchestrator")
+	pod.UID = "orchestrator-uid"
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(pod), "test-scale-set",
+		WithOrchestratorPodName("orchestrator"))
+
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+
+	instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+		context.TODO(), "test-runner", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("instance was not created: %v", err)
+	}
+
+	owners := instance.GetOwnerReferences()
+	if len(owners) != 1 || owners[0].Name != "orchestrator" || owners[0].UID != "orchestrator-uid" {
+		t.Errorf("owner references = %v, want pod orchestrator", owners)
+	}
+}
+
+// TestCreateResourcesCreatedRecord tests the structured record of the created instance
+func TestCreateResourcesCreatedRecord(t *testing.T) {
+	logs := captureLogs(t)
+
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	// The fake does not assign UIDs, so stamp one as the API server would
+	dynamicClient.PrependReactor("create", "podrunners", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		obj.SetUID("instance-uid")
+		return false, nil, nil
+	})
+
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set")
+	if r.Created() != nil {
+		t.Fatalf("Created() = %v before create, want nil", r.Created())
+	}
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+
+	expected := CreateResult{GVR: testRGGVR, Namespace: "default", Name: "test-runner", UID: "instance-uid"}
+	if got := r.Created(); got == nil || *got != expected {
+		t.Errorf("Created() = %v, want %v", got, expected)
+	}
+
+	record := "event=created group=kro.run version=v1alpha1 resource=podrunners namespace=default name=test-runner uid=instance-uid"
+	if !strings.Contains(logs.String(), record) {
+		t.Errorf("logs missing %q:\n%s", record, logs.String())
+	}
+}
+
+// TestCreateResourcesRunLabels tests labelling instances with the GitHub run ID and attempt
+func TestCreateResourcesRunLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected map[string]string
+	}{
+		{
+			name:     "Run ID and attempt set",
+			env:      map[string]string{"GITHUB_RUN_ID": "123456789", "GITHUB_RUN_ATTEMPT": "2"},
+			expected: map[string]string{runIDLabelKey: "123456789", runAttemptLabelKey: "2"},
+		},
+		{
+			name:     "Only run ID set",
+			env:      map[string]string{"GITHUB_RUN_ID": "123456789"},
+			expected: map[string]string{runIDLabelKey: "123456789"},
+		},
+		{
+			name:     "Unset",
+			expected: map[string]string{},
+		},
+		{
+			name:     "Invalid value is omitted",
+			env:      map[string]string{"GITHUB_RUN_ID": "not a label"},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_RUN_ID", tt.env["GITHUB_RUN_ID"])
+			t.Setenv("GITHUB_RUN_ATTEMPT", tt.env["GITHUB_RUN_ATTEMPT"])
+
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set")
+			if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+
+			instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+				context.TODO(), "test-runner", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("instance was not created: %v", err)
+			}
+
+			labels := instance.GetLabels()
+			for _, key := range []string{runIDLabelKey, runAttemptLabelKey} {
+				val, ok := labels[key]
+				want, wantOK := tt.expected[key]
+				if ok != wantOK || val != want {
+					t.Errorf("label %s = %q (present %v), want %q (present %v)", key, val, ok, want, wantOK)
+				}
+			}
+		})
+	}
+}
+
+// TestRunURL tests building the workflow run URL from the GitHub environment
+func TestRunURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "All set",
+			env:      map[string]string{"GITHUB_SERVER_URL": "https://github.com", "GITHUB_REPOSITORY": "octo/repo", "GITHUB_RUN_ID": "123"},
+			expected: "https://github.com/octo/repo/actions/runs/123",
+		},
+		{
+			name:     "GHES server with trailing slash",
+			env:      map[string]string{"GITHUB_SERVER_URL": "https://ghe.example.com/", "GITHUB_REPOSITORY": "octo/repo", "GITHUB_RUN_ID": "123"},
+			expected: "https://ghe.example.com/octo/repo/actions/runs/123",
+		},
+		{
+			name: "Missing run ID",
+			env:  map[string]string{"GITHUB_SERVER_URL": "https://github.com", "GITHUB_REPOSITORY": "octo/repo"},
+		},
+		{
+			name: "Unset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"GITHUB_SERVER_URL", "GITHUB_REPOSITORY", "GITHUB_RUN_ID"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			if got := runURL(); got != tt.expected {
+				t.Errorf("runURL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestDeleteResourcesUsesCachedRGD tests that cleanup deletes the instance without rediscovering the RGD
+func TestDeleteResourcesUsesCachedRGD(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	NewAppContext("test-runner", "")
+	t.Cleanup(func() { appContext = nil })
+
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set")
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+
+	// Discovery now fails, as it would with an expired cleanup context
+	dynamicClient.PrependReactor("list", "resourcegraphdefinitions", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, context.DeadlineExceeded
+	})
+
+	if err := r.DeleteResources(context.TODO()); err != nil {
+		t.Fatalf("DeleteResources() error = %v", err)
+	}
+
+	_, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(context.TODO(), "test-runner", metav1.GetOptions{})
+	if !k8serrors.IsNotFound(err) {
+		t.Errorf("instance still exists after cleanup: err = %v", err)
+	}
+}
+
+// TestDeleteResourcesPartialFailure tests that every cleanup step runs and the failed ones are returned together
+func TestDeleteResourcesPartialFailure(t *testing.T) {
+	forbidden := k8serrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "test-secret", errors.New("rbac"))
+
+	tests := []struct {
+		name             string
+		instanceErr      error
+		secretErr        error
+		expectedErrs     []string
+		expectedInstance bool
+	}{
+		{name: "All steps succeed"},
+		{
+			name:         "Secret fails, instance deleted",
+			secretErr:    forbidden,
+			expectedErrs: []string{"failed to delete JIT secret test-secret"},
+		},
+		{
+			name:             "Both fail",
+			instanceErr:      k8serrors.NewForbidden(testRGGVR.GroupResource(), "test-runner", errors.New("rbac")),
+			secretErr:        forbidden,
+			expectedErrs:     []string{"failed to delete ResourceGraph instance test-runner", "failed to delete JIT secret test-secret"},
+			expectedInstance: true,
+		},
+		{
+			name:      "Already deleted secret is not a failure",
+			secretErr: k8serrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "test-secret"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := newFakeDynamicClient(
+				newTestRGD("pod-runner", "test-scale-set", "PodRunner"),
+				newTestInstance("default", "test-runner", "test-scale-set"),
+			)
+			if tt.instanceErr != nil {
+				dynamicClient.PrependReactor("delete", "podrunners", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.instanceErr
+				})
+			}
+			kubeClient := kubefake.NewClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "default"}})
+			if tt.secretErr != nil {
+				kubeClient.PrependReactor("delete", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.secretErr
+				})
+			}
+
+			NewAppContext("test-runner", "test-secret")
+			t.Cleanup(func() { appContext = nil })
+
+			r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set")
+			err := r.DeleteResources(context.TODO())
+
+			if len(tt.expectedErrs) == 0 {
+				if err != nil {
+					t.Fatalf("DeleteResources() error = %v, want nil", err)
+				}
+			} else {
+				var aggregate utilerrors.Aggregate
+				if !errors.As(err, &aggregate) || len(aggregate.Errors()) != len(tt.expectedErrs) {
+					t.Fatalf("DeleteResources() error = %v, want %d joined errors", err, len(tt.expectedErrs))
+				}
+				for i, want := range tt.expectedErrs {
+					if !strings.Contains(aggregate.Errors()[i].Error(), want) {
+						t.Errorf("error %d = %q, want it to contain %q", i, aggregate.Errors()[i], want)
+					}
+				}
+				if !errors.Is(err, tt.secretErr) {
+					t.Errorf("DeleteResources() error = %v, want it to wrap %v", err, tt.secretErr)
+				}
+			}
+
+			_, getErr := dynamicClient.Resource(testRGGVR).Namespace("default").Get(context.TODO(), "test-runner", metav1.GetOptions{})
+			if exists := getErr == nil; exists != tt.expectedInstance {
+				t.Errorf("instance exists = %v, want %v", exists, tt.expectedInstance)
+			}
+		})
+	}
+}
+
+// TestDeleteResourcesWithoutInstance tests that cleanup is a no-op when no instance was created
+func TestDeleteResourcesWithoutInstance(t *testing.T) {
+	appContext = nil
+	t.Cleanup(func() { appContext = nil })
+
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	kubeClient := kubefake.NewClientset()
+	r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set")
+
+	if err := r.DeleteResources(context.TODO()); err != nil {
+		t.Fatalf("DeleteResources() error = %v", err)
+	}
+	if actions := dynamicClient.Actions(); len(actions) != 0 {
+		t.Errorf("dynamic client actions = %v, want none", actions)
+	}
+	if actions := kubeClient.Actions(); len(actions) != 0 {
+		t.Errorf("kube client actions = %v, want none", actions)
+	}
+}
+
+// TestWaitForResourceGraphReconnect tests re-establishing closed watches within the reconnect budget
+func TestWaitForResourceGraphReconnect(t *testing.T) {
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+
+	tests := []struct {
+		name          string
+		closedWatches int
+		expectedErr   error
+	}{
+		{name: "Reconnects after closed watches", closedWatches: 2},
+		{name: "Budget exhausted", closedWatches: 4, expectedErr: ErrWatchUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+			NewAppContext("test-runner", "")
+			t.Cleanup(func() { appContext = nil })
+
+			// Each watch closes immediately until closedWatches have been served, then the runner succeeds
+			watches := 0
+			dynamicClient.PrependWatchReactor("podrunners", func(k8stesting.Action) (bool, watch.Interface, error) {
+				watches++
+				watcher := watch.NewFakeWithChanSize(1, false)
+				if watches <= tt.closedWatches {
+					watcher.Stop()
+				} else {
+					watcher.Modify(withStatus(instance, "ACTIVE", true, "Succeeded"))
+				}
+				return true, watcher, nil
+			})
+
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set",
+				WithWatchReconnect(3, time.Millisecond))
+			err := r.WaitForResourceGraph(context.TODO())
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("WaitForResourceGraph() error = %v, want %v", err, tt.expectedErr)
+			}
+
+			wantWatches := tt.closedWatches + 1
+			if tt.expectedErr != nil {
+				wantWatches = 4
+			}
+			if watches != wantWatches {
+				t.Errorf("watches opened = %d, want %d", watches, wantWatches)
+			}
+			if !strings.Contains(logs.String(), "reconnect attempt 1/3") {
+				t.Errorf("reconnect attempt was not logged; logs:\n%s", logs.String())
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphWatchError tests re-listing after expired watches and failing on fatal watch errors
+func TestWaitForResourceGraphWatchError(t *testing.T) {
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+	expired := &metav1.Status{Status: metav1.StatusFailure, Code: http.StatusGone, Reason: metav1.StatusReasonExpired}
+	gone := &metav1.Status{Status: metav1.StatusFailure, Code: http.StatusGone, Reason: metav1.StatusReasonGone}
+	internalError := &metav1.Status{Status: metav1.StatusFailure, Code: http.StatusInternalServerError,
+		Reason: metav1.StatusReasonInternalError}
+
+	tests := []struct {
+		name        string
+		errors      []*metav1.Status
+		listed      *unstructured.Unstructured
+		wantWatches int
+		expectedErr error
+		errContains string
+	}{
+		{
+			name:        "Expired watch restarts and succeeds",
+			errors:      []*metav1.Status{expired},
+			wantWatches: 2,
+		},
+		{
+			name:        "Gone watch restarts and succeeds",
+			errors:      []*metav1.Status{gone, expired},
+			listed:      withStatus(instance, "ACTIVE", false, "Running"),
+			wantWatches: 3,
+		},
+		{
+			name:        "Re-list observes completion",
+			errors:      []*metav1.Status{expired},
+			listed:      withStatus(instance, "ACTIVE", true, "Succeeded"),
+			wantWatches: 1,
+		},
+		{
+			name:        "Repeated expiry exhausts the reconnect budget",
+			errors:      []*metav1.Status{expired, expired, expired, expired},
+			wantWatches: 4,
+			expectedErr: ErrWatchUnavailable,
+		},
+		{
+			name:        "Fatal watch error",
+			errors:      []*metav1.Status{internalError},
+			wantWatches: 1,
+			errContains: "watch error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			objects := []runtime.Object{newTestRGD("pod-runner", "test-scale-set", "PodRunner")}
+			if tt.listed != nil {
+				objects = append(objects, tt.listed)
+			}
+			dynamicClient := newFakeDynamicClient(objects...)
+			NewAppContext("test-runner", "")
+			t.Cleanup(func() { appContext = nil })
+
+			// Each watch delivers the next error until they run out, then the runner succeeds
+			watches := 0
+			dynamicClient.PrependWatchReactor("podrunners", func(k8stesting.Action) (bool, watch.Interface, error) {
+				watches++
+				watcher := watch.NewFakeWithChanSize(1, false)
+				if watches <= len(tt.errors) {
+					watcher.Error(tt.errors[watches-1])
+				} else {
+					watcher.Modify(withStatus(instance, "ACTIVE", true, "Succeeded"))
+				}
+				return true, watcher, nil
+			})
+
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set",
+				WithWatchReconnect(3, time.Millisecond))
+			err := r.WaitForResourceGraph(context.TODO())
+			switch {
+			case tt.errContains != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("WaitForResourceGraph() error = %v, want it to contain %q", err, tt.errContains)
+				}
+			case !errors.Is(err, tt.expectedErr):
+				t.Fatalf("WaitForResourceGraph() error = %v, want %v", err, tt.expectedErr)
+			}
+
+			if watches != tt.wantWatches {
+				t.Errorf("watches opened = %d, want %d", watches, tt.wantWatches)
+			}
+			if tt.errContains == "" && !strings.Contains(logs.String(), "re-listing") {
+				t.Errorf("re-list was not logged; logs:\n%s", logs.String())
+			}
+		})
+	}
+}
+
+// TestNamespaceRouting tests which namespace each operation uses when the scale set and runner namespaces differ
+func TestNamespaceRouting(t *testing.T) {
+	scaleSetRGD := newTestRGD("pod-runner", "test-scale-set", "PodRunner")
+	scaleSetRGD.SetNamespace("arc-systems")
+	otherRGD := newTestRGD("other-pod-runner", "test-scale-set", "PodRunner")
+	otherRGD.SetNamespace("other")
+
+	dynamicClient := newFakeDynamicClient(scaleSetRGD, otherRGD)
+	watcher := watch.NewFakeWithChanSize(1, false)
+	dynamicClient.PrependWatchReactor("podrunners", k8stesting.DefaultWatchReactor(watcher, nil))
+	kubeClient := kubefake.NewClientset(newTestPod("arc-runners", "test-runner"))
+
+	t.Cleanup(func() { appContext = nil })
+
+	r := NewKRORunner("arc-runners", dynamicClient, kubeClient, "test-scale-set", WithScaleSetNamespace("arc-systems"))
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+	watcher.Modify(withStatus(newTestInstance("arc-runners", "test-runner", "test-scale-set"), "ACTIVE", true, "Succeeded"))
+	if err := r.WaitForResourceGraph(context.TODO()); err != nil {
+		t.Fatalf("WaitForResourceGraph() error = %v", err)
+	}
+
+	// Create resets the app context, so name the JIT secret again for cleanup
+	NewAppContext("test-runner", "test-secret")
+	if err := r.DeleteResources(context.TODO()); err != nil {
+		t.Fatalf("DeleteResources() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"list/resourcegraphdefinitions": "arc-systems",
+		"create/podrunners":             "arc-runners",
+		"watch/podrunners":              "arc-runners",
+		"delete/podrunners":             "arc-runners",
+		"get/pods":                      "arc-runners",
+		"delete/secrets":                "arc-runners",
+	}
+
+	got := map[string]string{}
+	for _, action := range append(dynamicClient.Actions(), kubeClient.Actions()...) {
+		got[action.GetVerb()+"/"+action.GetResource().Resource] = action.GetNamespace()
+	}
+	for operation, namespace := range expected {
+		if got[operation] != namespace {
+			t.Errorf("%s namespace = %q, want %q", operation, got[operation], namespace)
+		}
+	}
+}
+
+// TestCreateResourcesAdoption tests adopting an instance that already exists only when its spec matches
+func TestCreateResourcesAdoption(t *testing.T) {
+	tests := []struct {
+		name         string
+		existingSpec map[string]interface{}
+		expectedErr  error
+	}{
+		{
+			name:         "Matching spec is adopted",
+			existingSpec: map[string]interface{}{"runnerName": "test-runner", "serverDefault": "kept"},
+		},
+		{
+			name:         "Mismatched runner name is refused",
+			existingSpec: map[string]interface{}{"runnerName": "another-runner"},
+			expectedErr:  ErrInstanceMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := newTestInstance("default", "test-runner", "test-scale-set")
+			existing.Object["spec"] = tt.existingSpec
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"), existing)
+			t.Cleanup(func() { appContext = nil })
+
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set",
+				WithOnConflict(ConflictAdopt))
+			err := r.CreateResources(context.TODO(), "test-runner", "test-config")
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("CreateResources() error = %v, want %v", err, tt.expectedErr)
+			}
+
+			instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+				context.TODO(), "test-runner", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get instance: %v", err)
+			}
+			spec, _, _ := unstructured.NestedMap(instance.Object, "spec")
+			if !reflect.DeepEqual(spec, tt.existingSpec) {
+				t.Errorf("existing spec was modified: got %v, want %v", spec, tt.existingSpec)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesOnConflict tests each policy for an instance that already exists under the runner name
+func TestCreateResourcesOnConflict(t *testing.T) {
+	previous := deletionPollInterval
+	deletionPollInterval = time.Millisecond
+	t.Cleanup(func() { deletionPollInterval = previous })
+
+	tests := []struct {
+		name           string
+		policy         string
+		expectedErr    error
+		expectedSpec   map[string]interface{}
+		expectReplaced bool
+	}{
+		{name: "Default fails", expectedErr: ErrInstanceExists, expectedSpec: map[string]interface{}{"runnerName": "test-runner", "old": "kept"}},
+		{name: "Adopt", policy: ConflictAdopt, expectedSpec: map[string]interface{}{"runnerName": "test-runner", "old": "kept"}},
+		{name: "Fail", policy: ConflictFail, expectedErr: ErrInstanceExists, expectedSpec: map[string]interface{}{"runnerName": "test-runner", "old": "kept"}},
+		{name: "Replace", policy: ConflictReplace, expectedSpec: map[string]interface{}{"runnerName": "test-runner"}, expectReplaced: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := newTestInstance("default", "test-runner", "test-scale-set")
+			existing.SetUID("old-uid")
+			existing.Object["spec"] = map[string]interface{}{"runnerName": "test-runner", "old": "kept"}
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"), existing)
+			t.Cleanup(func() { appContext = nil })
+
+			// Deletion is asynchronous: the old instance stays visible for two more reads
+			var deleting, pendingReads int
+			var createdWhileTerminating bool
+			dynamicClient.PrependReactor("delete", "podrunners", func(k8stesting.Action) (bool, runtime.Object, error) {
+				deleting, pendingReads = 1, 2
+				return true, nil, nil
+			})
+			dynamicClient.PrependReactor("get", "podrunners", func(k8stesting.Action) (bool, runtime.Object, error) {
+				if deleting == 0 {
+					return false, nil, nil
+				}
+				if pendingReads > 0 {
+					pendingReads--
+					return true, existing, nil
+				}
+				if deleting == 1 {
+					deleting = 2
+					_ = dynamicClient.Tracker().Delete(testRGGVR, "default", "test-runner")
+				}
+				return false, nil, nil
+			})
+			dynamicClient.PrependReactor("create", "podrunners", func(k8stesting.Action) (bool, runtime.Object, error) {
+				if deleting == 1 {
+					createdWhileTerminating = true
+				}
+				return false, nil, nil
+			})
+
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set",
+				WithOnConflict(tt.policy))
+			err := r.CreateResources(context.TODO(), "test-runner", "test-config")
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("CreateResources() error = %v, want %v", err, tt.expectedErr)
+			}
+			if createdWhileTerminating {
+				t.Error("instance was recreated before the old one was deleted")
+			}
+
+			instance, err := dynamicClient.Tracker().Get(testRGGVR, "default", "test-runner")
+			if err != nil {
+				t.Fatalf("failed to get instance: %v", err)
+			}
+			spec, _, _ := unstructured.NestedMap(instance.(*unstructured.Unstructured).Object, "spec")
+			if !reflect.DeepEqual(spec, tt.expectedSpec) {
+				t.Errorf("spec = %v, want %v", spec, tt.expectedSpec)
+			}
+			if replaced := deleting != 0; replaced != tt.expectReplaced {
+				t.Errorf("replaced = %v, want %v", replaced, tt.expectReplaced)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesJITReferenceStyle tests the shape of the jitConfigSecret spec field for each style
+func TestCreateResourcesJITReferenceStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    string
+		expected interface{}
+	}{
+		{
+			name:     "Default omits the reference",
+			style:    "",
+			expected: nil,
+		},
+		{
+			name:     "None omits the reference",
+			style:    JITReferenceNone,
+			expected: nil,
+		},
+		{
+			name:     "Name is the plain secret name",
+			style:    JITReferenceName,
+			expected: "test-runner",
+		},
+		{
+			name:     "SecretKeyRef is a map with name and key",
+			style:    JITReferenceSecretKeyRef,
+			expected: map[string]interface{}{"name": "test-runner", "key": ".jitconfig"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set",
+				WithJITReferenceStyle(tt.style))
+			if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+
+			instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+				context.TODO(), "test-runner", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("instance was not created: %v", err)
+			}
+
+			spec, _, _ := unstructured.NestedMap(instance.Object, "spec")
+			got, ok := spec["jitConfigSecret"]
+			if tt.expected == nil {
+				if ok {
+					t.Errorf("jitConfigSecret = %v, want it omitted", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("jitConfigSecret = %#v, want %#v", got, tt.expected)
+			}
+			if spec["runnerName"] != "test-runner" {
+				t.Errorf("runnerName = %v, want test-runner", spec["runnerName"])
+			}
+		})
+	}
+}
+
+// TestCreateResourcesRGDResourceName tests that the resource name override is used verbatim
+func TestCreateResourcesRGDResourceName(t *testing.T) {
+	// toResourceName would derive "proxys" for this kind
+	dynamicClient := newFakeDynamicClient(newTestRGD("proxy-runner", "test-scale-set", "Proxy"))
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set",
+		WithRGDResourceName("proxies"))
+
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+
+	if got := r.Created(); got == nil || got.GVR.Resource != "proxies" {
+		t.Fatalf("Created() = %v, want resource proxies", got)
+	}
+	gvr := schema.GroupVersionResource{Group: "kro.run", Version: "v1alpha1", Resource: "proxies"}
+	if _, err := dynamicClient.Resource(gvr).Namespace("default").Get(context.TODO(), "test-runner", metav1.GetOptions{}); err != nil {
+		t.Errorf("instance not created under the override resource: %v", err)
+	}
+}
+
+// TestCreateResourcesJITSecretName tests that a decoupled secret name is referenced while the instance
+// keeps the runner name, and that cleanup leaves the secret to ARC
+func TestCreateResourcesJITSecretName(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	kubeClient := kubefake.NewClientset(
+		newTestPod("default", "test-runner"),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-runner"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "arc-jit-secret"}},
+	)
+	t.Cleanup(func() { appContext = nil })
+
+	r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set",
+		WithJITReferenceStyle(JITReferenceSecretKeyRef), WithJITSecretName("arc-jit-secret"))
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+
+	instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+		context.TODO(), "test-runner", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("instance was not created under the runner name: %v", err)
+	}
+	if name, _, _ := unstructured.NestedString(instance.Object, "spec", "jitConfigSecret", "name"); name != "arc-jit-secret" {
+		t.Errorf("spec.jitConfigSecret.name = %q, want %q", name, "arc-jit-secret")
+	}
+	if runnerName, _, _ := unstructured.NestedString(instance.Object, "spec", "runnerName"); runnerName != "test-runner" {
+		t.Errorf("spec.runnerName = %q, want %q", runnerName, "test-runner")
+	}
+	if metadata, err := parseRunnerMetadata(instance); err != nil || metadata.JitConfigSecret != "arc-jit-secret" {
+		t.Errorf("runner metadata = %+v (%v), want jitConfigSecret arc-jit-secret", metadata, err)
+	}
+
+	if err := r.DeleteResources(context.TODO()); err != nil {
+		t.Fatalf("DeleteResources() error = %v", err)
+	}
+	if _, err := kubeClient.CoreV1().Secrets("default").Get(context.TODO(), "arc-jit-secret", metav1.GetOptions{}); err != nil {
+		t.Errorf("ARC's JIT secret was deleted on cleanup: %v", err)
+	}
+	if _, err := kubeClient.CoreV1().Secrets("default").Get(context.TODO(), "test-runner", metav1.GetOptions{}); err != nil {
+		t.Errorf("secret named after the runner was deleted: %v", err)
+	}
+}
+
+// TestWaitForResourceGraphWatchByLabel tests watching by runner name label and ignoring other matches
+func TestWaitForResourceGraphWatchByLabel(t *testing.T) {
+	r, watcher, dynamicClient := newWatchedRunner(t, WithWatchByLabel(true))
+
+	// A label match for another object must not decide the outcome
+	other := withStatus(newTestInstance("default", "other-runner", "test-scale-set"), "FAILED", false, "")
+	completed := withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", true, "Succeeded")
+	watcher.Modify(other)
+	watcher.Modify(completed)
+
+	if err := r.WaitForResourceGraph(context.TODO()); err != nil {
+		t.Fatalf("WaitForResourceGraph() error = %v", err)
+	}
+
+	for _, action := range dynamicClient.Actions() {
+		watchAction, ok := action.(k8stesting.WatchAction)
+		if !ok {
+			continue
+		}
+		restrictions := watchAction.GetWatchRestrictions()
+		if got := restrictions.Labels.String(); got != runnerNameLabelKey+"=test-runner" {
+			t.Errorf("watch label selector = %q, want %q", got, runnerNameLabelKey+"=test-runner")
+		}
+		if !restrictions.Fields.Empty() {
+			t.Errorf("watch field selector = %q, want none", restrictions.Fields)
+		}
+		return
+	}
+	t.Error("no watch was opened")
+}
+
+// TestWaitForResourceGraphResync tests that a terminal state only visible through the resync Get ends the wait
+func TestWaitForResourceGraphResync(t *testing.T) {
+	tests := []struct {
+		name        string
+		instance    *unstructured.Unstructured
+		expectedErr error
+	}{
+		{
+			name:     "Succeeded",
+			instance: withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", true, "Succeeded"),
+		},
+		{
+			name:        "Failed",
+			instance:    withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "FAILED", false, ""),
+			expectedErr: ErrRunnerFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, watcher, dynamicClient := newWatchedRunner(t, WithWatchResyncInterval(10*time.Millisecond))
+
+			// The watch only ever sees the runner start; the terminal transition is lost
+			watcher.Modify(withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", false, "Running"))
+			if _, err := dynamicClient.Resource(testRGGVR).Namespace("default").Create(
+				context.TODO(), tt.instance, metav1.CreateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err := r.WaitForResourceGraph(ctx)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("WaitForResourceGraph() error = %v, want %v", err, tt.expectedErr)
+			}
+			if ctx.Err() != nil {
+				t.Error("the wait ended on the test timeout instead of the resync")
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphMalformedStatus tests that repeated wrongly typed status fields fail the wait
+func TestWaitForResourceGraphMalformedStatus(t *testing.T) {
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+	wrongState := instance.DeepCopy()
+	_ = unstructured.SetNestedField(wrongState.Object, int64(3), "status", "state")
+	wrongConditions := instance.DeepCopy()
+	_ = unstructured.SetNestedField(wrongConditions.Object, "Ready", "status", "conditions")
+	completed := withStatus(instance, "ACTIVE", true, "Succeeded")
+
+	tests := []struct {
+		name        string
+		events      []*unstructured.Unstructured
+		expectedErr error
+	}{
+		{
+			name:        "Wrong-typed state fails after repeated reads",
+			events:      []*unstructured.Unstructured{wrongState, wrongState, wrongState},
+			expectedErr: ErrMalformedStatus,
+		},
+		{
+			name:        "Wrong-typed conditions fail after repeated reads",
+			events:      []*unstructured.Unstructured{wrongConditions, wrongState, wrongConditions},
+			expectedErr: ErrMalformedStatus,
+		},
+		{
+			name:   "Transient malformed status recovers",
+			events: []*unstructured.Unstructured{wrongState, wrongState, completed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			r, watcher, _ := newWatchedRunner(t)
+			for _, event := range tt.events {
+				watcher.Modify(event)
+			}
+
+			err := r.WaitForResourceGraph(context.TODO())
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("WaitForResourceGraph() error = %v, want %v", err, tt.expectedErr)
+			}
+			if !strings.Contains(logs.String(), "has a malformed status (1/3)") {
+				t.Errorf("expected a malformed status warning, got logs: %s", logs.String())
+			}
+		})
+	}
+}
+
+// TestRunnerMetadataSchemaVersion tests that new annotations carry the schema version and older ones still parse
+func TestRunnerMetadataSchemaVersion(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+	r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set")
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+	created, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(context.TODO(), "test-runner", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("instance was not created: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		annotation      string
+		expectedVersion int
+	}{
+		{name: "Created instance", annotation: created.GetAnnotations()[runnerMetadataAnnotation], expectedVersion: runnerMetadataSchemaVersion},
+		{name: "Unversioned annotation", annotation: `{"runnerName":"test-runner","scaleSetName":"test-scale-set"}`, expectedVersion: 0},
+		{
+			name:            "Newer version with unknown fields",
+			annotation:      `{"schemaVersion":2,"runnerName":"test-runner","scaleSetName":"test-scale-set","newField":true}`,
+			expectedVersion: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newTestInstance("default", "test-runner", "test-scale-set")
+			instance.SetAnnotations(map[string]string{runnerMetadataAnnotation: tt.annotation})
+
+			metadata, err := parseRunnerMetadata(instance)
+			if err != nil {
+				t.Fatalf("parseRunnerMetadata() error = %v", err)
+			}
+			if metadata.SchemaVersion != tt.expectedVersion {
+				t.Errorf("SchemaVersion = %d, want %d", metadata.SchemaVersion, tt.expectedVersion)
+			}
+			if metadata.RunnerName != "test-runner" || metadata.ScaleSetName != "test-scale-set" {
+				t.Errorf("metadata = %+v, want runner and scale set names", metadata)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesInstanceTTL tests the TTL annotation stamped for external TTL controllers
+func TestCreateResourcesInstanceTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		expected string
+	}{
+		{name: "Disabled", ttl: 0, expected: ""},
+		{name: "Whole seconds", ttl: 90 * time.Minute, expected: "5400"},
+		{name: "Sub-second rounds up", ttl: 1500 * time.Millisecond, expected: "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set",
+				WithInstanceTTL(tt.ttl))
+			if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+
+			instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+				context.TODO(), "test-runner", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("instance was not created: %v", err)
+			}
+
+			got, ok := instance.GetAnnotations()[ttlSecondsAnnotation]
+			if got != tt.expected || ok != (tt.expected != "") {
+				t.Errorf("%s = %q (present %v), want %q", ttlSecondsAnnotation, got, ok, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesCleanupTimeout tests the cleanup timeout annotation stamped for external reapers
+func TestCreateResourcesCleanupTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		stamp    bool
+		timeout  time.Duration
+		expected string
+	}{
+		{name: "Not stamped", stamp: false, timeout: 5 * time.Minute, expected: ""},
+		{name: "Stamped", stamp: true, timeout: 5 * time.Minute, expected: "300"},
+		{name: "No limit", stamp: true, timeout: 0, expected: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set",
+				WithCleanupTimeout(tt.timeout), WithStampCleanupTimeout(tt.stamp))
+			if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+
+			instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+				context.TODO(), "test-runner", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("instance was not created: %v", err)
+			}
+
+			got, ok := instance.GetAnnotations()[cleanupTimeoutAnnotation]
+			if got != tt.expected || ok != (tt.expected != "") {
+				t.Errorf("%s = %q (present %v), want %q", cleanupTimeoutAnnotation, got, ok, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// leaseDuration is how long the runner Lease stays valid without renewal; it is renewed every third of it
+var leaseDuration = 30 * time.Second
+
+// leaseReleaseTimeout bounds deleting the Lease, which may happen after the run context expired
+const leaseReleaseTimeout = 10 * time.Second
+
+// StartLease creates a coordination.k8s.io Lease named after the runner, taking over a stale one left by
+// a crashed orchestrator, and renews it until the returned release function deletes it
+func (r *KRORunner) StartLease(ctx context.Context, runnerName string) (func(), error) {
+	leases := r.kubeClient.CoordinationV1().Leases(r.namespace)
+	now := metav1.NewMicroTime(time.Now())
+
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: runnerName,
+			Labels: map[string]string{
+				rgdLabelKey:        r.scaleSetName,
+				runnerNameLabelKey: runnerName,
+			},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To(resolveOrchestratorPodName(r.orchestratorPodName, runnerName)),
+			LeaseDurationSeconds: ptr.To(int32(leaseDuration.Seconds())),
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	}
+
+	created, err := leases.Create(ctx, lease, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		var existing *coordinationv1.Lease
+		existing, err = leases.Get(ctx, runnerName, metav1.GetOptions{})
+		if err == nil {
+			log.Printf("Warning: taking over existing Lease %s held by %s", runnerName, ptr.Deref(existing.Spec.HolderIdentity, ""))
+			existing.Labels = lease.Labels
+			existing.Spec = lease.Spec
+			created, err = leases.Update(ctx, existing, metav1.UpdateOptions{})
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to acquire Lease %s", runnerName)
+	}
+	Infof("Acquired Lease %s", runnerName)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(leaseDuration / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				created.Spec.RenewTime = ptr.To(metav1.NewMicroTime(time.Now()))
+				renewed, err := leases.Update(context.WithoutCancel(ctx), created, metav1.UpdateOptions{})
+				if err != nil {
+					log.Printf("Warning: failed to renew Lease %s: %v", runnerName, err)
+					continue
+				}
+				created = renewed
+			}
+		}
+	}()
+
+	release := func() {
+		close(stop)
+		<-done
+
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), leaseReleaseTimeout)
+		defer cancel()
+
+		// The UID precondition leaves a Lease another orchestrator has since taken over
+		err := leases.Delete(releaseCtx, runnerName, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &created.UID},
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			log.Printf("Warning: failed to release Lease %s: %v", runnerName, err)
+			return
+		}
+		Infof("Released Lease %s", runnerName)
+	}
+
+	return release, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+// TestStartLease tests that the runner Lease is acquired, renewed and deleted on release
+func TestStartLease(t *testing.T) {
+	previous := leaseDuration
+	leaseDuration = 30 * time.Millisecond
+	t.Cleanup(func() { leaseDuration = previous })
+
+	stale := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-runner", Namespace: "default"},
+		Spec:       coordinationv1.LeaseSpec{HolderIdentity: ptr.To("crashed-pod")},
+	}
+
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+	}{
+		{name: "New lease"},
+		{name: "Stale lease is taken over", objects: []runtime.Object{stale}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := kubefake.NewClientset(tt.objects...)
+			r := NewKRORunner("default", nil, kubeClient, "test-scale-set", WithOrchestratorPodName("orchestrator-pod"))
+			leases := kubeClient.CoordinationV1().Leases("default")
+
+			release, err := r.StartLease(context.TODO(), "test-runner")
+			if err != nil {
+				t.Fatalf("StartLease() error = %v", err)
+			}
+
+			lease, err := leases.Get(context.TODO(), "test-runner", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("lease was not created: %v", err)
+			}
+			if holder := ptr.Deref(lease.Spec.HolderIdentity, ""); holder != "orchestrator-pod" {
+				t.Errorf("HolderIdentity = %q, want %q", holder, "orchestrator-pod")
+			}
+			if lease.Labels[runnerNameLabelKey] != "test-runner" {
+				t.Errorf("labels = %v, want the runner name label", lease.Labels)
+			}
+			acquired := lease.Spec.RenewTime.Time
+
+			time.Sleep(5 * leaseDuration)
+			lease, err = leases.Get(context.TODO(), "test-runner", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !lease.Spec.RenewTime.After(acquired) {
+				t.Errorf("RenewTime = %v, want it renewed after %v", lease.Spec.RenewTime, acquired)
+			}
+
+			release()
+			if _, err := leases.Get(context.TODO(), "test-runner", metav1.GetOptions{}); !k8serrors.IsNotFound(err) {
+				t.Errorf("lease still exists after release: %v", err)
+			}
+		})
+	}
+}
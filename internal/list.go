@@ -0,0 +1,171 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// listPageSize bounds the instances fetched per list request
+const listPageSize = 100
+
+// Outcomes of instances that have not finished
+const (
+	OutcomePending = "pending"
+	OutcomeRunning = "running"
+	OutcomeDeleted = "deleted"
+)
+
+// InstanceSummary describes a runner instance for fleet-wide listing
+type InstanceSummary struct {
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	Kind      string    `json:"kind"`
+	State     string    `json:"state"`
+	Outcome   string    `json:"outcome"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ListInstances lists the runner instances of every RGD kind matching the scale set,
+// sorted by kind and name
+func (r *KRORunner) ListInstances(ctx context.Context) ([]InstanceSummary, error) {
+	rgds, err := r.listRGDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := r.instanceSelector()
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []InstanceSummary
+	seen := map[string]bool{}
+	for i := range rgds {
+		info, err := newRGDInfo(&rgds[i])
+		if err != nil {
+			log.Printf("Warning: skipping RGD: %v", err)
+			continue
+		}
+		// Kinds sharing a resource (e.g. under --rgd-resource-name) are listed once
+		resource := r.resourceName(info.Kind)
+		if seen[resource] {
+			continue
+		}
+		seen[resource] = true
+
+		instances, err := r.listInstancesOfKind(ctx, info.Kind, selector)
+		if err != nil {
+			return nil, err
+		}
+		for j := range instances {
+			summaries = append(summaries, summarizeInstance(&instances[j], info.Kind))
+		}
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Kind != summaries[j].Kind {
+			return summaries[i].Kind < summaries[j].Kind
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+
+	return summaries, nil
+}
+
+// instanceSelector matches the scale set's instances that carry a runner name label
+func (r *KRORunner) instanceSelector() (string, error) {
+	hasRunnerName, err := labels.NewRequirement(runnerNameLabelKey, selection.Exists, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return labels.SelectorFromSet(labels.Set{rgdLabelKey: r.scaleSetName}).Add(*hasRunnerName).String(), nil
+}
+
+// listInstancesOfKind lists instances of kind page by page
+func (r *KRORunner) listInstancesOfKind(ctx context.Context, kind, selector string) ([]unstructured.Unstructured, error) {
+	rgGVR := r.kroGVR(r.resourceName(kind))
+
+	var items []unstructured.Unstructured
+	opts := metav1.ListOptions{LabelSelector: selector, Limit: listPageSize}
+	for {
+		page, err := r.dynamicClient.Resource(rgGVR).Namespace(r.namespace).List(ctx, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list %s instances", kind)
+		}
+		items = append(items, page.Items...)
+
+		if page.GetContinue() == "" {
+			return items, nil
+		}
+		opts.Continue = page.GetContinue()
+	}
+}
+
+// summarizeInstance reads the state and outcome from an instance's status
+func summarizeInstance(rg *unstructured.Unstructured, kind string) InstanceSummary {
+	state, _, _ := unstructured.NestedString(rg.Object, "status", "state")
+
+	return InstanceSummary{
+		Name:      rg.GetName(),
+		Namespace: rg.GetNamespace(),
+		Kind:      kind,
+		State:     state,
+		Outcome:   summaryOutcome(rg, state),
+		CreatedAt: rg.GetCreationTimestamp().Time,
+	}
+}
+
+// summaryOutcome maps the instance status to an outcome without logging, unlike readyOutcome
+func summaryOutcome(rg *unstructured.Unstructured, state string) string {
+	switch state {
+	case "":
+		return OutcomePending
+	case "FAILED":
+		return OutcomeFailed
+	case "DELETED":
+		return OutcomeDeleted
+	case "ACTIVE":
+	default:
+		return OutcomeRunning
+	}
+
+	for _, condition := range extractConditions(rg) {
+		if condition.Type != "ResourcesReady" || condition.Status != "True" {
+			continue
+		}
+
+		phase, _, _ := unstructured.NestedString(rg.Object, "status", "resources", "runnerPod", "status", "phase")
+		if phase == "Failed" {
+			return OutcomeFailed
+		}
+		// As in readyOutcome, a ready instance without a pod phase counts as succeeded
+		return OutcomeSucceeded
+	}
+
+	return OutcomeRunning
+}
@@ -0,0 +1,177 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// testVMRunnerGVR is the GVR of instances of a second RGD kind
+var testVMRunnerGVR = schema.GroupVersionResource{Group: "kro.run", Version: "v1alpha1", Resource: "vmrunners"}
+
+// TestListInstances tests listing runner instances across every RGD kind of the scale set
+func TestListInstances(t *testing.T) {
+	vmInstance := withStatus(newTestInstance("default", "vm-runner", "test-scale-set"), "ACTIVE", false, "")
+	vmInstance.SetKind("VMRunner")
+
+	unlabelled := newTestInstance("default", "unlabelled", "test-scale-set")
+	unlabelled.SetLabels(map[string]string{rgdLabelKey: "test-scale-set"})
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			testRGDGVR:      "ResourceGraphDefinitionList",
+			testRGGVR:       "PodRunnerList",
+			testVMRunnerGVR: "VMRunnerList",
+		},
+		newTestRGD("pod-runner", "test-scale-set", "PodRunner"),
+		newTestRGD("vm-runner", "test-scale-set", "VMRunner"),
+		newTestRGD("other", "other-scale-set", "OtherRunner"),
+		newTestInstance("default", "pending", "test-scale-set"),
+		withStatus(newTestInstance("default", "succeeded", "test-scale-set"), "ACTIVE", true, "Succeeded"),
+		withStatus(newTestInstance("default", "failed", "test-scale-set"), "ACTIVE", true, "Failed"),
+		withStatus(newTestInstance("default", "kro-failed", "test-scale-set"), "FAILED", false, ""),
+		newTestInstance("default", "other-scale-set", "other-scale-set"),
+		unlabelled,
+		vmInstance,
+	)
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set")
+
+	summaries, err := r.ListInstances(context.TODO())
+	if err != nil {
+		t.Fatalf("ListInstances() error = %v", err)
+	}
+
+	type row struct{ kind, name, state, outcome string }
+	var got []row
+	for _, s := range summaries {
+		got = append(got, row{s.Kind, s.Name, s.State, s.Outcome})
+	}
+	want := []row{
+		{"PodRunner", "failed", "ACTIVE", OutcomeFailed},
+		{"PodRunner", "kro-failed", "FAILED", OutcomeFailed},
+		{"PodRunner", "pending", "", OutcomePending},
+		{"PodRunner", "succeeded", "ACTIVE", OutcomeSucceeded},
+		{"VMRunner", "vm-runner", "ACTIVE", OutcomeRunning},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListInstances() = %v, want %v", got, want)
+	}
+}
+
+// pagingClient serves instance lists one item per page, since the fake dynamic client drops
+// limit and continue options, and records the options of each list request
+type pagingClient struct {
+	dynamic.Interface
+	lists []metav1.ListOptions
+}
+
+func (c *pagingClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &pagingNamespaceable{NamespaceableResourceInterface: c.Interface.Resource(gvr), client: c, gvr: gvr}
+}
+
+type pagingNamespaceable struct {
+	dynamic.NamespaceableResourceInterface
+	client *pagingClient
+	gvr    schema.GroupVersionResource
+}
+
+func (n *pagingNamespaceable) Namespace(namespace string) dynamic.ResourceInterface {
+	return &pagingResource{ResourceInterface: n.NamespaceableResourceInterface.Namespace(namespace), client: n.client, gvr: n.gvr}
+}
+
+type pagingResource struct {
+	dynamic.ResourceInterface
+	client *pagingClient
+	gvr    schema.GroupVersionResource
+}
+
+func (r *pagingResource) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	list, err := r.ResourceInterface.List(ctx, opts)
+	if err != nil || r.gvr != testRGGVR {
+		return list, err
+	}
+	r.client.lists = append(r.client.lists, opts)
+
+	offset := 0
+	if opts.Continue != "" {
+		offset, _ = strconv.Atoi(opts.Continue)
+	}
+	page := &unstructured.UnstructuredList{Object: list.Object}
+	if offset < len(list.Items) {
+		page.Items = list.Items[offset : offset+1]
+	}
+	if offset+1 < len(list.Items) {
+		page.SetContinue(strconv.Itoa(offset + 1))
+	}
+	return page, nil
+}
+
+// TestListInstancesPagination tests following continue tokens across list pages
+func TestListInstancesPagination(t *testing.T) {
+	dynamicClient := &pagingClient{Interface: newFakeDynamicClient(
+		newTestRGD("pod-runner", "test-scale-set", "PodRunner"),
+		newTestInstance("default", "runner-a", "test-scale-set"),
+		newTestInstance("default", "runner-b", "test-scale-set"),
+		newTestInstance("default", "runner-c", "test-scale-set"),
+	)}
+
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set")
+	summaries, err := r.ListInstances(context.TODO())
+	if err != nil {
+		t.Fatalf("ListInstances() error = %v", err)
+	}
+
+	var names []string
+	for _, s := range summaries {
+		names = append(names, s.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"runner-a", "runner-b", "runner-c"}) {
+		t.Errorf("ListInstances() names = %v, want [runner-a runner-b runner-c]", names)
+	}
+
+	if len(dynamicClient.lists) != 3 {
+		t.Fatalf("list requests = %d, want 3", len(dynamicClient.lists))
+	}
+	for i, opts := range dynamicClient.lists {
+		if opts.Limit != listPageSize {
+			t.Errorf("list %d limit = %d, want %d", i, opts.Limit, listPageSize)
+		}
+	}
+}
+
+// TestListInstancesCreatedAt tests that the creation timestamp is carried into the summary
+func TestListInstancesCreatedAt(t *testing.T) {
+	created := metav1.Date(2024, 6, 1, 12, 0, 0, 0, metav1.Now().Location())
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+	instance.SetCreationTimestamp(created)
+
+	summary := summarizeInstance(instance, "PodRunner")
+	if !summary.CreatedAt.Equal(created.Time) {
+		t.Errorf("CreatedAt = %v, want %v", summary.CreatedAt, created.Time)
+	}
+}
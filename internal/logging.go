@@ -0,0 +1,198 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// quiet suppresses routine progress logs, leaving warnings, errors and the final outcome
+var quiet atomic.Bool
+
+// SetQuiet enables or disables quiet mode
+func SetQuiet(enabled bool) {
+	quiet.Store(enabled)
+}
+
+// Quiet reports whether quiet mode is enabled
+func Quiet() bool {
+	return quiet.Load()
+}
+
+// Infof logs routine progress through the standard logger unless quiet mode is enabled
+func Infof(format string, args ...interface{}) {
+	if quiet.Load() {
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// logSampler logs one in every rate consecutive identical messages; a changed message is always logged
+type logSampler struct {
+	rate    int
+	last    string
+	repeats int
+}
+
+// newLogSampler returns a sampler logging one in every rate repeats, or every message when rate is at most 1
+func newLogSampler(rate int) *logSampler {
+	return &logSampler{rate: rate}
+}
+
+// Infof logs like Infof, dropping repeats of the previous message that fall outside the sample
+func (s *logSampler) Infof(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if message != s.last {
+		s.last = message
+		s.repeats = 0
+		Infof("%s", message)
+		return
+	}
+
+	s.repeats++
+	if s.rate <= 1 {
+		Infof("%s", message)
+		return
+	}
+	if s.repeats%s.rate == 0 {
+		Infof("%s (repeated %d times)", message, s.repeats)
+	}
+}
+
+// Timestamp formats for console log lines
+const (
+	// LogTimestampDefault keeps the standard logger's date and time
+	LogTimestampDefault = "default"
+	// LogTimestampRFC3339 prefixes lines with an RFC 3339 UTC timestamp
+	LogTimestampRFC3339 = "rfc3339"
+	// LogTimestampEpoch prefixes lines with Unix seconds with millisecond precision
+	LogTimestampEpoch = "epoch"
+	// LogTimestampNone omits the timestamp, for collectors that add their own
+	LogTimestampNone = "none"
+)
+
+// ErrInvalidLogTimestampFormat is returned for an unknown log timestamp format
+var ErrInvalidLogTimestampFormat = errors.New("invalid log timestamp format")
+
+// timestampWriter prefixes each standard logger entry with a timestamp in its own format
+type timestampWriter struct {
+	out    io.Writer
+	format func(time.Time) string
+}
+
+func (w *timestampWriter) Write(p []byte) (int, error) {
+	line := append([]byte(w.format(time.Now())+" "), p...)
+	if _, err := w.out.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// SetLogTimestampFormat sets how console log lines are timestamped: default, rfc3339, epoch or
+// none, matched case-insensitively. Call it before SetLogFile, whose NDJSON records keep their
+// own time field.
+func SetLogTimestampFormat(format string) error {
+	var stamp func(time.Time) string
+	switch strings.ToLower(format) {
+	case "", LogTimestampDefault:
+		return nil
+	case LogTimestampRFC3339:
+		stamp = func(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+	case LogTimestampEpoch:
+		stamp = func(t time.Time) string { return strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', 3, 64) }
+	case LogTimestampNone:
+	default:
+		return errors.Wrapf(ErrInvalidLogTimestampFormat, "format %q, expected default, rfc3339, epoch or none", format)
+	}
+
+	log.SetFlags(log.Flags() &^ (log.Ldate | log.Ltime | log.Lmicroseconds | log.LUTC))
+	if stamp != nil {
+		log.SetOutput(&timestampWriter{out: log.Writer(), format: stamp})
+	}
+	return nil
+}
+
+// ndjsonRecord is one line of the NDJSON log file
+type ndjsonRecord struct {
+	Time    string `json:"time"`
+	Message string `json:"msg"`
+}
+
+// teeWriter sends each standard logger entry to the console logger and as NDJSON to a file
+type teeWriter struct {
+	mu      sync.Mutex
+	console *log.Logger
+	file    io.Writer
+}
+
+func (w *teeWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_ = w.console.Output(2, msg)
+
+	line, err := json.Marshal(ndjsonRecord{Time: time.Now().UTC().Format(time.RFC3339Nano), Message: msg})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.file.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// SetLogFile additionally writes standard logger entries to path as NDJSON, while the
+// console output keeps its format. The returned function flushes and closes the file and
+// restores the standard logger.
+func SetLogFile(path string) (func() error, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open log file")
+	}
+
+	output, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+
+	// The console logger adds the timestamp and prefix, so the file gets the bare message
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(&teeWriter{console: log.New(output, prefix, flags), file: file})
+
+	return func() error {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+
+		if err := file.Sync(); err != nil {
+			_ = file.Close()
+			return errors.Wrap(err, "failed to flush log file")
+		}
+		return file.Close()
+	}, nil
+}
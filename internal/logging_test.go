@@ -0,0 +1,214 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useQuiet enables quiet mode for the duration of a test
+func useQuiet(t *testing.T) {
+	t.Helper()
+
+	SetQuiet(true)
+	t.Cleanup(func() { SetQuiet(false) })
+}
+
+// TestInfof tests that routine logs are suppressed only in quiet mode
+func TestInfof(t *testing.T) {
+	tests := []struct {
+		name     string
+		quiet    bool
+		expected bool
+	}{
+		{name: "Default", quiet: false, expected: true},
+		{name: "Quiet", quiet: true, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			SetQuiet(tt.quiet)
+			t.Cleanup(func() { SetQuiet(false) })
+
+			Infof("routine progress %d", 1)
+
+			if got := strings.Contains(logs.String(), "routine progress 1"); got != tt.expected {
+				t.Errorf("info log emitted = %v, want %v; logs:\n%s", got, tt.expected, logs.String())
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphQuiet tests that quiet mode drops per-transition logs but keeps failures
+func TestWaitForResourceGraphQuiet(t *testing.T) {
+	logs := captureLogs(t)
+	useQuiet(t)
+	r, watcher, _ := newWatchedRunner(t)
+
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+	watcher.Modify(withStatus(instance, "ACTIVE", false, ""))
+	watcher.Modify(withStatus(instance, "FAILED", false, ""))
+
+	if err := r.WaitForResourceGraph(context.TODO()); err != ErrRunnerFailed {
+		t.Fatalf("WaitForResourceGraph() error = %v, want %v", err, ErrRunnerFailed)
+	}
+
+	for _, routine := range []string{"Watching ResourceGraph instance", "state: ACTIVE", "Discovering RGD", "phase=active"} {
+		if strings.Contains(logs.String(), routine) {
+			t.Errorf("quiet mode logged %q; logs:\n%s", routine, logs.String())
+		}
+	}
+	if !strings.Contains(logs.String(), "ResourceGraph test-runner failed") {
+		t.Errorf("quiet mode dropped the failure; logs:\n%s", logs.String())
+	}
+}
+
+// TestWaitForResourceGraphLogSampling tests that repeated identical events are sampled while state changes are not
+func TestWaitForResourceGraphLogSampling(t *testing.T) {
+	tests := []struct {
+		name           string
+		rate           int
+		expectedActive int
+	}{
+		{name: "Unsampled", rate: 1, expectedActive: 8},
+		{name: "One in four", rate: 4, expectedActive: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			r, watcher, _ := newWatchedRunner(t, WithLogSampleRate(tt.rate))
+
+			instance := newTestInstance("default", "test-runner", "test-scale-set")
+			for i := 0; i < 8; i++ {
+				watcher.Modify(withStatus(instance, "ACTIVE", false, "Running"))
+			}
+			watcher.Modify(withStatus(instance, "FAILED", false, ""))
+
+			if err := r.WaitForResourceGraph(context.TODO()); err != ErrRunnerFailed {
+				t.Fatalf("WaitForResourceGraph() error = %v, want %v", err, ErrRunnerFailed)
+			}
+
+			if got := strings.Count(logs.String(), "state: ACTIVE"); got != tt.expectedActive {
+				t.Errorf("state: ACTIVE logged %d times, want %d; logs:\n%s", got, tt.expectedActive, logs.String())
+			}
+			for _, transition := range []string{"state: FAILED", "ResourceGraph test-runner failed"} {
+				if !strings.Contains(logs.String(), transition) {
+					t.Errorf("sampling dropped %q; logs:\n%s", transition, logs.String())
+				}
+			}
+		})
+	}
+}
+
+// TestSetLogFile tests that log entries reach both the console and the NDJSON file
+func TestSetLogFile(t *testing.T) {
+	console := captureLogs(t)
+	path := filepath.Join(t.TempDir(), "kar.ndjson")
+
+	closeLog, err := SetLogFile(path)
+	if err != nil {
+		t.Fatalf("SetLogFile() error = %v", err)
+	}
+
+	log.Printf("Warning: first entry")
+	Infof("second entry %d", 2)
+
+	if err := closeLog(); err != nil {
+		t.Fatalf("close log file error = %v", err)
+	}
+
+	// Entries after closing only reach the console
+	log.Printf("after close")
+
+	for _, msg := range []string{"Warning: first entry", "second entry 2", "after close"} {
+		if !strings.Contains(console.String(), msg) {
+			t.Errorf("console is missing %q:\n%s", msg, console.String())
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := []string{"Warning: first entry", "second entry 2"}
+	if len(lines) != len(want) {
+		t.Fatalf("log file has %d lines, want %d:\n%s", len(lines), len(want), data)
+	}
+	for i, line := range lines {
+		var record ndjsonRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("line %d is not JSON: %v: %s", i, err, line)
+		}
+		if record.Message != want[i] {
+			t.Errorf("line %d msg = %q, want %q", i, record.Message, want[i])
+		}
+		if _, err := time.Parse(time.RFC3339Nano, record.Time); err != nil {
+			t.Errorf("line %d time = %q is not RFC 3339: %v", i, record.Time, err)
+		}
+	}
+}
+
+// TestSetLogTimestampFormat tests that console log lines carry the configured timestamp format
+func TestSetLogTimestampFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		expected    *regexp.Regexp
+		expectedErr error
+	}{
+		{name: "Default", format: "", expected: regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} hello\n$`)},
+		{name: "RFC3339", format: "RFC3339", expected: regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z hello\n$`)},
+		{name: "Epoch", format: "epoch", expected: regexp.MustCompile(`^\d{10}\.\d{3} hello\n$`)},
+		{name: "None", format: "none", expected: regexp.MustCompile(`^hello\n$`)},
+		{name: "Unknown format", format: "iso", expectedErr: ErrInvalidLogTimestampFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			flags := log.Flags()
+			log.SetFlags(log.LstdFlags)
+			t.Cleanup(func() { log.SetFlags(flags) })
+
+			err := SetLogTimestampFormat(tt.format)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("SetLogTimestampFormat() error = %v, want %v", err, tt.expectedErr)
+			}
+			if err != nil {
+				return
+			}
+
+			log.Printf("hello")
+
+			if !tt.expected.MatchString(logs.String()) {
+				t.Errorf("log line %q does not match %s", logs.String(), tt.expected)
+			}
+		})
+	}
+}
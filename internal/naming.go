@@ -0,0 +1,47 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+)
+
+// deterministicNamePrefix starts every hashed instance name, keeping it a valid DNS label
+const deterministicNamePrefix = "kar-"
+
+// deterministicNameHashLength is the number of hex characters of the hash kept in the name
+const deterministicNameHashLength = 20
+
+// deterministicInstanceName derives a stable instance name from the scale set, runner name and
+// run ID, so the same logical runner always maps to the same instance
+func deterministicInstanceName(scaleSetName, runnerName, runID string) string {
+	// NUL separators keep ("ab", "c") and ("a", "bc") from hashing alike
+	sum := sha256.Sum256([]byte(strings.Join([]string{scaleSetName, runnerName, runID}, "\x00")))
+	return deterministicNamePrefix + hex.EncodeToString(sum[:])[:deterministicNameHashLength]
+}
+
+// instanceName returns the name of the instance created for runnerName: the runner name itself,
+// or with deterministic naming a hash including GITHUB_RUN_ID
+func (r *KRORunner) instanceName(runnerName string) string {
+	if !r.deterministicName {
+		return runnerName
+	}
+	return deterministicInstanceName(r.scaleSetName, runnerName, strings.TrimSpace(os.Getenv("GITHUB_RUN_ID")))
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// TestDeterministicInstanceName tests that the same inputs yield the same valid name and different inputs do not
+func TestDeterministicInstanceName(t *testing.T) {
+	name := deterministicInstanceName("test-scale-set", "test-runner", "12345")
+
+	if again := deterministicInstanceName("test-scale-set", "test-runner", "12345"); again != name {
+		t.Errorf("deterministicInstanceName() = %q then %q for the same inputs", name, again)
+	}
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		t.Errorf("deterministicInstanceName() = %q is not a DNS label: %v", name, errs)
+	}
+
+	tests := []struct {
+		name                    string
+		scaleSet, runner, runID string
+	}{
+		{name: "Different scale set", scaleSet: "other-scale-set", runner: "test-runner", runID: "12345"},
+		{name: "Different runner", scaleSet: "test-scale-set", runner: "other-runner", runID: "12345"},
+		{name: "Different run ID", scaleSet: "test-scale-set", runner: "test-runner", runID: "67890"},
+		{name: "No run ID", scaleSet: "test-scale-set", runner: "test-runner"},
+		{name: "Shifted boundary", scaleSet: "test-scale-settest-runner", runID: "12345"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deterministicInstanceName(tt.scaleSet, tt.runner, tt.runID); got == name {
+				t.Errorf("deterministicInstanceName() = %q, want a name different from the baseline", got)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesDeterministicName tests that the instance is named by hash, records the runner
+// name and is adopted when created again for the same run
+func TestCreateResourcesDeterministicName(t *testing.T) {
+	t.Setenv("GITHUB_RUN_ID", "12345")
+	t.Cleanup(func() { appContext = nil })
+
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+	expectedName := deterministicInstanceName("test-scale-set", "test-runner", "12345")
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set",
+			WithDeterministicName(true), WithOnConflict(ConflictAdopt))
+		if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+			t.Fatalf("CreateResources() attempt %d error = %v", attempt, err)
+		}
+		if got := GetAppContext().GetVMIName(); got != expectedName {
+			t.Errorf("attempt %d tracks instance %q, want %q", attempt, got, expectedName)
+		}
+	}
+
+	instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+		context.TODO(), expectedName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("instance %s was not created: %v", expectedName, err)
+	}
+	if got := instance.GetAnnotations()[runnerNameAnnotation]; got != "test-runner" {
+		t.Errorf("%s annotation = %q, want test-runner", runnerNameAnnotation, got)
+	}
+	if got, _, _ := unstructured.NestedString(instance.Object, "spec", "runnerName"); got != "test-runner" {
+		t.Errorf("spec.runnerName = %q, want test-runner", got)
+	}
+}
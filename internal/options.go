@@ -0,0 +1,384 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"strings"
+	"time"
+)
+
+// Option configures optional behaviour of a KRORunner
+type Option func(*KRORunner)
+
+// Configure applies options to an already constructed runner
+func (r *KRORunner) Configure(opts ...Option) {
+	for _, opt := range opts {
+		opt(r)
+	}
+}
+
+// WithPrintSpec logs the (redacted) instance spec right before it is created
+func WithPrintSpec(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.printSpec = enabled
+	}
+}
+
+// WithAllowedKinds restricts the RGD kinds the runner may instantiate
+func WithAllowedKinds(kinds []string) Option {
+	return func(r *KRORunner) {
+		r.allowedKinds = kinds
+	}
+}
+
+// WithResume reattaches to an instance created by a previous orchestrator run
+func WithResume(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.resume = enabled
+	}
+}
+
+// WithGetOrCreate looks the instance up by name before creating it and reuses it when it
+// belongs to the same scale set, so a reattach never attempts the create
+func WithGetOrCreate(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.getOrCreate = enabled
+	}
+}
+
+// WithDeterministicName names the instance after a hash of the scale set, runner name and
+// GITHUB_RUN_ID instead of the runner name, so retries of one logical runner map to one instance
+func WithDeterministicName(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.deterministicName = enabled
+	}
+}
+
+// WithCreateTimeout bounds the create phase API calls (zero disables the bound)
+func WithCreateTimeout(timeout time.Duration) Option {
+	return func(r *KRORunner) {
+		r.createTimeout = timeout
+	}
+}
+
+// WithStartupRetryBudget caps the cumulative backoff spent retrying discovery and create (zero leaves retries unbounded)
+func WithStartupRetryBudget(budget time.Duration) Option {
+	return func(r *KRORunner) {
+		r.startupRetryBudget = budget
+	}
+}
+
+// WithWatchByLabel watches the instance by its runner name label instead of a metadata.name field selector
+func WithWatchByLabel(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.watchByLabel = enabled
+	}
+}
+
+// WithJITSecretName names ARC's JIT secret when it differs from the runner name; ARC owns the secret, so cleanup keeps it
+func WithJITSecretName(name string) Option {
+	return func(r *KRORunner) {
+		r.jitSecretName = name
+	}
+}
+
+// WithOnConflict sets the policy for an instance that already exists: fail, adopt or replace
+func WithOnConflict(policy string) Option {
+	return func(r *KRORunner) {
+		r.onConflict = policy
+	}
+}
+
+// WithRGDSelection sets how discovery picks an RGD when several match: strict, newest or oldest
+func WithRGDSelection(strategy string) Option {
+	return func(r *KRORunner) {
+		r.rgdSelection = strategy
+	}
+}
+
+// WithWatchPodFallback watches the pod named by the instance's status.runnerPodName for its phase
+// when the status does not report it under status.resources.runnerPod
+func WithWatchPodFallback(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.watchPodFallback = enabled
+	}
+}
+
+// WithOnMissingPodStatus sets the outcome of a ready instance without a runner pod phase:
+// assume-success, assume-failure or error
+func WithOnMissingPodStatus(policy string) Option {
+	return func(r *KRORunner) {
+		r.onMissingPodStatus = policy
+	}
+}
+
+// WithRunnerLabels passes GitHub runner labels to the RGD under spec.runnerLabels
+func WithRunnerLabels(labels []string) Option {
+	return func(r *KRORunner) {
+		r.runnerLabels = labels
+	}
+}
+
+// WithRunnerGroup passes the GitHub runner group to the RGD under spec.runnerGroup
+func WithRunnerGroup(group string) Option {
+	return func(r *KRORunner) {
+		r.runnerGroup = group
+	}
+}
+
+// WithImagePullSecrets passes image pull secret names to the RGD under spec.imagePullSecrets
+func WithImagePullSecrets(names []string) Option {
+	return func(r *KRORunner) {
+		r.imagePullSecrets = names
+	}
+}
+
+// WithScheduling passes node selector and tolerations to the RGD under spec.scheduling
+func WithScheduling(scheduling *Scheduling) Option {
+	return func(r *KRORunner) {
+		r.scheduling = scheduling
+	}
+}
+
+// WithFailureEvents logs up to n of the instance's most recent events when the runner fails or times out
+func WithFailureEvents(n int, includeRelated bool) Option {
+	return func(r *KRORunner) {
+		r.failureEvents = n
+		r.failureEventsIncludeRelated = includeRelated
+	}
+}
+
+// WithCompletionExprs decides completion and failure with CEL expressions over the instance status
+func WithCompletionExprs(exprs *CompletionExprs) Option {
+	return func(r *KRORunner) {
+		r.completionExprs = exprs
+	}
+}
+
+// WithSpecOverrides merges additional fields into the generated instance spec
+func WithSpecOverrides(overrides map[string]interface{}) Option {
+	return func(r *KRORunner) {
+		r.specOverrides = overrides
+	}
+}
+
+// WithSpecTemplate renders the base instance spec from tmpl instead of passing only the runner name
+func WithSpecTemplate(tmpl *SpecTemplate) Option {
+	return func(r *KRORunner) {
+		r.specTemplate = tmpl
+	}
+}
+
+// WithRGDExtraSelector adds label requirements to RGD discovery alongside the scale set label
+func WithRGDExtraSelector(selector map[string]string) Option {
+	return func(r *KRORunner) {
+		r.rgdExtraSelector = selector
+	}
+}
+
+// WithRGDResourceName uses resource verbatim as the instance's plural resource name instead of deriving it from the kind
+func WithRGDResourceName(resource string) Option {
+	return func(r *KRORunner) {
+		r.rgdResourceName = resource
+	}
+}
+
+// WithFailedGrace waits up to grace for a FAILED instance to recover before concluding failure
+func WithFailedGrace(grace time.Duration) Option {
+	return func(r *KRORunner) {
+		r.failedGrace = grace
+	}
+}
+
+// WithDeleteGracePeriod sets the grace period in seconds used when deleting the instance
+func WithDeleteGracePeriod(seconds *int64) Option {
+	return func(r *KRORunner) {
+		r.deleteGracePeriod = seconds
+	}
+}
+
+// WithClusterInfo records the resolved kubeconfig context and API server for diagnostics
+func WithClusterInfo(kubeContext, server string) Option {
+	return func(r *KRORunner) {
+		r.kubeContext = kubeContext
+		r.kubeServer = server
+	}
+}
+
+// WithCleanupTimeout records how long the orchestrator allows for cleanup (zero means no limit)
+func WithCleanupTimeout(timeout time.Duration) Option {
+	return func(r *KRORunner) {
+		r.cleanupTimeout = timeout
+	}
+}
+
+// WithStampCleanupTimeout annotates instances with the cleanup timeout so external reapers can
+// wait as long as kar does for a graceful teardown
+func WithStampCleanupTimeout(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.stampCleanupTimeout = enabled
+	}
+}
+
+// WithStampCreator annotates instances with the identity of the orchestrator pod that created them
+func WithStampCreator(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.stampCreator = enabled
+	}
+}
+
+// WithInformer waits for the instance through a shared informer over the scale set instead of a single watch
+func WithInformer(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.useInformer = enabled
+	}
+}
+
+// WithValidate issues a server-side dry-run create so admission errors surface before the real create
+func WithValidate(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.validate = enabled
+	}
+}
+
+// WithInstanceTTL stamps the instance with a TTL in seconds for an external TTL controller to honour
+func WithInstanceTTL(ttl time.Duration) Option {
+	return func(r *KRORunner) {
+		r.instanceTTL = ttl
+	}
+}
+
+// WithMaxRuntime bounds how long the runner may run and stamps the instance with an expiry
+func WithMaxRuntime(d time.Duration) Option {
+	return func(r *KRORunner) {
+		r.maxRuntime = d
+	}
+}
+
+// WithFatalWaitingReasons sets the container waiting reasons that fail the runner immediately, empty disables detection
+func WithFatalWaitingReasons(reasons []string) Option {
+	return func(r *KRORunner) {
+		r.fatalWaitingReasons = reasons
+	}
+}
+
+// WithWatchReconnect caps consecutive watch reconnect attempts and the interval between them
+func WithWatchReconnect(maxAttempts int, interval time.Duration) Option {
+	return func(r *KRORunner) {
+		r.maxWatchReconnects = maxAttempts
+		r.watchReconnectInterval = interval
+	}
+}
+
+// WithWatchResyncInterval re-reads the instance at interval alongside the watch, zero disables it
+func WithWatchResyncInterval(interval time.Duration) Option {
+	return func(r *KRORunner) {
+		r.watchResyncInterval = interval
+	}
+}
+
+// WithLogSampleRate logs one in every rate identical per-event watch lines, 1 logs them all
+func WithLogSampleRate(rate int) Option {
+	return func(r *KRORunner) {
+		r.logSampleRate = rate
+	}
+}
+
+// WithStatusFile writes the live runner status as JSON to path on each observed instance
+func WithStatusFile(path string) Option {
+	return func(r *KRORunner) {
+		r.statusFile = path
+	}
+}
+
+// WithScaleSetNamespace discovers RGDs in namespace instead of cluster-wide; instances stay in the runner namespace
+func WithScaleSetNamespace(namespace string) Option {
+	return func(r *KRORunner) {
+		r.scaleSetNamespace = namespace
+	}
+}
+
+// WithKROAPIVersion pins the KRO API version instead of discovering the server's preferred version
+func WithKROAPIVersion(version string) Option {
+	return func(r *KRORunner) {
+		r.kroAPIVersionMu.Lock()
+		defer r.kroAPIVersionMu.Unlock()
+		r.kroAPIVersion = version
+	}
+}
+
+// WithJITReferenceStyle shapes how the instance spec references the JIT secret
+func WithJITReferenceStyle(style string) Option {
+	return func(r *KRORunner) {
+		r.jitReferenceStyle = style
+	}
+}
+
+// WithRegisteredStatusPath logs when the dot-separated instance field (e.g. status.registered) becomes truthy
+func WithRegisteredStatusPath(path string) Option {
+	return func(r *KRORunner) {
+		if path == "" {
+			r.registeredStatusPath = nil
+			return
+		}
+		r.registeredStatusPath = strings.Split(path, ".")
+	}
+}
+
+// WithTerminateOnFirstEvent returns from WaitForResourceGraph after the first status-bearing event; debug only
+func WithTerminateOnFirstEvent(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.terminateOnFirstEvent = enabled
+	}
+}
+
+// WithOrchestratorPodName sets the pod used as the instance owner, overriding KAR_ORCHESTRATOR_POD_NAME and the runner name
+func WithOrchestratorPodName(name string) Option {
+	return func(r *KRORunner) {
+		r.orchestratorPodName = name
+	}
+}
+
+// WithCleanupConcurrency bounds how many expired instances CleanupExpired deletes in parallel
+func WithCleanupConcurrency(n int) Option {
+	return func(r *KRORunner) {
+		r.cleanupConcurrency = n
+	}
+}
+
+// WithWaitActiveOnCreate makes CreateResources wait, within the create timeout, for the instance to become ACTIVE
+func WithWaitActiveOnCreate(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.waitActiveOnCreate = enabled
+	}
+}
+
+// WithCheckKRO checks the KRO controller Deployment namespace/name is available before creating the instance
+func WithCheckKRO(enabled bool, namespace, name string) Option {
+	return func(r *KRORunner) {
+		r.checkKRO = enabled
+		r.kroControllerNamespace = namespace
+		r.kroControllerName = name
+	}
+}
+
+// WithCheckQuota checks the namespace's ResourceQuotas before creating the instance
+func WithCheckQuota(enabled bool) Option {
+	return func(r *KRORunner) {
+		r.checkQuotaBeforeCreate = enabled
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// runnerPodName returns the runner pod name an RGD exposes under status.runnerPodName, or ""
+func runnerPodName(rg *unstructured.Unstructured) string {
+	name, _, _ := unstructured.NestedString(rg.Object, "status", "runnerPodName")
+	return name
+}
+
+// runnerPodPhase returns the runner pod phase from the instance's per-resource status, or ""
+func runnerPodPhase(rg *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(rg.Object, "status", "resources", "runnerPod", "status", "phase")
+	return phase
+}
+
+// watchPodPhase watches the named pod and delivers its phase once it is Succeeded or Failed.
+// The channel is closed without a phase if the watch fails or ends first.
+func (r *KRORunner) watchPodPhase(ctx context.Context, podName string) <-chan corev1.PodPhase {
+	phases := make(chan corev1.PodPhase, 1)
+
+	go func() {
+		defer close(phases)
+
+		watcher, err := r.kubeClient.CoreV1().Pods(r.namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("metadata.name", podName).String(),
+		})
+		if err != nil {
+			log.Printf("Warning: failed to watch runner pod %s: %v", podName, err)
+			return
+		}
+		defer watcher.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					log.Printf("Warning: watch for runner pod %s closed", podName)
+					return
+				}
+				if event.Type == watch.Error {
+					log.Printf("Warning: watch for runner pod %s failed: %v", podName, event.Object)
+					return
+				}
+
+				pod, ok := event.Object.(*corev1.Pod)
+				if !ok || pod.Name != podName {
+					continue
+				}
+				if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+					phases <- pod.Status.Phase
+					return
+				}
+			}
+		}
+	}()
+
+	return phases
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// withPhase returns a copy of pod in phase
+func withPhase(pod *corev1.Pod, phase corev1.PodPhase) *corev1.Pod {
+	pod = pod.DeepCopy()
+	pod.Status.Phase = phase
+	return pod
+}
+
+// TestWaitForResourceGraphWatchPodFallback tests that the runner pod named in the status decides
+// completion when the status lacks its phase
+func TestWaitForResourceGraphWatchPodFallback(t *testing.T) {
+	tests := []struct {
+		name          string
+		fallback      bool
+		statusPhase   string
+		podPhases     []corev1.PodPhase
+		expectedErr   error
+		expectedWatch bool
+	}{
+		{
+			name:          "Pod transitions to Succeeded",
+			fallback:      true,
+			podPhases:     []corev1.PodPhase{corev1.PodPending, corev1.PodRunning, corev1.PodSucceeded},
+			expectedWatch: true,
+		},
+		{
+			name:          "Pod transitions to Failed",
+			fallback:      true,
+			podPhases:     []corev1.PodPhase{corev1.PodRunning, corev1.PodFailed},
+			expectedErr:   ErrRunnerFailed,
+			expectedWatch: true,
+		},
+		{
+			name:        "Status reports the phase",
+			fallback:    true,
+			statusPhase: "Succeeded",
+			podPhases:   []corev1.PodPhase{corev1.PodFailed},
+		},
+		{
+			name:        "Fallback disabled",
+			podPhases:   []corev1.PodPhase{corev1.PodSucceeded},
+			expectedErr: ErrPodStatusMissing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, watcher, _ := newWatchedRunner(t, WithWatchPodFallback(tt.fallback), WithOnMissingPodStatus(MissingPodStatusError))
+
+			podWatcher := watch.NewFakeWithChanSize(10, false)
+			podWatches := 0
+			r.kubeClient.(*kubefake.Clientset).PrependWatchReactor("pods", func(_ k8stesting.Action) (bool, watch.Interface, error) {
+				podWatches++
+				return true, podWatcher, nil
+			})
+			pod := newTestPod("default", "test-runner-job")
+			for _, phase := range tt.podPhases {
+				podWatcher.Modify(withPhase(pod, phase))
+			}
+
+			instance := withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", true, tt.statusPhase)
+			_ = unstructured.SetNestedField(instance.Object, "test-runner-job", "status", "runnerPodName")
+			watcher.Modify(instance)
+
+			if err := r.WaitForResourceGraph(context.TODO()); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("WaitForResourceGraph() error = %v, want %v", err, tt.expectedErr)
+			}
+			if watched := podWatches > 0; watched != tt.expectedWatch {
+				t.Errorf("runner pod watched = %v, want %v", watched, tt.expectedWatch)
+			}
+		})
+	}
+}
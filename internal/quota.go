@@ -0,0 +1,128 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotation on an RGD listing the resources one runner instance consumes, e.g. "pods=1,cpu=2,memory=4Gi"
+const expectedResourcesAnnotation = "actions.github.com/expected-resources"
+
+// ErrQuotaExceeded is returned when a new runner would not fit the namespace's ResourceQuota
+var ErrQuotaExceeded = errors.New("namespace resource quota exceeded")
+
+// defaultExpectedResources applies when the RGD does not declare its resources: each runner runs one pod
+var defaultExpectedResources = corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")}
+
+// parseExpectedResources parses a comma-separated name=quantity list
+func parseExpectedResources(value string) (corev1.ResourceList, error) {
+	resources := corev1.ResourceList{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, quantity, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid resource %q, expected name=quantity", pair)
+		}
+		q, err := resource.ParseQuantity(strings.TrimSpace(quantity))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid quantity for %s", name)
+		}
+		resources[corev1.ResourceName(strings.TrimSpace(name))] = q
+	}
+	return resources, nil
+}
+
+// expectedResources returns the per-runner resources declared on the RGD, or one pod
+func expectedResources(rgdInfo *RGDInfo) (corev1.ResourceList, error) {
+	value, ok := rgdInfo.Annotations[expectedResourcesAnnotation]
+	if !ok {
+		return defaultExpectedResources, nil
+	}
+
+	resources, err := parseExpectedResources(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "RGD %s has an invalid %s annotation", rgdInfo.Name, expectedResourcesAnnotation)
+	}
+	return resources, nil
+}
+
+// quotaNames lists the quota resource names an expected resource counts against;
+// plain cpu and memory are also limited by their requests.* quotas
+func quotaNames(name corev1.ResourceName) []corev1.ResourceName {
+	switch name {
+	case corev1.ResourceCPU:
+		return []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceRequestsCPU}
+	case corev1.ResourceMemory:
+		return []corev1.ResourceName{corev1.ResourceMemory, corev1.ResourceRequestsMemory}
+	default:
+		return []corev1.ResourceName{name}
+	}
+}
+
+// checkQuota fails with ErrQuotaExceeded when adding the RGD's expected resources to the
+// namespace's current usage would exceed any ResourceQuota's hard limit
+func (r *KRORunner) checkQuota(ctx context.Context, rgdInfo *RGDInfo) error {
+	expected, err := expectedResources(rgdInfo)
+	if err != nil {
+		return err
+	}
+
+	quotas, err := r.kubeClient.CoreV1().ResourceQuotas(r.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list resource quotas")
+	}
+
+	var exceeded []string
+	for _, quota := range quotas.Items {
+		for name, want := range expected {
+			for _, quotaName := range quotaNames(name) {
+				hard, ok := quota.Status.Hard[quotaName]
+				if !ok {
+					continue
+				}
+				used := quota.Status.Used[quotaName]
+				total := used.DeepCopy()
+				total.Add(want)
+				if total.Cmp(hard) > 0 {
+					exceeded = append(exceeded, fmt.Sprintf("%s/%s: used %s + requested %s > hard %s",
+						quota.Name, quotaName, used.String(), want.String(), hard.String()))
+				}
+			}
+		}
+	}
+
+	if len(exceeded) > 0 {
+		sort.Strings(exceeded)
+		return errors.Wrapf(ErrQuotaExceeded, "namespace %s: %s", r.namespace, strings.Join(exceeded, "; "))
+	}
+
+	Infof("Resource quotas in namespace %s have room for runner", r.namespace)
+	return nil
+}
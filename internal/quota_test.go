@@ -0,0 +1,116 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestQuota builds a ResourceQuota with the given hard limits and usage
+func newTestQuota(name string, hard, used corev1.ResourceList) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status:     corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+	}
+}
+
+// TestCreateResourcesCheckQuota tests failing fast when the namespace quota cannot fit another runner
+func TestCreateResourcesCheckQuota(t *testing.T) {
+	tests := []struct {
+		name       string
+		expected   string
+		quota      *corev1.ResourceQuota
+		checkQuota bool
+		wantErr    error
+		expectErr  bool
+	}{
+		{
+			name:       "Pod quota has room",
+			quota:      newTestQuota("pods", corev1.ResourceList{"pods": resource.MustParse("10")}, corev1.ResourceList{"pods": resource.MustParse("9")}),
+			checkQuota: true,
+		},
+		{
+			name:       "Pod quota at its limit",
+			quota:      newTestQuota("pods", corev1.ResourceList{"pods": resource.MustParse("10")}, corev1.ResourceList{"pods": resource.MustParse("10")}),
+			checkQuota: true,
+			wantErr:    ErrQuotaExceeded,
+		},
+		{
+			name:     "Declared CPU exceeds requests.cpu quota",
+			expected: "pods=1,cpu=2",
+			quota: newTestQuota("compute",
+				corev1.ResourceList{"pods": resource.MustParse("10"), "requests.cpu": resource.MustParse("8")},
+				corev1.ResourceList{"pods": resource.MustParse("3"), "requests.cpu": resource.MustParse("7")}),
+			checkQuota: true,
+			wantErr:    ErrQuotaExceeded,
+		},
+		{
+			name:       "Quota not checked when disabled",
+			quota:      newTestQuota("pods", corev1.ResourceList{"pods": resource.MustParse("10")}, corev1.ResourceList{"pods": resource.MustParse("10")}),
+			checkQuota: false,
+		},
+		{
+			name:       "Invalid expected resources annotation",
+			expected:   "cpu",
+			quota:      newTestQuota("pods", corev1.ResourceList{"pods": resource.MustParse("10")}, nil),
+			checkQuota: true,
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rgd := newTestRGD("pod-runner", "test-scale-set", "PodRunner")
+			if tt.expected != "" {
+				rgd.SetAnnotations(map[string]string{expectedResourcesAnnotation: tt.expected})
+			}
+			dynamicClient := newFakeDynamicClient(rgd)
+			objects := []runtime.Object{newTestPod("default", "test-runner"), tt.quota}
+
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(objects...), "test-scale-set",
+				WithCheckQuota(tt.checkQuota))
+			err := r.CreateResources(context.TODO(), "test-runner", "test-config")
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateResources() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.expectErr:
+				if err == nil {
+					t.Fatal("CreateResources() error = nil, want error")
+				}
+			case err != nil:
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+
+			// No instance may be created when the check fails
+			list, _ := dynamicClient.Resource(testRGGVR).Namespace("default").List(context.TODO(), metav1.ListOptions{})
+			if created := len(list.Items) > 0; created != (err == nil) {
+				t.Errorf("instance created = %v, want %v", created, err == nil)
+			}
+		})
+	}
+}
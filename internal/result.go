@@ -0,0 +1,291 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Runner outcomes reported in a RunnerResult
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+	OutcomeTimeout   = "timeout"
+	OutcomeCancelled = "cancelled"
+)
+
+// Condition is a snapshot of a single status condition of the instance
+type Condition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+}
+
+// RunnerResult describes the outcome of a single runner orchestration
+type RunnerResult struct {
+	RunnerName      string      `json:"runnerName"`
+	ScaleSetName    string      `json:"scaleSetName"`
+	Outcome         string      `json:"outcome"`
+	Error           string      `json:"error,omitempty"`
+	StartTime       time.Time   `json:"startTime"`
+	DurationSeconds float64     `json:"durationSeconds"`
+	Conditions      []Condition `json:"conditions,omitempty"`
+	// ExitCode is the runner container's exit code, when the instance status exposes it
+	ExitCode *int `json:"exitCode,omitempty"`
+}
+
+// NewRunnerResult builds a result for a run that started at start and ended with err
+func NewRunnerResult(runnerName, scaleSetName string, start time.Time, err error, conditions []Condition) RunnerResult {
+	result := RunnerResult{
+		RunnerName:      runnerName,
+		ScaleSetName:    scaleSetName,
+		Outcome:         OutcomeFor(err),
+		StartTime:       start,
+		DurationSeconds: time.Since(start).Seconds(),
+		Conditions:      conditions,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// OutcomeFor classifies a run error into an outcome, as reported in RunnerResult
+func OutcomeFor(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSucceeded
+	case errors.Is(err, context.DeadlineExceeded):
+		return OutcomeTimeout
+	case errors.Is(err, context.Canceled), errors.Is(err, ErrRunnerCancelled):
+		return OutcomeCancelled
+	default:
+		return OutcomeFailed
+	}
+}
+
+// extractConditions snapshots status.conditions of an instance
+func extractConditions(rg *unstructured.Unstructured) []Condition {
+	raw, found, err := unstructured.NestedSlice(rg.Object, "status", "conditions")
+	if err != nil || !found {
+		return nil
+	}
+
+	conditions := make([]Condition, 0, len(raw))
+	for _, item := range raw {
+		condMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		cond := Condition{}
+		cond.Type, _ = condMap["type"].(string)
+		cond.Status, _ = condMap["status"].(string)
+		cond.Reason, _ = condMap["reason"].(string)
+		cond.Message, _ = condMap["message"].(string)
+		cond.LastTransitionTime, _ = condMap["lastTransitionTime"].(string)
+		conditions = append(conditions, cond)
+	}
+
+	return conditions
+}
+
+// containerExitCode returns the exit code of terminated containers in status.resources.<id>.status.containerStatuses.
+// A non-zero exit code wins, so a failing runner is not hidden by a sidecar that exited cleanly.
+func containerExitCode(rg *unstructured.Unstructured) (int, bool) {
+	resources, found, err := unstructured.NestedMap(rg.Object, "status", "resources")
+	if err != nil || !found {
+		return 0, false
+	}
+
+	ids := make([]string, 0, len(resources))
+	for id := range resources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	terminated := false
+	for _, id := range ids {
+		resource, ok := resources[id].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		statuses, _, _ := unstructured.NestedSlice(resource, "status", "containerStatuses")
+		for _, s := range statuses {
+			status, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			code, found, err := unstructured.NestedInt64(status, "state", "terminated", "exitCode")
+			if err != nil || !found {
+				continue
+			}
+			if code != 0 {
+				return int(code), true
+			}
+			terminated = true
+		}
+	}
+
+	return 0, terminated
+}
+
+// logConditions records the condition snapshot taken when a runner failed
+func logConditions(runnerName string, conditions []Condition) {
+	if len(conditions) == 0 {
+		log.Printf("ResourceGraph %s reported no conditions", runnerName)
+		return
+	}
+
+	for _, cond := range conditions {
+		log.Printf("ResourceGraph %s condition: type=%s status=%s reason=%s message=%q lastTransitionTime=%s",
+			runnerName, cond.Type, cond.Status, cond.Reason, cond.Message, cond.LastTransitionTime)
+	}
+}
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// junit renders the result as a single JUnit test case
+func (res RunnerResult) junit() ([]byte, error) {
+	seconds := fmt.Sprintf("%.3f", res.DurationSeconds)
+	testCase := junitTestCase{
+		ClassName: res.ScaleSetName,
+		Name:      res.RunnerName,
+		Time:      seconds,
+	}
+
+	failures := 0
+	if res.Outcome != OutcomeSucceeded {
+		failures = 1
+
+		var body strings.Builder
+		for _, cond := range res.Conditions {
+			fmt.Fprintf(&body, "%s=%s reason=%s message=%s\n", cond.Type, cond.Status, cond.Reason, cond.Message)
+		}
+		testCase.Failure = &junitFailure{Message: res.Error, Type: res.Outcome, Body: body.String()}
+	}
+
+	out, err := xml.MarshalIndent(junitTestSuites{
+		Suites: []junitSuite{{
+			Name:     "kar",
+			Tests:    1,
+			Failures: failures,
+			Time:     seconds,
+			Cases:    []junitTestCase{testCase},
+		}},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+// WriteFile writes the result to path as JUnit XML for .xml files and JSON otherwise
+func (res RunnerResult) WriteFile(path string) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		data, err = res.junit()
+	} else {
+		data, err = json.MarshalIndent(res, "", "  ")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to encode runner result")
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write runner result to %s", path)
+	}
+
+	return nil
+}
+
+// Post sends the result as JSON to url, authenticating with token as a bearer token when set
+func (res RunnerResult) Post(ctx context.Context, client *http.Client, url, token string) error {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode runner result")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "failed to build result webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to post runner result")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("result webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,284 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestOutcomeFor tests classification of run errors into outcomes
+func TestOutcomeFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "No error", err: nil, expected: OutcomeSucceeded},
+		{name: "Runner failed", err: errors.Wrap(ErrRunnerFailed, "fail to wait"), expected: OutcomeFailed},
+		{name: "Deadline exceeded", err: errors.Wrap(context.DeadlineExceeded, "fail to wait"), expected: OutcomeTimeout},
+		{name: "Cancelled", err: errors.Wrap(context.Canceled, "fail to wait"), expected: OutcomeCancelled},
+		{name: "Cancelled by annotation", err: errors.Wrap(ErrRunnerCancelled, "fail to wait"), expected: OutcomeCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OutcomeFor(tt.err); got != tt.expected {
+				t.Errorf("OutcomeFor(%v) = %q, want %q", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRunnerResultWriteFileJSON tests the JSON result file
+func TestRunnerResultWriteFileJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.json")
+	result := NewRunnerResult("test-runner", "test-scale-set", time.Now().Add(-2*time.Second), nil, nil)
+
+	if err := result.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read result file: %v", err)
+	}
+
+	var decoded RunnerResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("result file is not valid JSON: %v", err)
+	}
+	if decoded.Outcome != OutcomeSucceeded {
+		t.Errorf("Outcome = %q, want %q", decoded.Outcome, OutcomeSucceeded)
+	}
+	if decoded.DurationSeconds < 2 {
+		t.Errorf("DurationSeconds = %v, want >= 2", decoded.DurationSeconds)
+	}
+}
+
+// TestRunnerResultWriteFileJUnit tests the JUnit XML result file for a failed run
+func TestRunnerResultWriteFileJUnit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "result.xml")
+	conditions := []Condition{{Type: "ResourcesReady", Status: "False", Reason: "PodFailed", Message: "exit code 1"}}
+	result := NewRunnerResult("test-runner", "test-scale-set", time.Now(), ErrRunnerFailed, conditions)
+
+	if err := result.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read result file: %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("result file is not valid XML: %v", err)
+	}
+	if len(suites.Suites) != 1 || len(suites.Suites[0].Cases) != 1 {
+		t.Fatalf("expected one suite with one test case, got %+v", suites)
+	}
+
+	testCase := suites.Suites[0].Cases[0]
+	if testCase.Name != "test-runner" {
+		t.Errorf("test case name = %q, want %q", testCase.Name, "test-runner")
+	}
+	if testCase.Failure == nil {
+		t.Fatal("failed run has no failure element")
+	}
+	if testCase.Failure.Message != ErrRunnerFailed.Error() {
+		t.Errorf("failure message = %q, want %q", testCase.Failure.Message, ErrRunnerFailed.Error())
+	}
+	if !strings.Contains(testCase.Failure.Body, "PodFailed") {
+		t.Errorf("failure body %q does not include conditions", testCase.Failure.Body)
+	}
+}
+
+// TestRunnerResultPost tests delivering the result to a webhook
+func TestRunnerResultPost(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		status    int
+		expectErr bool
+	}{
+		{name: "Delivered with token", token: "secret-token", status: http.StatusOK},
+		{name: "Delivered without token", status: http.StatusNoContent},
+		{name: "Server error", status: http.StatusInternalServerError, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				received      RunnerResult
+				authorization string
+				contentType   string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				authorization = req.Header.Get("Authorization")
+				contentType = req.Header.Get("Content-Type")
+				_ = json.NewDecoder(req.Body).Decode(&received)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			result := NewRunnerResult("test-runner", "test-scale-set", time.Now(), ErrRunnerFailed, nil)
+			err := result.Post(context.Background(), server.Client(), server.URL, tt.token)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Post() error = %v, expectErr %v", err, tt.expectErr)
+			}
+
+			if received.RunnerName != "test-runner" || received.Outcome != OutcomeFailed {
+				t.Errorf("received result = %+v, want runner test-runner with outcome %s", received, OutcomeFailed)
+			}
+			if contentType != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", contentType)
+			}
+
+			wantAuthorization := ""
+			if tt.token != "" {
+				wantAuthorization = "Bearer " + tt.token
+			}
+			if authorization != wantAuthorization {
+				t.Errorf("Authorization = %q, want %q", authorization, wantAuthorization)
+			}
+		})
+	}
+}
+
+// withTerminatedContainers sets the container statuses of resource id to terminated with exitCodes
+func withTerminatedContainers(instance *unstructured.Unstructured, id string, exitCodes ...int64) *unstructured.Unstructured {
+	instance = instance.DeepCopy()
+	statuses := make([]interface{}, 0, len(exitCodes))
+	for i, code := range exitCodes {
+		statuses = append(statuses, map[string]interface{}{
+			"name":  fmt.Sprintf("container-%d", i),
+			"state": map[string]interface{}{"terminated": map[string]interface{}{"exitCode": code}},
+		})
+	}
+	_ = unstructured.SetNestedSlice(instance.Object, statuses, "status", "resources", id, "status", "containerStatuses")
+	return instance
+}
+
+// TestContainerExitCode tests reading the runner exit code from the instance status
+func TestContainerExitCode(t *testing.T) {
+	base := withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", true, "")
+
+	tests := []struct {
+		name         string
+		instance     *unstructured.Unstructured
+		expectedCode int
+		expectedOK   bool
+	}{
+		{name: "No resources", instance: newTestInstance("default", "test-runner", "test-scale-set")},
+		{name: "Not terminated", instance: withStatus(base, "ACTIVE", false, "Running")},
+		{name: "Zero exit code", instance: withTerminatedContainers(base, "runnerPod", 0), expectedOK: true},
+		{name: "Non-zero exit code", instance: withTerminatedContainers(base, "runnerPod", 2), expectedCode: 2, expectedOK: true},
+		{name: "Non-zero wins over sidecar", instance: withTerminatedContainers(base, "runnerPod", 0, 137), expectedCode: 137, expectedOK: true},
+		{name: "Any resource id", instance: withTerminatedContainers(base, "jobPod", 1), expectedCode: 1, expectedOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, ok := containerExitCode(tt.instance)
+			if code != tt.expectedCode || ok != tt.expectedOK {
+				t.Errorf("containerExitCode() = (%d, %v), want (%d, %v)", code, ok, tt.expectedCode, tt.expectedOK)
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphExitCode tests that a failed runner reports its container exit code
+func TestWaitForResourceGraphExitCode(t *testing.T) {
+	r, watcher, _ := newWatchedRunner(t)
+
+	instance := withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "FAILED", false, "Failed")
+	watcher.Modify(withTerminatedContainers(instance, "runnerPod", 3))
+
+	if err := r.WaitForResourceGraph(context.TODO()); err == nil {
+		t.Fatal("WaitForResourceGraph() error = nil, want error")
+	}
+
+	code, ok := r.ExitCode()
+	if !ok || code != 3 {
+		t.Errorf("ExitCode() = (%d, %v), want (3, true)", code, ok)
+	}
+}
+
+// TestInstanceHealthy tests which observed instances count as a job still running without failure
+func TestInstanceHealthy(t *testing.T) {
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+
+	tests := []struct {
+		name     string
+		instance *unstructured.Unstructured
+		expected bool
+	}{
+		{name: "Active and running", instance: withStatus(instance, "ACTIVE", false, "Running"), expected: true},
+		{name: "In progress without pod", instance: withStatus(instance, "IN_PROGRESS", false, ""), expected: true},
+		{name: "Pod succeeded", instance: withStatus(instance, "ACTIVE", true, "Succeeded"), expected: false},
+		{name: "Pod failed", instance: withStatus(instance, "ACTIVE", true, "Failed"), expected: false},
+		{name: "Instance failed", instance: withStatus(instance, "FAILED", false, "Running"), expected: false},
+		{name: "No status", instance: instance, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := instanceHealthy(tt.instance); got != tt.expected {
+				t.Errorf("instanceHealthy() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphInstanceHealthy tests that the wait records the health of the last observed instance
+func TestWaitForResourceGraphInstanceHealthy(t *testing.T) {
+	tests := []struct {
+		name     string
+		podPhase string
+		expected bool
+	}{
+		{name: "Running", podPhase: "Running", expected: true},
+		{name: "Failed", podPhase: "Failed", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, watcher, _ := newWatchedRunner(t, WithTerminateOnFirstEvent(true))
+			watcher.Modify(withStatus(newTestInstance("default", "test-runner", "test-scale-set"), "ACTIVE", false, tt.podPhase))
+
+			if err := r.WaitForResourceGraph(context.TODO()); err != nil {
+				t.Fatalf("WaitForResourceGraph() error = %v", err)
+			}
+			if got := r.InstanceHealthy(); got != tt.expected {
+				t.Errorf("InstanceHealthy() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
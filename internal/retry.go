@@ -0,0 +1,143 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Backoff bounds for retrying transient API errors
+var (
+	retryInitialBackoff = 200 * time.Millisecond
+	retryMaxBackoff     = 5 * time.Second
+	// retryAfterUnit scales the server's Retry-After hint, which is given in seconds
+	retryAfterUnit = time.Second
+)
+
+// ErrRetryBudgetExhausted is returned when retries would exceed the shared startup retry budget
+var ErrRetryBudgetExhausted = errors.New("startup retry budget exhausted")
+
+// retryBudget caps the cumulative backoff spent retrying across several operations
+type retryBudget struct {
+	total     time.Duration
+	remaining time.Duration
+	retries   int
+}
+
+type retryBudgetKey struct{}
+
+// withRetryBudget attaches a budget of total backoff time shared by every retry under ctx
+func withRetryBudget(ctx context.Context, total time.Duration) context.Context {
+	return context.WithValue(ctx, retryBudgetKey{}, &retryBudget{total: total, remaining: total})
+}
+
+// retryBudgetFrom returns the budget attached to ctx, or nil if retries are unbounded
+func retryBudgetFrom(ctx context.Context) *retryBudget {
+	budget, _ := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	return budget
+}
+
+// spend reserves backoff from the budget, reporting false once it would be overdrawn
+func (b *retryBudget) spend(backoff time.Duration) bool {
+	if backoff > b.remaining {
+		return false
+	}
+	b.remaining -= backoff
+	b.retries++
+	return true
+}
+
+// IsRetryable reports whether an API error is likely to succeed on retry. Conflicts,
+// timeouts, throttling and server errors are retryable; NotFound, Forbidden, Invalid,
+// AlreadyExists and any other error are not.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return k8serrors.IsConflict(err) ||
+		k8serrors.IsServerTimeout(err) ||
+		k8serrors.IsTimeout(err) ||
+		k8serrors.IsTooManyRequests(err) ||
+		k8serrors.IsInternalError(err) ||
+		k8serrors.IsServiceUnavailable(err) ||
+		k8serrors.IsUnexpectedServerError(err)
+}
+
+// retryTransient calls fn until it succeeds, fails permanently, ctx expires or the
+// context's retry budget runs out, backing off exponentially between transient failures
+func retryTransient(ctx context.Context, operation string, fn func() error) error {
+	return retryWhile(ctx, operation, IsRetryable, fn)
+}
+
+// retryThrottled retries fn like retryTransient, but only while the API server throttles it
+// with 429 TooManyRequests; any other error is returned at once
+func retryThrottled(ctx context.Context, operation string, fn func() error) error {
+	return retryWhile(ctx, operation, k8serrors.IsTooManyRequests, fn)
+}
+
+// retryWhile calls fn until it succeeds, returns an error retryable rejects, ctx expires or the
+// context's retry budget runs out. Each wait is the exponential backoff or the server's
+// Retry-After hint, whichever is longer.
+func retryWhile(ctx context.Context, operation string, retryable func(error) bool, fn func() error) error {
+	budget := retryBudgetFrom(ctx)
+	backoff := retryInitialBackoff
+	for {
+		err := fn()
+		if err == nil || !retryable(err) {
+			return err
+		}
+
+		delay := backoff
+		if seconds, ok := k8serrors.SuggestsClientDelay(err); ok {
+			if hint := time.Duration(seconds) * retryAfterUnit; hint > delay {
+				delay = hint
+			}
+		}
+
+		if budget != nil && !budget.spend(delay) {
+			return errors.Wrapf(ErrRetryBudgetExhausted, "gave up after %d retries within %s, last error from %s: %v",
+				budget.retries, budget.total, operation, err)
+		}
+
+		log.Printf("%s failed with transient error, retrying in %s: %v", operation, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+
+		backoff *= 2
+		if backoff > retryMaxBackoff {
+			backoff = retryMaxBackoff
+		}
+	}
+}
+
+// retryWithinBudget retries fn like retryTransient when ctx carries a retry budget, and calls it once otherwise
+func retryWithinBudget(ctx context.Context, operation string, fn func() error) error {
+	if retryBudgetFrom(ctx) == nil {
+		return fn()
+	}
+	return retryTransient(ctx, operation, fn)
+}
@@ -0,0 +1,304 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// useFastBackoff shortens retry backoff for the duration of a test
+func useFastBackoff(t *testing.T) {
+	t.Helper()
+
+	initial, maxBackoff, unit := retryInitialBackoff, retryMaxBackoff, retryAfterUnit
+	retryInitialBackoff, retryMaxBackoff, retryAfterUnit = time.Millisecond, 5*time.Millisecond, time.Millisecond
+	t.Cleanup(func() { retryInitialBackoff, retryMaxBackoff, retryAfterUnit = initial, maxBackoff, unit })
+}
+
+// TestRetryTransient tests retry decisions for transient and permanent errors
+func TestRetryTransient(t *testing.T) {
+	useFastBackoff(t)
+	gr := schema.GroupResource{Group: "kro.run", Resource: "podrunners"}
+
+	tests := []struct {
+		name          string
+		errs          []error
+		expectedCalls int
+		expectErr     bool
+	}{
+		{name: "Immediate success", errs: nil, expectedCalls: 1},
+		{name: "Conflict then success", errs: []error{k8serrors.NewConflict(gr, "x", errors.New("conflict"))}, expectedCalls: 2},
+		{name: "Server errors then success", errs: []error{k8serrors.NewInternalError(errors.New("boom")), k8serrors.NewServiceUnavailable("down")}, expectedCalls: 3},
+		{name: "Not found is not retried", errs: []error{k8serrors.NewNotFound(gr, "x")}, expectedCalls: 1, expectErr: true},
+		{name: "Forbidden is not retried", errs: []error{k8serrors.NewForbidden(gr, "x", errors.New("rbac"))}, expectedCalls: 1, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryTransient(context.TODO(), "test", func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+
+			if (err != nil) != tt.expectErr {
+				t.Errorf("retryTransient() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.expectedCalls)
+			}
+		})
+	}
+}
+
+// TestRetryTransientContextExpiry tests that retries stop when the context expires
+func TestRetryTransientContextExpiry(t *testing.T) {
+	useFastBackoff(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := retryTransient(ctx, "test", func() error {
+		return k8serrors.NewTooManyRequests("slow down", 1)
+	})
+	if !k8serrors.IsTooManyRequests(err) {
+		t.Errorf("retryTransient() error = %v, want the last transient error", err)
+	}
+}
+
+// TestDeleteResourcesRetriesTransientErrors tests that cleanup retries a failing delete until it succeeds
+func TestDeleteResourcesRetriesTransientErrors(t *testing.T) {
+	useFastBackoff(t)
+
+	dynamicClient := newFakeDynamicClient(
+		newTestRGD("pod-runner", "test-scale-set", "PodRunner"),
+		newTestInstance("default", "test-runner", "test-scale-set"),
+	)
+
+	failures := 0
+	dynamicClient.PrependReactor("delete", "podrunners", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		if failures < 2 {
+			failures++
+			return true, nil, k8serrors.NewConflict(testRGGVR.GroupResource(), "test-runner", errors.New("conflict"))
+		}
+		return false, nil, nil
+	})
+
+	NewAppContext("test-runner", "")
+	t.Cleanup(func() { appContext = nil })
+
+	r := NewKRORunner("default", dynamicClient, nil, "test-scale-set")
+	if err := r.DeleteResources(context.TODO()); err != nil {
+		t.Fatalf("DeleteResources() error = %v", err)
+	}
+
+	if failures != 2 {
+		t.Errorf("delete failed %d times before succeeding, want 2", failures)
+	}
+	if _, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+		context.TODO(), "test-runner", metav1.GetOptions{}); !k8serrors.IsNotFound(err) {
+		t.Errorf("instance still exists after cleanup: %v", err)
+	}
+}
+
+// TestIsRetryable tests retry classification for each API error category
+func TestIsRetryable(t *testing.T) {
+	gr := schema.GroupResource{Group: "kro.run", Resource: "podrunners"}
+	cause := errors.New("cause")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "Nil", err: nil, expected: false},
+		{name: "Conflict", err: k8serrors.NewConflict(gr, "x", cause), expected: true},
+		{name: "ServerTimeout", err: k8serrors.NewServerTimeout(gr, "create", 1), expected: true},
+		{name: "Timeout", err: k8serrors.NewTimeoutError("timeout", 1), expected: true},
+		{name: "TooManyRequests", err: k8serrors.NewTooManyRequests("slow down", 1), expected: true},
+		{name: "InternalError", err: k8serrors.NewInternalError(cause), expected: true},
+		{name: "ServiceUnavailable", err: k8serrors.NewServiceUnavailable("down"), expected: true},
+		{name: "UnexpectedServerError", err: k8serrors.NewGenericServerResponse(500, "get", gr, "x", "boom", 0, true), expected: true},
+		{name: "Wrapped Conflict", err: fmt.Errorf("create: %w", k8serrors.NewConflict(gr, "x", cause)), expected: true},
+		{name: "NotFound", err: k8serrors.NewNotFound(gr, "x"), expected: false},
+		{name: "Forbidden", err: k8serrors.NewForbidden(gr, "x", cause), expected: false},
+		{name: "Invalid", err: k8serrors.NewInvalid(schema.GroupKind{Group: "kro.run", Kind: "PodRunner"}, "x", nil), expected: false},
+		{name: "AlreadyExists", err: k8serrors.NewAlreadyExists(gr, "x"), expected: false},
+		{name: "Unauthorized", err: k8serrors.NewUnauthorized("no token"), expected: false},
+		{name: "BadRequest", err: k8serrors.NewBadRequest("bad"), expected: false},
+		{name: "Non-API error", err: cause, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.expected {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesRetriesTransientErrors tests that a throttled create is retried
+func TestCreateResourcesRetriesTransientErrors(t *testing.T) {
+	useFastBackoff(t)
+
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	failures := 0
+	dynamicClient.PrependReactor("create", "podrunners", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		if failures < 2 {
+			failures++
+			return true, nil, k8serrors.NewTooManyRequests("slow down", 1)
+		}
+		return false, nil, nil
+	})
+
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set")
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+	if failures != 2 {
+		t.Errorf("create failed %d times before succeeding, want 2", failures)
+	}
+}
+
+// TestCreateResourcesStartupRetryBudget tests that discovery and create draw on one retry budget
+func TestCreateResourcesStartupRetryBudget(t *testing.T) {
+	useFastBackoff(t)
+
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	listFailures := 0
+	dynamicClient.PrependReactor("list", "resourcegraphdefinitions", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		if listFailures < 2 {
+			listFailures++
+			return true, nil, k8serrors.NewServiceUnavailable("down")
+		}
+		return false, nil, nil
+	})
+	creates := 0
+	dynamicClient.PrependReactor("create", "podrunners", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		creates++
+		return true, nil, k8serrors.NewTooManyRequests("slow down", 1)
+	})
+
+	// Discovery spends 1ms+2ms of the 8ms budget, leaving create two retries (1ms+2ms) before 4ms overdraws it
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set",
+		WithStartupRetryBudget(8*time.Millisecond))
+	err := r.CreateResources(context.TODO(), "test-runner", "test-config")
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("CreateResources() error = %v, want %v", err, ErrRetryBudgetExhausted)
+	}
+
+	for _, want := range []string{"after 4 retries", "Creating ResourceGraph instance test-runner", "slow down"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q is missing %q", err, want)
+		}
+	}
+	if listFailures != 2 || creates != 3 {
+		t.Errorf("discovery failed %d times and create was called %d times, want 2 and 3", listFailures, creates)
+	}
+}
+
+// TestFindRGDByLabelRetryAfter tests that a throttled RGD List waits for the Retry-After hint
+func TestFindRGDByLabelRetryAfter(t *testing.T) {
+	useFastBackoff(t)
+
+	tests := []struct {
+		name          string
+		err           error
+		expectedCalls int
+		expectedWait  string
+		expectErr     bool
+	}{
+		{name: "Throttled then success", err: k8serrors.NewTooManyRequests("slow down", 30), expectedCalls: 2, expectedWait: "retrying in 30ms"},
+		{name: "Hint shorter than backoff", err: k8serrors.NewTooManyRequests("slow down", 0), expectedCalls: 2, expectedWait: "retrying in 1ms"},
+		{name: "Server error is not retried", err: k8serrors.NewServiceUnavailable("down"), expectedCalls: 1, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+			calls := 0
+			dynamicClient.PrependReactor("list", "resourcegraphdefinitions", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+				calls++
+				if calls == 1 {
+					return true, nil, tt.err
+				}
+				return false, nil, nil
+			})
+
+			r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set")
+			logs := captureLogs(t)
+			info, err := r.findRGDByLabel(context.TODO())
+
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("findRGDByLabel() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.expectedCalls)
+			}
+			if !tt.expectErr && info.Kind != "PodRunner" {
+				t.Errorf("Kind = %q, want PodRunner", info.Kind)
+			}
+			if tt.expectedWait != "" && !strings.Contains(logs.String(), tt.expectedWait) {
+				t.Errorf("logs %q do not contain %q", logs, tt.expectedWait)
+			}
+		})
+	}
+}
+
+// TestWaitForResourceGraphReconnectPermanentError tests that reconnecting stops on a non-retryable error
+func TestWaitForResourceGraphReconnectPermanentError(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	NewAppContext("test-runner", "")
+	t.Cleanup(func() { appContext = nil })
+
+	watches := 0
+	dynamicClient.PrependWatchReactor("podrunners", func(k8stesting.Action) (bool, watch.Interface, error) {
+		watches++
+		if watches > 1 {
+			return true, nil, k8serrors.NewForbidden(testRGGVR.GroupResource(), "", errors.New("rbac"))
+		}
+		watcher := watch.NewFake()
+		watcher.Stop()
+		return true, watcher, nil
+	})
+
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(), "test-scale-set",
+		WithWatchReconnect(5, time.Millisecond))
+	if err := r.WaitForResourceGraph(context.TODO()); !k8serrors.IsForbidden(err) {
+		t.Errorf("WaitForResourceGraph() error = %v, want Forbidden", err)
+	}
+	if watches != 2 {
+		t.Errorf("watches opened = %d, want 2", watches)
+	}
+}
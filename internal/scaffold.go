@@ -0,0 +1,126 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ErrInvalidScaffold is returned when the RGD skeleton cannot be generated from the given kind or scale set name
+var ErrInvalidScaffold = errors.New("invalid RGD skeleton input")
+
+// scaffoldKindPattern matches a CamelCase Kind such as PodRunner
+var scaffoldKindPattern = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// rgdSkeleton is a minimal RGD kar can discover, create and wait on: the scale set label for discovery,
+// a runnerName spec field, and a runnerPod resource whose readyWhen drives the ResourcesReady condition
+var rgdSkeleton = template.Must(template.New("rgd").Parse(`apiVersion: kro.run/v1alpha1
+kind: ResourceGraphDefinition
+metadata:
+  name: {{ .Name }}
+  labels:
+    # kar discovers the RGD by this label; it must match the ARC runnerScaleSetName
+    {{ .LabelKey }}: {{ .ScaleSetName }}
+spec:
+  schema:
+    apiVersion: v1alpha1
+    kind: {{ .Kind }}
+    spec:
+      # Set by kar; also the name of the ARC-created JIT config secret
+      runnerName: string
+  resources:
+    # Wait for the ARC-created JIT config secret to exist
+    - id: jitSecret
+      externalRef:
+        apiVersion: v1
+        kind: Secret
+        metadata:
+          name: ${schema.spec.runnerName}
+    # kar reads status.resources.runnerPod.status.phase for the outcome, so keep this id.
+    # ResourcesReady becomes True once readyWhen holds, which kar treats as completion.
+    - id: runnerPod
+      readyWhen:
+        - ${runnerPod.status.phase == "Succeeded" || runnerPod.status.phase == "Failed"}
+      template:
+        apiVersion: v1
+        kind: Pod
+        metadata:
+          # Suffixed to avoid clashing with the orchestrator pod of the same name
+          name: ${schema.spec.runnerName}-job
+        spec:
+          restartPolicy: Never
+          containers:
+            - name: runner
+              image: ghcr.io/actions/actions-runner:latest
+              command: ["/home/runner/run.sh"]
+              env:
+                - name: ACTIONS_RUNNER_INPUT_JITCONFIG
+                  valueFrom:
+                    secretKeyRef:
+                      name: ${schema.spec.runnerName}
+                      key: {{ .SecretKey }}
+`))
+
+// ScaffoldRGD returns a minimal ResourceGraphDefinition YAML for kind, labelled for discovery by scaleSetName
+func ScaffoldRGD(kind, scaleSetName string) (string, error) {
+	if !scaffoldKindPattern.MatchString(kind) {
+		return "", errors.Wrapf(ErrInvalidScaffold, "kind %q must be CamelCase, e.g. PodRunner", kind)
+	}
+	if scaleSetName == "" {
+		return "", errors.Wrap(ErrInvalidScaffold, "scale set name is required")
+	}
+	if errs := validation.IsValidLabelValue(scaleSetName); len(errs) > 0 {
+		return "", errors.Wrapf(ErrInvalidScaffold, "scale set name %q: %s", scaleSetName, strings.Join(errs, "; "))
+	}
+
+	var buf bytes.Buffer
+	err := rgdSkeleton.Execute(&buf, struct {
+		Name, Kind, LabelKey, ScaleSetName, SecretKey string
+	}{
+		Name:         kindToRGDName(kind),
+		Kind:         kind,
+		LabelKey:     rgdLabelKey,
+		ScaleSetName: strconv.Quote(scaleSetName),
+		SecretKey:    jitConfigSecretKey,
+	})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// kindToRGDName converts a CamelCase Kind to a kebab-case RGD name, e.g. PodRunner to pod-runner
+func kindToRGDName(kind string) string {
+	var b strings.Builder
+	for i, c := range kind {
+		if i > 0 && c >= 'A' && c <= 'Z' {
+			prev := kind[i-1]
+			if prev >= 'a' && prev <= 'z' || prev >= '0' && prev <= '9' {
+				b.WriteByte('-')
+			}
+		}
+		b.WriteRune(c)
+	}
+	return strings.ToLower(b.String())
+}
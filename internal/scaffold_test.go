@@ -0,0 +1,112 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// TestScaffoldRGD tests that the generated skeleton parses and is discoverable for the scale set
+func TestScaffoldRGD(t *testing.T) {
+	tests := []struct {
+		name         string
+		kind         string
+		scaleSetName string
+		expectedName string
+		expectedErr  error
+	}{
+		{name: "Pod runner", kind: "PodRunner", scaleSetName: "linux-builders", expectedName: "pod-runner"},
+		{name: "Numeric scale set name", kind: "EC2Runner", scaleSetName: "1234", expectedName: "ec2-runner"},
+		{name: "Lowercase kind", kind: "podrunner", scaleSetName: "linux-builders", expectedErr: ErrInvalidScaffold},
+		{name: "Missing scale set name", kind: "PodRunner", expectedErr: ErrInvalidScaffold},
+		{name: "Invalid scale set name", kind: "PodRunner", scaleSetName: "linux builders", expectedErr: ErrInvalidScaffold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ScaffoldRGD(tt.kind, tt.scaleSetName)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("ScaffoldRGD() error = %v, want %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+
+			rgd := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal([]byte(out), &rgd.Object); err != nil {
+				t.Fatalf("skeleton is not YAML: %v\n%s", err, out)
+			}
+
+			if rgd.GetKind() != "ResourceGraphDefinition" {
+				t.Errorf("kind = %q, want ResourceGraphDefinition", rgd.GetKind())
+			}
+			if rgd.GetName() != tt.expectedName {
+				t.Errorf("name = %q, want %q", rgd.GetName(), tt.expectedName)
+			}
+			if got := rgd.GetLabels()[rgdLabelKey]; got != tt.scaleSetName {
+				t.Errorf("label %s = %q, want %q", rgdLabelKey, got, tt.scaleSetName)
+			}
+
+			info, err := newRGDInfo(rgd)
+			if err != nil {
+				t.Fatalf("newRGDInfo() error = %v", err)
+			}
+			if info.Kind != tt.kind {
+				t.Errorf("schema kind = %q, want %q", info.Kind, tt.kind)
+			}
+			if _, ok := info.SpecSchema["runnerName"]; !ok {
+				t.Errorf("spec schema %v has no runnerName field", info.SpecSchema)
+			}
+
+			resources, _, _ := unstructured.NestedSlice(rgd.Object, "spec", "resources")
+			hasRunnerPod := false
+			for _, resource := range resources {
+				if resource.(map[string]interface{})["id"] == "runnerPod" {
+					hasRunnerPod = true
+				}
+			}
+			if !hasRunnerPod {
+				t.Errorf("skeleton has no runnerPod resource:\n%s", out)
+			}
+		})
+	}
+}
+
+// TestKindToRGDName tests the CamelCase to kebab-case conversion of RGD names
+func TestKindToRGDName(t *testing.T) {
+	tests := []struct {
+		kind     string
+		expected string
+	}{
+		{kind: "PodRunner", expected: "pod-runner"},
+		{kind: "Runner", expected: "runner"},
+		{kind: "EC2Runner", expected: "ec2-runner"},
+		{kind: "GPURunner", expected: "gpurunner"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			if got := kindToRGDName(tt.kind); got != tt.expected {
+				t.Errorf("kindToRGDName(%q) = %q, want %q", tt.kind, got, tt.expected)
+			}
+		})
+	}
+}
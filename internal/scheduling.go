@@ -0,0 +1,141 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ErrInvalidScheduling is returned for a node selector or toleration that Kubernetes would reject
+var ErrInvalidScheduling = errors.New("invalid scheduling constraint")
+
+// Scheduling holds the pod scheduling constraints passed to the RGD under spec.scheduling
+type Scheduling struct {
+	NodeSelector map[string]string
+	Tolerations  []corev1.Toleration
+}
+
+// ParseScheduling parses key=value node selectors and key[=value][:effect] tolerations, in the style of
+// kubectl taint. A toleration with a value uses the Equal operator, one without uses Exists.
+// It returns nil when neither is given.
+func ParseScheduling(nodeSelectors, tolerations []string) (*Scheduling, error) {
+	if len(nodeSelectors) == 0 && len(tolerations) == 0 {
+		return nil, nil
+	}
+
+	scheduling := &Scheduling{}
+	for _, raw := range nodeSelectors {
+		key, value, ok := strings.Cut(strings.TrimSpace(raw), "=")
+		if !ok {
+			return nil, errors.Wrapf(ErrInvalidScheduling, "node selector %q, expected key=value", raw)
+		}
+		if err := validateSchedulingKeyValue(key, value); err != nil {
+			return nil, errors.Wrapf(err, "node selector %q", raw)
+		}
+		if _, exists := scheduling.NodeSelector[key]; exists {
+			return nil, errors.Wrapf(ErrInvalidScheduling, "duplicate node selector key %q", key)
+		}
+		if scheduling.NodeSelector == nil {
+			scheduling.NodeSelector = map[string]string{}
+		}
+		scheduling.NodeSelector[key] = value
+	}
+
+	for _, raw := range tolerations {
+		toleration, err := parseToleration(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, errors.Wrapf(err, "toleration %q", raw)
+		}
+		scheduling.Tolerations = append(scheduling.Tolerations, toleration)
+	}
+
+	return scheduling, nil
+}
+
+// parseToleration parses key[=value][:effect]
+func parseToleration(raw string) (corev1.Toleration, error) {
+	rest, effect, _ := strings.Cut(raw, ":")
+	key, value, hasValue := strings.Cut(rest, "=")
+
+	toleration := corev1.Toleration{Key: key, Operator: corev1.TolerationOpExists}
+	if hasValue {
+		toleration.Operator = corev1.TolerationOpEqual
+		toleration.Value = value
+	}
+	if err := validateSchedulingKeyValue(key, value); err != nil {
+		return corev1.Toleration{}, err
+	}
+
+	switch corev1.TaintEffect(effect) {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		toleration.Effect = corev1.TaintEffect(effect)
+	default:
+		return corev1.Toleration{}, errors.Wrapf(ErrInvalidScheduling,
+			"effect %q, expected NoSchedule, PreferNoSchedule or NoExecute", effect)
+	}
+
+	return toleration, nil
+}
+
+// validateSchedulingKeyValue checks a node label or taint key and value
+func validateSchedulingKeyValue(key, value string) error {
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return errors.Wrapf(ErrInvalidScheduling, "key %q: %s", key, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		return errors.Wrapf(ErrInvalidScheduling, "value %q: %s", value, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// spec converts the constraints into the spec's pod-style form, e.g.
+// {nodeSelector: {gpu: "true"}, tolerations: [{key: gpu, operator: Exists, effect: NoSchedule}]}
+func (s *Scheduling) spec() map[string]interface{} {
+	spec := map[string]interface{}{}
+
+	if len(s.NodeSelector) > 0 {
+		nodeSelector := map[string]interface{}{}
+		for key, value := range s.NodeSelector {
+			nodeSelector[key] = value
+		}
+		spec["nodeSelector"] = nodeSelector
+	}
+
+	if len(s.Tolerations) > 0 {
+		tolerations := make([]interface{}, 0, len(s.Tolerations))
+		for _, toleration := range s.Tolerations {
+			entry := map[string]interface{}{
+				"key":      toleration.Key,
+				"operator": string(toleration.Operator),
+			}
+			if toleration.Value != "" {
+				entry["value"] = toleration.Value
+			}
+			if toleration.Effect != "" {
+				entry["effect"] = string(toleration.Effect)
+			}
+			tolerations = append(tolerations, entry)
+		}
+		spec["tolerations"] = tolerations
+	}
+
+	return spec
+}
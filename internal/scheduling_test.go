@@ -0,0 +1,114 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// TestParseScheduling tests parsing and validation of node selectors and tolerations
+func TestParseScheduling(t *testing.T) {
+	tests := []struct {
+		name          string
+		nodeSelectors []string
+		tolerations   []string
+		expected      *Scheduling
+		expectedErr   error
+	}{
+		{name: "Unset"},
+		{
+			name:          "Node selectors",
+			nodeSelectors: []string{"node.kubernetes.io/pool=gpu", "kubernetes.io/arch=amd64"},
+			expected: &Scheduling{NodeSelector: map[string]string{
+				"node.kubernetes.io/pool": "gpu",
+				"kubernetes.io/arch":      "amd64",
+			}},
+		},
+		{
+			name:        "Tolerations",
+			tolerations: []string{"nvidia.com/gpu:NoSchedule", "pool=ci:NoExecute", "dedicated=runners", "spot"},
+			expected: &Scheduling{Tolerations: []corev1.Toleration{
+				{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+				{Key: "pool", Operator: corev1.TolerationOpEqual, Value: "ci", Effect: corev1.TaintEffectNoExecute},
+				{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "runners"},
+				{Key: "spot", Operator: corev1.TolerationOpExists},
+			}},
+		},
+		{name: "Node selector without value", nodeSelectors: []string{"pool"}, expectedErr: ErrInvalidScheduling},
+		{name: "Node selector with invalid key", nodeSelectors: []string{"bad key=gpu"}, expectedErr: ErrInvalidScheduling},
+		{name: "Node selector with invalid value", nodeSelectors: []string{"pool=gpu pool"}, expectedErr: ErrInvalidScheduling},
+		{name: "Duplicate node selector", nodeSelectors: []string{"pool=gpu", "pool=cpu"}, expectedErr: ErrInvalidScheduling},
+		{name: "Toleration without key", tolerations: []string{":NoSchedule"}, expectedErr: ErrInvalidScheduling},
+		{name: "Toleration with unknown effect", tolerations: []string{"gpu:Never"}, expectedErr: ErrInvalidScheduling},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScheduling(tt.nodeSelectors, tt.tolerations)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("ParseScheduling() error = %v, want %v", err, tt.expectedErr)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseScheduling() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesScheduling tests that scheduling constraints reach the spec in pod form
+func TestCreateResourcesScheduling(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+
+	scheduling, err := ParseScheduling([]string{"pool=gpu"}, []string{"nvidia.com/gpu:NoSchedule", "pool=gpu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set", WithScheduling(scheduling))
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+
+	instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+		context.TODO(), "test-runner", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("instance was not created: %v", err)
+	}
+	got, found, err := unstructured.NestedMap(instance.Object, "spec", "scheduling")
+	if err != nil || !found {
+		t.Fatalf("spec.scheduling missing or malformed: found=%v err=%v", found, err)
+	}
+
+	expected := map[string]interface{}{
+		"nodeSelector": map[string]interface{}{"pool": "gpu"},
+		"tolerations": []interface{}{
+			map[string]interface{}{"key": "nvidia.com/gpu", "operator": "Exists", "effect": "NoSchedule"},
+			map[string]interface{}{"key": "pool", "operator": "Equal", "value": "gpu"},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("spec.scheduling = %v, want %v", got, expected)
+	}
+}
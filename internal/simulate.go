@@ -0,0 +1,113 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// SimulatedKind is the instance kind of the RGD served in simulate mode
+const SimulatedKind = "SimulatedRunner"
+
+// NewSimulatedRunner returns a KRORunner backed by in-memory fake clients instead of a cluster.
+// It serves one RGD for the scale set, and every watch of an instance replays ACTIVE, then
+// resources ready with a Succeeded runner pod, so the full create, wait and delete flow runs
+// without cluster access. It is for demos and tests only, never for production: no runner is
+// started and nothing is created in a cluster.
+func NewSimulatedRunner(namespace, scaleSetName string, opts ...Option) *KRORunner {
+	rgdGVR := schema.GroupVersionResource{Group: kroGroup, Version: DefaultKROAPIVersion, Resource: "resourcegraphdefinitions"}
+	instanceGVR := schema.GroupVersionResource{Group: kroGroup, Version: DefaultKROAPIVersion, Resource: toResourceName(SimulatedKind)}
+
+	rgd := &unstructured.Unstructured{}
+	rgd.SetGroupVersionKind(schema.GroupVersionKind{Group: kroGroup, Version: DefaultKROAPIVersion, Kind: "ResourceGraphDefinition"})
+	rgd.SetName("simulated-runner")
+	rgd.SetLabels(map[string]string{rgdLabelKey: scaleSetName})
+	_ = unstructured.SetNestedField(rgd.Object, SimulatedKind, "spec", "schema", "kind")
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			rgdGVR:      "ResourceGraphDefinitionList",
+			instanceGVR: SimulatedKind + "List",
+		}, rgd)
+	dynamicClient.PrependWatchReactor(instanceGVR.Resource, simulateInstanceLifecycle(dynamicClient, instanceGVR))
+
+	kubeClient := kubefake.NewClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+	kubeClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: kroGroup + "/" + DefaultKROAPIVersion,
+		APIResources: []metav1.APIResource{{Name: rgdGVR.Resource}, {Name: instanceGVR.Resource}},
+	}}
+	// Any orchestrator pod name resolves, as it would inside the runner pod
+	kubeClient.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		name := action.(k8stesting.GetAction).GetName()
+		return true, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: action.GetNamespace(),
+			UID:       types.UID("simulated-" + name),
+		}}, nil
+	})
+
+	opts = append([]Option{WithKROAPIVersion(DefaultKROAPIVersion), WithClusterInfo("simulated", "")}, opts...)
+	return NewKRORunner(namespace, dynamicClient, kubeClient, scaleSetName, opts...)
+}
+
+// simulateInstanceLifecycle answers a watch of an instance with its simulated status transitions
+func simulateInstanceLifecycle(dynamicClient *dynamicfake.FakeDynamicClient, gvr schema.GroupVersionResource) k8stesting.WatchReactionFunc {
+	return func(action k8stesting.Action) (bool, watch.Interface, error) {
+		restrictions := action.(k8stesting.WatchAction).GetWatchRestrictions()
+		name, ok := restrictions.Fields.RequiresExactMatch("metadata.name")
+		if !ok {
+			name, _ = restrictions.Labels.RequiresExactMatch(runnerNameLabelKey)
+		}
+
+		obj, err := dynamicClient.Tracker().Get(gvr, action.GetNamespace(), name)
+		if err != nil {
+			return true, nil, err
+		}
+		instance, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return true, nil, fmt.Errorf("unexpected instance type %T", obj)
+		}
+
+		Infof("Simulating ResourceGraph %s: ACTIVE, then ready with a Succeeded runner pod", name)
+		watcher := watch.NewFakeWithChanSize(2, false)
+		watcher.Modify(simulatedStatus(instance, "False", "Running"))
+		watcher.Modify(simulatedStatus(instance, "True", "Succeeded"))
+		return true, watcher, nil
+	}
+}
+
+// simulatedStatus returns a copy of instance with an ACTIVE status and the given readiness and pod phase
+func simulatedStatus(instance *unstructured.Unstructured, resourcesReady, podPhase string) *unstructured.Unstructured {
+	instance = instance.DeepCopy()
+	_ = unstructured.SetNestedField(instance.Object, "ACTIVE", "status", "state")
+	_ = unstructured.SetNestedSlice(instance.Object, []interface{}{
+		map[string]interface{}{"type": "ResourcesReady", "status": resourcesReady},
+	}, "status", "conditions")
+	_ = unstructured.SetNestedField(instance.Object, podPhase, "status", "resources", "runnerPod", "status", "phase")
+	return instance
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"testing"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TestSimulatedRunner tests the full create, wait and delete flow without a cluster
+func TestSimulatedRunner(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "Field selector watch"},
+		{name: "Label selector watch", opts: []Option{WithWatchByLabel(true)}},
+		{name: "Wait active on create", opts: []Option{WithWaitActiveOnCreate(true)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { appContext = nil })
+			r := NewSimulatedRunner("default", "demo", tt.opts...)
+
+			if err := r.CreateResources(context.TODO(), "demo-runner", "simulated-config"); err != nil {
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+			if err := r.WaitForResourceGraph(context.TODO()); err != nil {
+				t.Fatalf("WaitForResourceGraph() error = %v", err)
+			}
+			if err := r.DeleteResources(context.TODO()); err != nil {
+				t.Fatalf("DeleteResources() error = %v", err)
+			}
+
+			gvr := schema.GroupVersionResource{Group: kroGroup, Version: DefaultKROAPIVersion, Resource: "simulatedrunners"}
+			if _, err := r.dynamicClient.Resource(gvr).Namespace("default").Get(
+				context.TODO(), "demo-runner", metav1.GetOptions{}); !k8serrors.IsNotFound(err) {
+				t.Errorf("simulated instance still exists after delete: %v", err)
+			}
+		})
+	}
+}
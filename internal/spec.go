@@ -0,0 +1,193 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// mergeSpec deep-merges overrides into spec, with override values taking precedence
+func mergeSpec(spec, overrides map[string]interface{}) {
+	for key, val := range overrides {
+		overrideMap, isMap := val.(map[string]interface{})
+		existing, existingIsMap := spec[key].(map[string]interface{})
+		if isMap && existingIsMap {
+			mergeSpec(existing, overrideMap)
+			continue
+		}
+
+		spec[key] = val
+	}
+}
+
+// ErrInvalidRunnerLabels is returned for a runner label list that GitHub would reject
+var ErrInvalidRunnerLabels = errors.New("invalid runner labels")
+
+// runnerLabelPattern matches a single GitHub runner label, e.g. self-hosted or ubuntu-22.04
+var runnerLabelPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// ParseRunnerLabels splits a comma-separated runner label list such as "linux,x64,self-hosted,gpu".
+// Whitespace around labels is ignored; empty, malformed and duplicate labels are rejected.
+func ParseRunnerLabels(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var labels []string
+	seen := map[string]bool{}
+	for _, label := range strings.Split(raw, ",") {
+		label = strings.TrimSpace(label)
+		switch {
+		case label == "":
+			return nil, errors.Wrapf(ErrInvalidRunnerLabels, "empty label in %q", raw)
+		case !runnerLabelPattern.MatchString(label):
+			return nil, errors.Wrapf(ErrInvalidRunnerLabels, "label %q may only contain letters, digits, '.', '_' and '-'", label)
+		case seen[strings.ToLower(label)]:
+			return nil, errors.Wrapf(ErrInvalidRunnerLabels, "duplicate label %q", label)
+		}
+		seen[strings.ToLower(label)] = true
+		labels = append(labels, label)
+	}
+
+	return labels, nil
+}
+
+// runnerLabelsSpec converts runner labels into the spec's list form
+func runnerLabelsSpec(labels []string) []interface{} {
+	values := make([]interface{}, 0, len(labels))
+	for _, label := range labels {
+		values = append(values, label)
+	}
+	return values
+}
+
+// imagePullSecretRefs builds the pod-style imagePullSecrets list, e.g. [{name: regcred}]
+func imagePullSecretRefs(names []string) []interface{} {
+	refs := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		refs = append(refs, map[string]interface{}{"name": name})
+	}
+	return refs
+}
+
+// warnMissingImagePullSecrets logs a warning for each image pull secret missing from the namespace.
+// Missing secrets do not fail the create, since the RGD may create them or use another namespace.
+func (r *KRORunner) warnMissingImagePullSecrets(ctx context.Context) {
+	for _, name := range r.imagePullSecrets {
+		_, err := r.kubeClient.CoreV1().Secrets(r.namespace).Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+		case k8serrors.IsNotFound(err):
+			log.Printf("Warning: image pull secret %q not found in namespace %s", name, r.namespace)
+		default:
+			log.Printf("Warning: cannot check image pull secret %q: %v", name, err)
+		}
+	}
+}
+
+// validateSpecOverrides checks override keys against the RGD's spec schema.
+// Validation is skipped with a warning when the RGD does not expose its schema.
+func validateSpecOverrides(rgdInfo *RGDInfo, overrides map[string]interface{}) error {
+	if rgdInfo.SpecSchema == nil {
+		log.Printf("Warning: RGD %s has no introspectable spec schema, skipping spec override validation", rgdInfo.Name)
+		return nil
+	}
+
+	unknown := unknownSpecKeys(rgdInfo.SpecSchema, overrides, "")
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	return errors.Wrapf(ErrUnknownSpecKeys, "RGD %s does not define: %s", rgdInfo.Name, strings.Join(unknown, ", "))
+}
+
+// unknownSpecKeys returns the dotted paths of override keys missing from the schema.
+// Nested overrides are only checked when the schema also describes the field as an object.
+func unknownSpecKeys(schema, overrides map[string]interface{}, prefix string) []string {
+	var unknown []string
+	for key, val := range overrides {
+		path := prefix + key
+
+		fieldSchema, found := schema[key]
+		if !found {
+			unknown = append(unknown, path)
+			continue
+		}
+
+		nestedSchema, schemaIsMap := fieldSchema.(map[string]interface{})
+		nestedOverrides, overrideIsMap := val.(map[string]interface{})
+		if schemaIsMap && overrideIsMap {
+			unknown = append(unknown, unknownSpecKeys(nestedSchema, nestedOverrides, path+".")...)
+		}
+	}
+
+	return unknown
+}
+
+// specMismatches returns the sorted dotted paths of fields in desired whose value differs in existing.
+// Fields only present in existing, such as defaults filled in by the server, are ignored.
+func specMismatches(existing, desired map[string]interface{}) []string {
+	mismatched := collectSpecMismatches(normalizeSpec(existing), normalizeSpec(desired), "")
+	sort.Strings(mismatched)
+	return mismatched
+}
+
+func collectSpecMismatches(existing, desired map[string]interface{}, prefix string) []string {
+	var mismatched []string
+	for key, want := range desired {
+		path := prefix + key
+		got, found := existing[key]
+
+		wantMap, wantIsMap := want.(map[string]interface{})
+		gotMap, gotIsMap := got.(map[string]interface{})
+		switch {
+		case !found:
+			mismatched = append(mismatched, path)
+		case wantIsMap && gotIsMap:
+			mismatched = append(mismatched, collectSpecMismatches(gotMap, wantMap, path+".")...)
+		case !reflect.DeepEqual(got, want):
+			mismatched = append(mismatched, path)
+		}
+	}
+
+	return mismatched
+}
+
+// normalizeSpec round-trips a spec through JSON so numbers compare equal regardless of their Go type
+func normalizeSpec(spec map[string]interface{}) map[string]interface{} {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return spec
+	}
+
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return spec
+	}
+	return normalized
+}
@@ -0,0 +1,316 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// testSpecSchema mirrors a KRO simple schema with a nested object field
+var testSpecSchema = map[string]interface{}{
+	"runnerName": "string",
+	"image":      "string | default=runner:latest",
+	"resources": map[string]interface{}{
+		"cpu":    "string",
+		"memory": "string",
+	},
+}
+
+// TestMergeSpec tests deep merging of overrides into the spec
+func TestMergeSpec(t *testing.T) {
+	spec := map[string]interface{}{
+		"runnerName": "test-runner",
+		"resources":  map[string]interface{}{"cpu": "1"},
+	}
+
+	mergeSpec(spec, map[string]interface{}{
+		"image":     "custom:v1",
+		"resources": map[string]interface{}{"memory": "2Gi"},
+	})
+
+	expected := map[string]interface{}{
+		"runnerName": "test-runner",
+		"image":      "custom:v1",
+		"resources":  map[string]interface{}{"cpu": "1", "memory": "2Gi"},
+	}
+	if !reflect.DeepEqual(spec, expected) {
+		t.Errorf("mergeSpec() = %v, want %v", spec, expected)
+	}
+}
+
+// TestValidateSpecOverrides tests override keys are checked against the RGD schema
+func TestValidateSpecOverrides(t *testing.T) {
+	tests := []struct {
+		name        string
+		schema      map[string]interface{}
+		overrides   map[string]interface{}
+		expectedErr error
+		unknown     []string
+	}{
+		{
+			name:      "Known top-level and nested keys",
+			schema:    testSpecSchema,
+			overrides: map[string]interface{}{"image": "x", "resources": map[string]interface{}{"cpu": "2"}},
+		},
+		{
+			name:        "Unknown keys are listed",
+			schema:      testSpecSchema,
+			overrides:   map[string]interface{}{"imagee": "x", "resources": map[string]interface{}{"gpu": "1"}},
+			expectedErr: ErrUnknownSpecKeys,
+			unknown:     []string{"imagee", "resources.gpu"},
+		},
+		{
+			name:      "Missing schema skips validation",
+			schema:    nil,
+			overrides: map[string]interface{}{"anything": "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSpecOverrides(&RGDInfo{Name: "pod-runner", SpecSchema: tt.schema}, tt.overrides)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("validateSpecOverrides() error = %v, want %v", err, tt.expectedErr)
+			}
+			for _, key := range tt.unknown {
+				if !strings.Contains(err.Error(), key) {
+					t.Errorf("error %q does not list unknown field %q", err, key)
+				}
+			}
+		})
+	}
+}
+
+// TestCreateResourcesSpecOverrides tests overrides are validated against the discovered RGD and merged
+func TestCreateResourcesSpecOverrides(t *testing.T) {
+	tests := []struct {
+		name        string
+		overrides   map[string]interface{}
+		expectedErr error
+	}{
+		{name: "Valid override is applied", overrides: map[string]interface{}{"image": "custom:v1"}},
+		{name: "Typo is rejected before create", overrides: map[string]interface{}{"imag": "custom:v1"}, expectedErr: ErrUnknownSpecKeys},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rgd := newTestRGD("pod-runner", "test-scale-set", "PodRunner")
+			_ = unstructured.SetNestedField(rgd.Object, testSpecSchema, "spec", "schema", "spec")
+			dynamicClient := newFakeDynamicClient(rgd)
+			kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+
+			r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set", WithSpecOverrides(tt.overrides))
+			err := r.CreateResources(context.TODO(), "test-runner", "test-config")
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("CreateResources() error = %v, want %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+
+			instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+				context.TODO(), "test-runner", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("instance was not created: %v", err)
+			}
+			image, _, _ := unstructured.NestedString(instance.Object, "spec", "image")
+			if image != "custom:v1" {
+				t.Errorf("spec.image = %q, want %q", image, "custom:v1")
+			}
+		})
+	}
+}
+
+// TestCreateResourcesImagePullSecrets tests the imagePullSecrets spec shape and the missing secret warning
+func TestCreateResourcesImagePullSecrets(t *testing.T) {
+	logs := captureLogs(t)
+
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	kubeClient := kubefake.NewClientset(
+		newTestPod("default", "test-runner"),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "regcred", Namespace: "default"}},
+	)
+
+	r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set",
+		WithImagePullSecrets([]string{"regcred", "missing-cred"}))
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+
+	instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+		context.TODO(), "test-runner", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("instance was not created: %v", err)
+	}
+	secrets, _, _ := unstructured.NestedSlice(instance.Object, "spec", "imagePullSecrets")
+	expected := []interface{}{
+		map[string]interface{}{"name": "regcred"},
+		map[string]interface{}{"name": "missing-cred"},
+	}
+	if !reflect.DeepEqual(secrets, expected) {
+		t.Errorf("spec.imagePullSecrets = %v, want %v", secrets, expected)
+	}
+
+	if !strings.Contains(logs.String(), `image pull secret "missing-cred" not found`) {
+		t.Errorf("expected a warning for the missing secret, got logs: %s", logs.String())
+	}
+	if strings.Contains(logs.String(), `"regcred"`) {
+		t.Errorf("unexpected warning for an existing secret, got logs: %s", logs.String())
+	}
+}
+
+// TestParseRunnerLabels tests parsing and validation of the runner label list
+func TestParseRunnerLabels(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		expected    []string
+		expectedErr error
+	}{
+		{name: "Unset", raw: ""},
+		{name: "Labels", raw: "linux,x64,self-hosted,gpu", expected: []string{"linux", "x64", "self-hosted", "gpu"}},
+		{name: "Whitespace is trimmed", raw: " linux , ubuntu-22.04 ", expected: []string{"linux", "ubuntu-22.04"}},
+		{name: "Empty label", raw: "linux,,x64", expectedErr: ErrInvalidRunnerLabels},
+		{name: "Trailing comma", raw: "linux,", expectedErr: ErrInvalidRunnerLabels},
+		{name: "Invalid characters", raw: "linux,gpu tesla", expectedErr: ErrInvalidRunnerLabels},
+		{name: "Duplicate label", raw: "linux,Linux", expectedErr: ErrInvalidRunnerLabels},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, err := ParseRunnerLabels(tt.raw)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("ParseRunnerLabels() error = %v, want %v", err, tt.expectedErr)
+			}
+			if !reflect.DeepEqual(labels, tt.expected) {
+				t.Errorf("ParseRunnerLabels() = %v, want %v", labels, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCreateResourcesRunnerLabels tests that runner labels are passed in the spec as a list
+func TestCreateResourcesRunnerLabels(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+
+	r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set",
+		WithRunnerLabels([]string{"linux", "x64", "gpu"}))
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+
+	instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+		context.TODO(), "test-runner", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("instance was not created: %v", err)
+	}
+	labels, found, err := unstructured.NestedStringSlice(instance.Object, "spec", "runnerLabels")
+	if err != nil || !found {
+		t.Fatalf("spec.runnerLabels missing or malformed: found=%v err=%v", found, err)
+	}
+	if expected := []string{"linux", "x64", "gpu"}; !reflect.DeepEqual(labels, expected) {
+		t.Errorf("spec.runnerLabels = %v, want %v", labels, expected)
+	}
+}
+
+// TestCreateResourcesRunnerGroup tests that the runner group is passed in the spec only when set
+func TestCreateResourcesRunnerGroup(t *testing.T) {
+	tests := []struct {
+		name          string
+		group         string
+		expectedFound bool
+	}{
+		{name: "Group set", group: "gpu-runners", expectedFound: true},
+		{name: "Group unset", group: "", expectedFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+			kubeClient := kubefake.NewClientset(newTestPod("default", "test-runner"))
+
+			r := NewKRORunner("default", dynamicClient, kubeClient, "test-scale-set", WithRunnerGroup(tt.group))
+			if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+				t.Fatalf("CreateResources() error = %v", err)
+			}
+
+			instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+				context.TODO(), "test-runner", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("instance was not created: %v", err)
+			}
+			group, found, err := unstructured.NestedString(instance.Object, "spec", "runnerGroup")
+			if err != nil {
+				t.Fatalf("spec.runnerGroup is malformed: %v", err)
+			}
+			if found != tt.expectedFound || group != tt.group {
+				t.Errorf("spec.runnerGroup = %q (found=%v), want %q (found=%v)", group, found, tt.group, tt.expectedFound)
+			}
+		})
+	}
+}
+
+// TestSpecMismatches tests detection of desired spec fields that differ in an existing spec
+func TestSpecMismatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing map[string]interface{}
+		desired  map[string]interface{}
+		expected []string
+	}{
+		{
+			name:     "Matching with server defaults",
+			existing: map[string]interface{}{"runnerName": "r1", "replicas": int64(1), "image": "default"},
+			desired:  map[string]interface{}{"runnerName": "r1", "replicas": 1},
+		},
+		{
+			name:     "Different runner name",
+			existing: map[string]interface{}{"runnerName": "other"},
+			desired:  map[string]interface{}{"runnerName": "r1"},
+			expected: []string{"runnerName"},
+		},
+		{
+			name:     "Missing and nested differences",
+			existing: map[string]interface{}{"runnerName": "r1", "resources": map[string]interface{}{"cpu": "1"}},
+			desired: map[string]interface{}{
+				"runnerName": "r1",
+				"resources":  map[string]interface{}{"cpu": "2", "memory": "1Gi"},
+				"image":      "custom",
+			},
+			expected: []string{"image", "resources.cpu", "resources.memory"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := specMismatches(tt.existing, tt.desired)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("specMismatches() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
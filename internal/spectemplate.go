@@ -0,0 +1,96 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/yaml"
+)
+
+// ErrInvalidSpecTemplate is returned when a spec template cannot be parsed, rendered or decoded into an object
+var ErrInvalidSpecTemplate = errors.New("invalid spec template")
+
+// SpecTemplateData holds the variables available to a spec template, e.g. {{ .RunnerName }}
+// or {{ .Env.HOME }}. A missing field or environment variable fails the render; use
+// {{ index .Env "NAME" }} for an optional variable.
+type SpecTemplateData struct {
+	RunnerName   string
+	ScaleSetName string
+	JITSecret    string
+	Namespace    string
+	Env          map[string]string
+}
+
+// SpecTemplate renders the instance spec from a Go template producing a YAML or JSON object
+type SpecTemplate struct {
+	tmpl *template.Template
+}
+
+// LoadSpecTemplate reads and parses the spec template file at path
+func LoadSpecTemplate(path string) (*SpecTemplate, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read spec template")
+	}
+	return ParseSpecTemplate(path, string(text))
+}
+
+// ParseSpecTemplate parses a spec template named name, for error messages
+func ParseSpecTemplate(name, text string) (*SpecTemplate, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidSpecTemplate, "%v", err)
+	}
+	return &SpecTemplate{tmpl: tmpl}, nil
+}
+
+// Render executes the template with data and decodes the output as the spec object.
+// Whole numbers decode as int64, as in specs read from the API server.
+func (t *SpecTemplate) Render(data SpecTemplateData) (map[string]interface{}, error) {
+	var out bytes.Buffer
+	if err := t.tmpl.Execute(&out, data); err != nil {
+		return nil, errors.Wrapf(ErrInvalidSpecTemplate, "%v", err)
+	}
+
+	raw, err := yaml.YAMLToJSON(out.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidSpecTemplate, "%s did not render valid YAML or JSON: %v", t.tmpl.Name(), err)
+	}
+
+	var spec map[string]interface{}
+	if err := utiljson.Unmarshal(raw, &spec); err != nil || spec == nil {
+		return nil, errors.Wrapf(ErrInvalidSpecTemplate, "%s did not render an object", t.tmpl.Name())
+	}
+	return spec, nil
+}
+
+// environMap returns the process environment as a map for spec templates
+func environMap() map[string]string {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		if key, val, ok := strings.Cut(kv, "="); ok {
+			env[key] = val
+		}
+	}
+	return env
+}
@@ -0,0 +1,155 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// TestSpecTemplateRender tests rendering spec templates with substitutions and rejecting bad output
+func TestSpecTemplateRender(t *testing.T) {
+	data := SpecTemplateData{
+		RunnerName:   "runner-abc",
+		ScaleSetName: "linux-builders",
+		JITSecret:    "runner-abc-jit",
+		Namespace:    "arc-runners",
+		Env:          map[string]string{"RUNNER_IMAGE": "ghcr.io/actions/runner:2.320.0"},
+	}
+
+	tests := []struct {
+		name        string
+		text        string
+		expected    map[string]interface{}
+		expectedErr error
+	}{
+		{
+			name: "YAML with substitutions",
+			text: `runnerName: {{ .RunnerName }}
+pool: {{ .ScaleSetName }}-{{ .Namespace }}
+runner:
+  image: {{ .Env.RUNNER_IMAGE }}
+  secret: {{ .JITSecret }}
+  replicas: 2
+`,
+			expected: map[string]interface{}{
+				"runnerName": "runner-abc",
+				"pool":       "linux-builders-arc-runners",
+				"runner": map[string]interface{}{
+					"image":    "ghcr.io/actions/runner:2.320.0",
+					"secret":   "runner-abc-jit",
+					"replicas": int64(2),
+				},
+			},
+		},
+		{
+			name:     "JSON",
+			text:     `{"runnerName": "{{ .RunnerName }}", "debug": true}`,
+			expected: map[string]interface{}{"runnerName": "runner-abc", "debug": true},
+		},
+		{
+			name:     "Optional environment variable",
+			text:     `region: "{{ index .Env "AWS_REGION" }}"`,
+			expected: map[string]interface{}{"region": ""},
+		},
+		{name: "Missing environment variable", text: `region: {{ .Env.AWS_REGION }}`, expectedErr: ErrInvalidSpecTemplate},
+		{name: "Unknown field", text: `runnerName: {{ .Runner }}`, expectedErr: ErrInvalidSpecTemplate},
+		{name: "Invalid YAML", text: "runnerName: [{{ .RunnerName }}", expectedErr: ErrInvalidSpecTemplate},
+		{name: "Not an object", text: "- {{ .RunnerName }}", expectedErr: ErrInvalidSpecTemplate},
+		{name: "Empty output", text: "{{/* nothing */}}", expectedErr: ErrInvalidSpecTemplate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseSpecTemplate("spec.yaml", tt.text)
+			if err != nil {
+				t.Fatalf("ParseSpecTemplate() error = %v", err)
+			}
+
+			spec, err := tmpl.Render(data)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Render() error = %v, want %v", err, tt.expectedErr)
+			}
+			if !reflect.DeepEqual(spec, tt.expected) {
+				t.Errorf("Render() = %#v, want %#v", spec, tt.expected)
+			}
+		})
+	}
+}
+
+// TestLoadSpecTemplate tests loading spec templates from files
+func TestLoadSpecTemplate(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(valid, []byte("runnerName: {{ .RunnerName }}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(invalid, []byte("runnerName: {{ .RunnerName\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadSpecTemplate(valid); err != nil {
+		t.Errorf("LoadSpecTemplate(valid) error = %v", err)
+	}
+	if _, err := LoadSpecTemplate(invalid); !errors.Is(err, ErrInvalidSpecTemplate) {
+		t.Errorf("LoadSpecTemplate(invalid) error = %v, want %v", err, ErrInvalidSpecTemplate)
+	}
+	if _, err := LoadSpecTemplate(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("LoadSpecTemplate(missing) succeeded, want an error")
+	}
+}
+
+// TestCreateResourcesSpecTemplate tests that the rendered template replaces the base spec
+func TestCreateResourcesSpecTemplate(t *testing.T) {
+	t.Setenv("RUNNER_IMAGE", "ghcr.io/actions/runner:latest")
+
+	tmpl, err := ParseSpecTemplate("spec.yaml", `runnerName: {{ .RunnerName }}
+image: {{ .Env.RUNNER_IMAGE }}
+`)
+	if err != nil {
+		t.Fatalf("ParseSpecTemplate() error = %v", err)
+	}
+
+	dynamicClient := newFakeDynamicClient(newTestRGD("pod-runner", "test-scale-set", "PodRunner"))
+	r := NewKRORunner("default", dynamicClient, kubefake.NewClientset(newTestPod("default", "test-runner")), "test-scale-set",
+		WithSpecTemplate(tmpl), WithRunnerLabels([]string{"linux"}))
+	if err := r.CreateResources(context.TODO(), "test-runner", "test-config"); err != nil {
+		t.Fatalf("CreateResources() error = %v", err)
+	}
+
+	instance, err := dynamicClient.Resource(testRGGVR).Namespace("default").Get(
+		context.TODO(), "test-runner", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("instance was not created: %v", err)
+	}
+	expected := map[string]interface{}{
+		"runnerName":   "test-runner",
+		"image":        "ghcr.io/actions/runner:latest",
+		"runnerLabels": []interface{}{"linux"},
+	}
+	if spec := instance.Object["spec"]; !reflect.DeepEqual(spec, expected) {
+		t.Errorf("spec = %#v, want %#v", spec, expected)
+	}
+}
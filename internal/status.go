@@ -0,0 +1,96 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// LiveStatus is the runner status written to the --status-json file on each observed instance
+type LiveStatus struct {
+	RunnerName string `json:"runnerName"`
+	State      string `json:"state"`
+	// Conditions summarizes the instance conditions as Type=Status, with the reason when set
+	Conditions     []string  `json:"conditions,omitempty"`
+	ElapsedSeconds float64   `json:"elapsedSeconds"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// newLiveStatus snapshots an observed instance, with the elapsed time measured from start
+func newLiveStatus(runnerName string, rg *unstructured.Unstructured, start time.Time) LiveStatus {
+	state, _, _ := unstructured.NestedString(rg.Object, "status", "state")
+
+	var conditions []string
+	for _, condition := range extractConditions(rg) {
+		summary := fmt.Sprintf("%s=%s", condition.Type, condition.Status)
+		if condition.Reason != "" {
+			summary += fmt.Sprintf(" (%s)", condition.Reason)
+		}
+		conditions = append(conditions, summary)
+	}
+
+	now := time.Now()
+	return LiveStatus{
+		RunnerName:     runnerName,
+		State:          state,
+		Conditions:     conditions,
+		ElapsedSeconds: now.Sub(start).Seconds(),
+		UpdatedAt:      now.UTC(),
+	}
+}
+
+// WriteFile writes the status to path as JSON, atomically so readers never see a partial file
+func (s LiveStatus) WriteFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode runner status")
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return errors.Wrapf(err, "failed to write runner status to %s", path)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into place
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	// Removing after a successful rename is a no-op
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2024
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// readLiveStatus polls path until it holds a status for which done returns true
+func readLiveStatus(t *testing.T, path string, done func(LiveStatus) bool) LiveStatus {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var status LiveStatus
+		if data, err := os.ReadFile(path); err == nil {
+			if err := json.Unmarshal(data, &status); err != nil {
+				t.Fatalf("status file is not valid JSON: %v\n%s", err, data)
+			}
+			if done(status) {
+				return status
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status file %s did not reach the expected status, last %+v", path, status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestWaitForResourceGraphStatusFile tests that the status file follows state transitions
+func TestWaitForResourceGraphStatusFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "status.json")
+	r, watcher, _ := newWatchedRunner(t, WithStatusFile(path))
+	instance := newTestInstance("default", "test-runner", "test-scale-set")
+
+	done := make(chan error, 1)
+	go func() { done <- r.WaitForResourceGraph(context.TODO()) }()
+
+	transitions := []struct {
+		state      string
+		ready      bool
+		podPhase   string
+		conditions []string
+	}{
+		{state: "IN_PROGRESS", conditions: []string{"ResourcesReady=False"}},
+		{state: "ACTIVE", podPhase: "Running", conditions: []string{"ResourcesReady=False"}},
+		{state: "ACTIVE", ready: true, podPhase: "Succeeded", conditions: []string{"ResourcesReady=True"}},
+	}
+	for _, transition := range transitions {
+		watcher.Modify(withStatus(instance, transition.state, transition.ready, transition.podPhase))
+
+		status := readLiveStatus(t, path, func(s LiveStatus) bool {
+			return s.State == transition.state && reflect.DeepEqual(s.Conditions, transition.conditions)
+		})
+		if status.RunnerName != "test-runner" {
+			t.Errorf("runnerName = %q, want test-runner", status.RunnerName)
+		}
+		if status.ElapsedSeconds < 0 || status.UpdatedAt.IsZero() {
+			t.Errorf("elapsedSeconds = %v, updatedAt = %v, want both set", status.ElapsedSeconds, status.UpdatedAt)
+		}
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("WaitForResourceGraph() error = %v", err)
+	}
+
+	// Writes go through a renamed temporary file, which must not be left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "status.json" {
+		t.Errorf("status directory holds %v, want only status.json", entries)
+	}
+}